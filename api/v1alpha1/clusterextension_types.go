@@ -29,6 +29,15 @@ const (
 	// the upgrade constraints set by the package author.
 	UpgradeConstraintPolicyEnforce UpgradeConstraintPolicy = "Enforce"
 
+	// The extension will only upgrade if the new version satisfies
+	// the upgrade constraints set by the package author, but can be
+	// downgraded to any earlier available version of the package that the
+	// rest of the spec allows, e.g. when spec.version is lowered to a prior
+	// release. Downgrades are not covered by the package author's upgrade
+	// testing, so users should verify the earlier version can take over
+	// the state the installed version left behind.
+	UpgradeConstraintPolicyAllowDowngrade UpgradeConstraintPolicy = "AllowDowngrade"
+
 	// Unsafe option which allows an extension to be
 	// upgraded or downgraded to any available version of the package and
 	// ignore the upgrade path designed by package authors.
@@ -38,39 +47,593 @@ const (
 	UpgradeConstraintPolicyIgnore UpgradeConstraintPolicy = "Ignore"
 )
 
+type ClusterVersionPolicy string
+
+const (
+	// Bundles are selected regardless of the cluster version.
+	ClusterVersionPolicyIgnore ClusterVersionPolicy = "Ignore"
+
+	// Only bundles whose declared cluster version range includes the current
+	// cluster version, and the version the cluster is upgrading to, are selected.
+	ClusterVersionPolicyEnforce ClusterVersionPolicy = "Enforce"
+)
+
+type UpgradeApproval string
+
+const (
+	// Upgrades are installed as soon as they are resolved.
+	UpgradeApprovalAutomatic UpgradeApproval = "Automatic"
+
+	// Upgrades are resolved and reported in status, but only installed once
+	// their version is approved in spec.upgrade.approvedVersion.
+	UpgradeApprovalManual UpgradeApproval = "Manual"
+
+	// Upgrades within the installed bundle's major and minor version are installed as
+	// soon as they are resolved. Other upgrades are only installed once their version
+	// is approved in spec.upgrade.approvedVersion.
+	UpgradeApprovalAutomaticPatch UpgradeApproval = "AutomaticPatch"
+)
+
+type UpgradeEdges string
+
+const (
+	// Upgrades follow the replaces, skips and skipRange edges of the catalog's channels.
+	UpgradeEdgesLegacy UpgradeEdges = "Legacy"
+
+	// Upgrades are to newer bundles within the installed bundle's major version.
+	UpgradeEdgesSemver UpgradeEdges = "Semver"
+
+	// Upgrades follow Legacy edges if the package's channels declare any replaces,
+	// skips or skipRange edges, and Semver edges otherwise.
+	UpgradeEdgesAuto UpgradeEdges = "Auto"
+)
+
+type UpgradePathPolicy string
+
+const (
+	// Only bundles an upgrade edge leads to from the installed bundle are resolved.
+	UpgradePathPolicyDirect UpgradePathPolicy = "Direct"
+
+	// A bundle that can only be reached from the installed bundle through
+	// intermediate bundles is upgraded to one bundle at a time.
+	UpgradePathPolicyPlanned UpgradePathPolicy = "Planned"
+)
+
+type PreflightMode string
+
+const (
+	// Failing preflight checks block installation of the resolved bundle.
+	PreflightModeEnforce PreflightMode = "Enforce"
+
+	// Failing preflight checks are reported in status but do not block
+	// installation of the resolved bundle.
+	PreflightModeWarn PreflightMode = "Warn"
+)
+
+// DependencyMode defines how missing package dependencies of the resolved bundle are handled.
+type DependencyMode string
+
+const (
+	// Missing package dependencies fail the RequiredDependencies preflight check.
+	DependencyModeManual DependencyMode = "Manual"
+
+	// Missing package dependencies are installed by ClusterExtensions the controller creates.
+	DependencyModeInstall DependencyMode = "Install"
+)
+
+type CRPolicy string
+
+const (
+	// Custom resources are kept when the extension is uninstalled, and so are
+	// the CustomResourceDefinitions that define them.
+	CRPolicyRetain CRPolicy = "Retain"
+
+	// Custom resources are deleted before the extension is uninstalled, while
+	// the extension is still running to process their finalizers.
+	CRPolicyDelete CRPolicy = "Delete"
+
+	// The extension is not uninstalled while any of its custom resources exist.
+	CRPolicyBlock CRPolicy = "Block"
+)
+
+type PropagationPolicy string
+
+const (
+	// The installed objects are deleted along with the ClusterExtension.
+	PropagationPolicyDelete PropagationPolicy = "Delete"
+
+	// The installed objects, including CustomResourceDefinitions and their custom
+	// resources, are left in place when the ClusterExtension is deleted.
+	PropagationPolicyOrphan PropagationPolicy = "Orphan"
+)
+
+// FailureClass groups reconcile failures that are retried with the same backoff.
+type FailureClass string
+
+const (
+	// The API server or a registry rejected the controller's credentials.
+	FailureClassAuth FailureClass = "Auth"
+
+	// A network error, timeout or overloaded server that is likely to clear up on its own.
+	FailureClassTransient FailureClass = "Transient"
+
+	// No bundle satisfied the ClusterExtension's constraints.
+	FailureClassResolution FailureClass = "Resolution"
+
+	// Any other failure.
+	FailureClassDefault FailureClass = "Default"
+)
+
+type AttentionSeverity string
+
+const (
+	// Nothing about the extension needs attention.
+	AttentionSeverityNone AttentionSeverity = "None"
+
+	// The extension works but something about it, such as a deprecated
+	// bundle, should be looked at.
+	AttentionSeverityWarning AttentionSeverity = "Warning"
+
+	// The extension could not be resolved or installed.
+	AttentionSeverityCritical AttentionSeverity = "Critical"
+)
+
+// InstallConfig configures how the installation of a bundle is carried out.
+type InstallConfig struct {
+	//+kubebuilder:Optional
+	//
+	// timeout is how long the installed objects may stay unhealthy after an install or
+	// upgrade before the Installed condition is set to False. Until then the Installed
+	// condition is Unknown. Health is only known when rukpak reports it on the
+	// BundleDeployment; if timeout is unset, the install is not held back by health.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PreflightConfig configures the checks run against a resolved bundle before it is installed.
+type PreflightConfig struct {
+	//+kubebuilder:validation:Enum:=Enforce;Warn
+	//+kubebuilder:default:=Enforce
+	//+kubebuilder:Optional
+	//
+	// mode defines whether failing preflight checks block installation.
+	// In Warn mode, failures are only reported in status.preflightChecks.
+	Mode PreflightMode `json:"mode,omitempty"`
+}
+
+// DependenciesConfig configures how the package dependencies of a resolved bundle are satisfied.
+type DependenciesConfig struct {
+	//+kubebuilder:validation:Enum:=Manual;Install
+	//+kubebuilder:default:=Manual
+	//+kubebuilder:Optional
+	//
+	// mode defines whether the controller installs the packages that the resolved bundle
+	// requires with olm.package.required properties and that no ClusterExtension has
+	// installed. In Manual mode they must be installed by other ClusterExtensions first.
+	// In Install mode the controller creates a ClusterExtension named after each missing
+	// package, selecting the required version range from the same catalogs and
+	// installing its own dependencies the same way. The created ClusterExtension is owned
+	// by every ClusterExtension that needs it, and is deleted with the last of them.
+	Mode DependencyMode `json:"mode,omitempty"`
+}
+
+// UpgradeConfig configures which release of the channel the ClusterExtension targets.
+type UpgradeConfig struct {
+	//+kubebuilder:validation:Minimum:=0
+	//+kubebuilder:Optional
+	//
+	// lagReleases keeps the ClusterExtension this many releases behind the head of its
+	// channel, giving new releases time to soak before they are installed. Releases are
+	// counted by distinct version among the package's bundles in the channel, or among all
+	// of its bundles if no channel is set. Upgrade constraints still apply, so the installed
+	// bundle is never downgraded to reach the target. Zero targets the channel head.
+	LagReleases int32 `json:"lagReleases,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Ignore;Enforce
+	//+kubebuilder:default:=Ignore
+	//+kubebuilder:Optional
+	//
+	// clusterVersionPolicy defines whether bundles must be compatible with the cluster
+	// version. With Enforce, only bundles whose olm.clusterVersionRange property includes
+	// both the current cluster version and the version the cluster is upgrading to are
+	// selected, so that the extension upgrades in step with the cluster. Bundles that do
+	// not declare a range are compatible with every cluster version. The cluster version
+	// is read from the OpenShift ClusterVersion resource.
+	ClusterVersionPolicy ClusterVersionPolicy `json:"clusterVersionPolicy,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Automatic;Manual;AutomaticPatch
+	//+kubebuilder:default:=Automatic
+	//+kubebuilder:Optional
+	//
+	// approval defines whether upgrades of the installed bundle need approval. With
+	// Manual, a resolved upgrade is reported in status.pendingUpgrade and the
+	// installed bundle is kept until the upgrade's version is set in approvedVersion.
+	// With AutomaticPatch, only upgrades that change the major or minor version need
+	// approval: until then the newest resolvable bundle within the installed major and
+	// minor version, e.g. 1.4.x, is installed. The initial install does not need approval.
+	Approval UpgradeApproval `json:"approval,omitempty"`
+
+	//+kubebuilder:validation:MaxLength:=64
+	//+kubebuilder:Optional
+	//
+	// approvedVersion approves the upgrade to the bundle with this version when approval
+	// is Manual or AutomaticPatch, e.g. the version reported in status.pendingUpgrade.
+	// The approved bundle is installed even if a newer one is available, which is then
+	// reported in status.pendingUpgrade.
+	ApprovedVersion string `json:"approvedVersion,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Legacy;Semver;Auto
+	//+kubebuilder:Optional
+	//
+	// edges defines how the bundles the installed bundle can upgrade to are found. With
+	// Legacy, they follow the replaces, skips and skipRange edges of the catalog's
+	// channels. With Semver, they are the newer bundles within the installed major
+	// version. With Auto, Legacy is used if any channel of the package declares such
+	// edges, and Semver otherwise. If unset, Semver is used when the
+	// ForceSemverUpgradeConstraints feature gate is enabled, and Legacy otherwise.
+	Edges UpgradeEdges `json:"edges,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Direct;Planned
+	//+kubebuilder:default:=Direct
+	//+kubebuilder:Optional
+	//
+	// path defines how the installed bundle reaches a bundle that satisfies the spec
+	// when no upgrade edge leads to it directly, e.g. when 1.2 must pass through 1.3 to
+	// reach 1.4. With Direct, resolution fails. With Planned, the fewest upgrades that
+	// lead to the bundle are installed in order, each once the previous one is
+	// installed, and the remaining upgrades are reported in
+	// status.resolution.upgradePath. Every intermediate bundle must satisfy the spec
+	// other than version, minimumVersion and resolvedBundleDigest.
+	Path UpgradePathPolicy `json:"path,omitempty"`
+}
+
+// ResolutionConfig configures what is reported about the ClusterExtension's resolution.
+type ResolutionConfig struct {
+	//+kubebuilder:validation:Minimum:=0
+	//+kubebuilder:validation:Maximum:=50
+	//+kubebuilder:Optional
+	//
+	// reportCandidates lists up to this many of the bundles that satisfy every constraint
+	// of the ClusterExtension in status.resolution.candidates, most preferred first, e.g.
+	// to offer the versions that could be installed. Zero does not list them.
+	ReportCandidates int32 `json:"reportCandidates,omitempty"`
+}
+
+// UninstallConfig configures what happens to the extension and its custom resources when
+// the ClusterExtension is deleted.
+type UninstallConfig struct {
+	//+kubebuilder:validation:Enum:=Retain;Delete;Block
+	//+kubebuilder:default:=Block
+	//+kubebuilder:Optional
+	//
+	// crPolicy defines how custom resources of the CustomResourceDefinitions installed by
+	// the bundle are handled when the ClusterExtension is deleted. Retain keeps them along
+	// with their CustomResourceDefinitions, which are left in place without an owner,
+	// Delete deletes them first, and Block keeps the ClusterExtension from being deleted
+	// until they have been removed.
+	CRPolicy CRPolicy `json:"crPolicy,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Delete;Orphan
+	//+kubebuilder:default:=Delete
+	//+kubebuilder:Optional
+	//
+	// propagationPolicy defines whether deleting the ClusterExtension uninstalls the
+	// extension. Delete uninstalls it as configured by crPolicy. Orphan removes only the
+	// ClusterExtension: its BundleDeployment is left in place without an owner, so the
+	// installed workloads, CustomResourceDefinitions and custom resources keep running
+	// until the BundleDeployment is deleted, and crPolicy is not applied. Deleting the
+	// ClusterExtension with foreground propagation still deletes the BundleDeployment.
+	PropagationPolicy PropagationPolicy `json:"propagationPolicy,omitempty"`
+}
+
+// ProvidedAPI identifies an API by its group and kind.
+type ProvidedAPI struct {
+	//+kubebuilder:validation:MaxLength:=253
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]+([\.-][a-z0-9]+)*$
+	// group is the API group of the provided API
+	Group string `json:"group"`
+
+	//+kubebuilder:validation:MaxLength:=63
+	//+kubebuilder:validation:Pattern:=^[A-Z][a-zA-Z0-9]*$
+	// kind is the kind of the provided API
+	Kind string `json:"kind"`
+}
+
+// ConfigMapBundle references a bundle of plain manifests stored in a ConfigMap.
+type ConfigMapBundle struct {
+	//+kubebuilder:validation:MaxLength:=253
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$
+	// name is the name of the ConfigMap, in the bundle ConfigMap namespace the controller is configured with.
+	Name string `json:"name"`
+}
+
+// BundleImage references a bundle image to install without resolving it from a catalog.
+type BundleImage struct {
+	//+kubebuilder:validation:MinLength:=1
+	//+kubebuilder:validation:MaxLength:=1000
+	// ref is the reference of the registry+v1 bundle image, e.g. quay.io/example/widgets-bundle:v1.2.0.
+	Ref string `json:"ref"`
+
+	//+kubebuilder:validation:MaxLength:=48
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]+(-[a-z0-9]+)*$
+	// packageName is the name of the package the bundle belongs to.
+	PackageName string `json:"packageName"`
+
+	//+kubebuilder:validation:MaxLength:=64
+	// version is the semver version of the bundle.
+	Version string `json:"version"`
+}
+
+// CatalogSnapshot pins a catalog to the image it was resolved from.
+type CatalogSnapshot struct {
+	//+kubebuilder:validation:MaxLength:=253
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$
+	// catalog is the name of the Catalog.
+	Catalog string `json:"catalog"`
+
+	//+kubebuilder:validation:MaxLength:=1000
+	//+kubebuilder:validation:Pattern:=`^[^@\s]+@sha256:[a-f0-9]{64}$`
+	// resolvedRef is the digest reference of the catalog image, as reported in the
+	// catalog's status.resolvedSource.image.resolvedRef.
+	ResolvedRef string `json:"resolvedRef"`
+}
+
+// ChannelName is the name of a channel of a package.
+//
+// +kubebuilder:validation:MaxLength:=48
+// +kubebuilder:validation:Pattern:=^[a-z0-9]+([\.-][a-z0-9]+)*$
+type ChannelName string
+
 // ClusterExtensionSpec defines the desired state of ClusterExtension
+//
+// +kubebuilder:validation:XValidation:rule="[has(self.packageName), has(self.providedAPI), has(self.configMapBundle), has(self.bundleImage)].filter(x, x).size() == 1",message="exactly one of packageName, providedAPI, configMapBundle or bundleImage must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.resolvedBundleDigest) || !has(self.configMapBundle)",message="resolvedBundleDigest cannot be used with configMapBundle"
+// +kubebuilder:validation:XValidation:rule="!has(self.resolvedBundleDigest) || !has(self.bundleImage)",message="resolvedBundleDigest cannot be used with bundleImage"
+// +kubebuilder:validation:XValidation:rule="!has(self.channel) || !has(self.channels)",message="channel and channels are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.watchNamespaces) || size(self.watchNamespaces) <= 1 || !self.watchNamespaces.exists(e, size(e) == 0)",message="watchNamespaces cannot contain an empty string when it lists more than one namespace"
+// +kubebuilder:validation:XValidation:rule="!has(self.pinnedImage) || has(self.packageName)",message="pinnedImage requires packageName"
+// +kubebuilder:validation:XValidation:rule="!has(self.pinnedImage) || (!has(self.resolvedBundleDigest) && !has(self.rollbackTo))",message="pinnedImage cannot be used with resolvedBundleDigest or rollbackTo"
 type ClusterExtensionSpec struct {
 	//+kubebuilder:validation:MaxLength:=48
 	//+kubebuilder:validation:Pattern:=^[a-z0-9]+(-[a-z0-9]+)*$
-	PackageName string `json:"packageName"`
+	//+kubebuilder:Optional
+	PackageName string `json:"packageName,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// providedAPI selects the package to install by an API it provides rather than by name.
+	// The resolver considers every package whose bundles advertise the given group and kind
+	// and picks the best bundle according to the version and channel constraints.
+	// Mutually exclusive with packageName.
+	ProvidedAPI *ProvidedAPI `json:"providedAPI,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// configMapBundle installs a bundle of plain manifests stored in a ConfigMap instead of
+	// resolving one from a catalog. The ConfigMap must be annotated with the bundle's package
+	// (olm.operatorframework.io/package) and version (olm.operatorframework.io/bundleVersion),
+	// and each of its data entries must contain Kubernetes manifests.
+	// Mutually exclusive with packageName and providedAPI.
+	ConfigMapBundle *ConfigMapBundle `json:"configMapBundle,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// bundleImage installs the referenced registry+v1 bundle image instead of resolving a
+	// bundle from a catalog, e.g. a CI build or a hotfix that is not published in any
+	// catalog. The upgrade graph is not consulted: changing the image reference installs
+	// the new image, whether or not it upgrades the installed bundle.
+	// Mutually exclusive with packageName, providedAPI and configMapBundle.
+	BundleImage *BundleImage `json:"bundleImage,omitempty"`
 
 	//+kubebuilder:validation:MaxLength:=64
 	//+kubebuilder:validation:Pattern=`^(\s*(=||!=|>|<|>=|=>|<=|=<|~|~>|\^)\s*(v?(0|[1-9]\d*|[x|X|\*])(\.(0|[1-9]\d*|x|X|\*]))?(\.(0|[1-9]\d*|x|X|\*))?(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?)\s*)((?:\s+|,\s*|\s*\|\|\s*)(=||!=|>|<|>=|=>|<=|=<|~|~>|\^)\s*(v?(0|[1-9]\d*|x|X|\*])(\.(0|[1-9]\d*|x|X|\*))?(\.(0|[1-9]\d*|x|X|\*]))?(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?)\s*)*$`
 	//+kubebuilder:Optional
 	// Version is an optional semver constraint on the package version. If not specified, the latest version available of the package will be installed.
-	// If specified, the specific version of the package will be installed so long as it is available in any of the content sources available.
-	// Examples: 1.2.3, 1.0.0-alpha, 1.0.0-rc.1
+	// If specified as an exact version, that version of the package will be installed so long as it is available in any of the content sources available.
+	// If specified as a range, only bundles within the range are considered, and upgrades stay within it.
+	// Examples: 1.2.3, 1.0.0-alpha, 1.0.0-rc.1, >=1.2.0 <2.0.0, ~1.2.0
 	//
 	// For more information on semver, please see https://semver.org/
 	Version string `json:"version,omitempty"`
 
+	//+kubebuilder:validation:MaxLength:=64
+	//+kubebuilder:validation:Pattern=`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?$`
+	//+kubebuilder:Optional
+	//
+	// minimumVersion is an optional hard lower bound on the version of the package that may be resolved.
+	// It is enforced in addition to, and independently of, the version range and the upgrade constraints,
+	// including on fresh installs. If no bundle at or above this version can be resolved, resolution
+	// fails with the BelowMinimumFloor reason.
+	// Example: 1.2.3
+	MinimumVersion string `json:"minimumVersion,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// allowPrerelease opts in to resolving pre-release versions of the package, e.g. 1.3.0-rc.1,
+	// for testing release candidates. By default pre-release versions are skipped, regardless of
+	// the version range, unless one is already installed.
+	AllowPrerelease bool `json:"allowPrerelease,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=8
+	//+kubebuilder:Optional
+	//
+	// selectorExpressions are CEL expressions that a bundle must all evaluate to true for
+	// to be considered, for constraints the other fields do not cover. Each expression can
+	// refer to the bundle's name and version as strings, and to properties, a map from each
+	// property type of the bundle to the list of values of its properties of that type.
+	// A bundle for which an expression fails to evaluate, e.g. because it has no property
+	// of the type, is not considered.
+	// Example: 'features.fips' in properties && properties['features.fips'].exists(v, v == true)
+	SelectorExpressions []string `json:"selectorExpressions,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=64
+	//+kubebuilder:Optional
+	//
+	// versionBlocklist lists versions of the package that are never resolved, e.g. releases
+	// with known regressions. Upgrades skip over them to the next acceptable version, when
+	// the upgrade edges allow it, without narrowing the version range. A blocked version that
+	// is already installed is not kept; the best remaining candidate is resolved instead.
+	// Example: ["1.4.2", "1.5.0"]
+	VersionBlocklist []string `json:"versionBlocklist,omitempty"`
+
+	//+kubebuilder:validation:MaxLength:=256
+	//+kubebuilder:validation:Pattern:=`^[a-z0-9]+([+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]+$`
+	//+kubebuilder:Optional
+	//
+	// resolvedBundleDigest pins the ClusterExtension to the bundle whose image has this digest,
+	// typically one recorded from a resolution in another environment. The pinned bundle is
+	// installed instead of the best candidate, but it must still satisfy every other constraint
+	// of the ClusterExtension; if it does not, resolution fails with the PinnedBundleMismatch reason.
+	// Example: sha256:3d5c4d3f1f0ec1d5b2e7e0a2b9d5f2c6b6e1a4c8f7d9e0b1a2c3d4e5f6a7b8c9
+	ResolvedBundleDigest string `json:"resolvedBundleDigest,omitempty"`
+
+	//+kubebuilder:validation:MaxLength:=1000
+	//+kubebuilder:validation:Pattern:=`^[^@\s]+@sha256:[a-f0-9]{64}$`
+	//+kubebuilder:Optional
+	//
+	// pinnedImage freezes the ClusterExtension at the bundle image with this digest
+	// reference, e.g. a known-good artifact during an incident. The image is installed
+	// without resolving, even if the catalogs no longer list it, as long as a catalog
+	// still provides it for packageName or it is the image currently installed. The
+	// version, channel and upgrade constraints are not consulted.
+	// Example: quay.io/operatorhubio/argocd-operator@sha256:3d5c4d3f1f0ec1d5b2e7e0a2b9d5f2c6b6e1a4c8f7d9e0b1a2c3d4e5f6a7b8c9
+	PinnedImage string `json:"pinnedImage,omitempty"`
+
 	//+kubebuilder:validation:MaxLength:=48
 	//+kubebuilder:validation:Pattern:=^[a-z0-9]+([\.-][a-z0-9]+)*$
-	// Channel constraint definition
+	// Channel constraint definition. If specified, only bundles published in the named
+	// channel of the package, e.g. stable or fast, are considered, and upgrades follow
+	// that channel's upgrade graph.
 	Channel string `json:"channel,omitempty"`
 
-	//+kubebuilder:validation:Enum:=Enforce;Ignore
+	//+kubebuilder:validation:MaxItems:=16
+	//+kubebuilder:Optional
+	//
+	// channels is a list of channels of the package to consider together. Bundles
+	// published in any of them are considered, which helps when a package moves bundles
+	// between overlapping channels such as stable-v1 and stable-v1.5.
+	// Mutually exclusive with channel.
+	Channels []ChannelName `json:"channels,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// catalogSelector restricts resolution to the catalogs whose labels match it, e.g.
+	// only catalogs labeled tier=certified. If unset, the controller's default catalog
+	// selector is used, which considers every catalog unless the controller was started
+	// with --default-catalog-selector. An empty selector considers every catalog.
+	CatalogSelector *metav1.LabelSelector `json:"catalogSelector,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=16
+	//+kubebuilder:Optional
+	//+listType=map
+	//+listMapKey=catalog
+	//
+	// catalogSnapshots freezes the input of resolution at the listed catalog images, so
+	// that the same bundle is resolved in every environment even if a catalog's tag
+	// advances. Only the listed catalogs are considered, and each must currently be
+	// unpacked from the listed image; otherwise resolution fails with the
+	// CatalogSnapshotUnavailable reason. The images of the catalogs a resolution
+	// considered are reported in status.resolution.catalogs.
+	CatalogSnapshots []CatalogSnapshot `json:"catalogSnapshots,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Enforce;AllowDowngrade;Ignore
 	//+kubebuilder:default:=Enforce
 	//+kubebuilder:Optional
 	//
-	// Defines the policy for how to handle upgrade constraints
+	// upgradeConstraintPolicy defines the policy for how to handle upgrade constraints.
+	// With Enforce, an installed extension only moves to a successor of the installed
+	// bundle in the catalog's upgrade graph. AllowDowngrade enforces the upgrade graph
+	// for newer versions, but also lets the extension move to any earlier version the
+	// rest of the spec allows, so that lowering version to a prior release installs
+	// that release; objects the earlier bundle no longer contains are removed as on an
+	// upgrade. With Ignore, the upgrade graph is not
+	// consulted and the extension can move to any version that matches the rest of the
+	// spec, including a downgrade; use it deliberately when the published upgrade edges
+	// are broken or missing.
 	UpgradeConstraintPolicy UpgradeConstraintPolicy `json:"upgradeConstraintPolicy,omitempty"`
 
+	//+kubebuilder:Optional
+	//
+	// upgrade configures which release of the channel is targeted.
+	Upgrade *UpgradeConfig `json:"upgrade,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// resolution configures what is reported about the resolution in status.resolution.
+	Resolution *ResolutionConfig `json:"resolution,omitempty"`
+
 	//+kubebuilder:Optional
 	//
 	// watchNamespaces indicates which namespaces the extension should watch.
-	// This feature is currently supported only with RegistryV1 bundles.
+	// This feature is currently supported only with RegistryV1 bundles, whose
+	// supported install modes decide which values are accepted: unset or a single
+	// empty string for AllNamespaces, the install namespace for OwnNamespace, a single
+	// other namespace for SingleNamespace and several namespaces for MultiNamespace.
 	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// install configures how the resolved bundle is installed.
+	Install *InstallConfig `json:"install,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// preflight configures the checks run against the resolved bundle before it is installed.
+	Preflight *PreflightConfig `json:"preflight,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// dependencies configures how the package dependencies of the resolved bundle are satisfied.
+	Dependencies *DependenciesConfig `json:"dependencies,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// uninstall configures how the extension is torn down when the ClusterExtension is deleted.
+	// If unset, uninstall is blocked while custom resources exist.
+	Uninstall *UninstallConfig `json:"uninstall,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// paused stops the controller from resolving, upgrading and updating the installed
+	// bundle's BundleDeployment while it is true. The status is kept as of the last
+	// reconcile before the extension was paused. Deleting a paused ClusterExtension
+	// still uninstalls it.
+	Paused bool `json:"paused,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// rollbackTo reinstalls the bundle of a revision recorded in status.installHistory
+	// or in a ClusterExtensionRevision instead of resolving one, re-applying the
+	// manifests of that install. The extension stays on the revision while rollbackTo
+	// is set; unset it to resume resolution. The revision's image is installed even if
+	// no catalog provides its bundle anymore.
+	RollbackTo *RollbackConfig `json:"rollbackTo,omitempty"`
+
+	//+kubebuilder:Optional
+	//+kubebuilder:validation:XValidation:rule="!('olm.operatorframework.io/owner-name' in self)",message="revisionLabels cannot set the olm.operatorframework.io/owner-name label"
+	//
+	// revisionLabels are set on each ClusterExtensionRevision created for the
+	// extension, overriding the labels the controller sets on every revision. They are
+	// applied when a revision is created; revisions that already exist keep their
+	// labels.
+	RevisionLabels map[string]string `json:"revisionLabels,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// dryRun resolves the bundle and runs the preflight checks without installing it.
+	// The changes that would be applied to the BundleDeployment are reported in
+	// status.dryRun, and the Installed condition is Unknown with the DryRun reason.
+	// The bundle's manifests are rendered by rukpak, so they are not part of the report.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// RollbackConfig selects a previous install to roll back to.
+type RollbackConfig struct {
+	//+kubebuilder:validation:Minimum:=1
+	//
+	// revision is the revision in status.installHistory to roll back to.
+	// Revisions that have aged out of status.installHistory are read from their
+	// ClusterExtensionRevision.
+	Revision int64 `json:"revision"`
 }
 
 const (
@@ -83,16 +646,67 @@ const (
 	TypePackageDeprecated = "PackageDeprecated"
 	TypeChannelDeprecated = "ChannelDeprecated"
 	TypeBundleDeprecated  = "BundleDeprecated"
+	// TypeCRDsEstablished reports whether every CustomResourceDefinition
+	// installed by the bundle has been established by the API server.
+	TypeCRDsEstablished = "CRDsEstablished"
+	// TypeHealthy reports whether the workloads and APIs installed by the bundle
+	// are currently healthy.
+	TypeHealthy = "Healthy"
+	// TypeUpgradeAvailable reports whether a bundle newer than the installed one
+	// satisfies the spec, whether or not it is being installed.
+	TypeUpgradeAvailable = "UpgradeAvailable"
 
+	ReasonBelowMinimumFloor         = "BelowMinimumFloor"
 	ReasonBundleLookupFailed        = "BundleLookupFailed"
+	ReasonCRDsNotEstablished        = "CRDsNotEstablished"
 	ReasonInstallationFailed        = "InstallationFailed"
 	ReasonInstallationStatusUnknown = "InstallationStatusUnknown"
 	ReasonInstallationSucceeded     = "InstallationSucceeded"
+	ReasonInstallPending            = "InstallPending"
+	ReasonDryRun                    = "DryRun"
 	ReasonInvalidSpec               = "InvalidSpec"
+	ReasonNoWorkloads               = "NoWorkloads"
+	ReasonPinnedBundleMismatch      = "PinnedBundleMismatch"
 	ReasonResolutionFailed          = "ResolutionFailed"
 	ReasonResolutionUnknown         = "ResolutionUnknown"
 	ReasonSuccess                   = "Success"
+	ReasonUnhealthy                 = "Unhealthy"
+	ReasonUpgradeAvailable          = "UpgradeAvailable"
+	ReasonUpToDate                  = "UpToDate"
 	ReasonDeprecated                = "Deprecated"
+	// ReasonDependentConstraintViolation means that every bundle that could be
+	// resolved would break another installed ClusterExtension that depends on it.
+	ReasonDependentConstraintViolation = "DependentConstraintViolation"
+	// ReasonConstraintNotSatisfied means that every bundle that could be resolved
+	// declares an olm.constraint property that no installed bundle satisfies.
+	ReasonConstraintNotSatisfied = "ConstraintNotSatisfied"
+	// ReasonResolutionAmbiguous means that catalogs of equal priority provide different
+	// bundles for the version that would be resolved, so no catalog can be chosen.
+	ReasonResolutionAmbiguous = "ResolutionAmbiguous"
+	// ReasonClusterVersionIncompatible means that no bundle that could be resolved
+	// is compatible with the current and next cluster versions.
+	ReasonClusterVersionIncompatible = "ClusterVersionIncompatible"
+	// ReasonKubernetesVersionIncompatible means that every bundle that could be
+	// resolved declares a minimum Kubernetes version newer than the cluster's.
+	ReasonKubernetesVersionIncompatible = "KubernetesVersionIncompatible"
+	// ReasonPlatformUnsupported means that every bundle that could be resolved
+	// declares platforms that do not include those of all the cluster's nodes.
+	ReasonPlatformUnsupported = "PlatformUnsupported"
+	// ReasonCatalogReferenceNotPinned means that the package could only be resolved
+	// from catalogs whose image is referenced by a tag rather than a digest, and the
+	// controller requires digest references.
+	ReasonCatalogReferenceNotPinned = "CatalogReferenceNotPinned"
+	// ReasonCatalogSnapshotUnavailable means that a catalog listed in
+	// spec.catalogSnapshots is not currently unpacked from the listed image.
+	ReasonCatalogSnapshotUnavailable = "CatalogSnapshotUnavailable"
+	// ReasonResolutionTimedOut means that resolution did not complete within the
+	// resolution timeout the controller was started with.
+	ReasonResolutionTimedOut = "ResolutionTimedOut"
+	// ReasonPreflightCheckPassed, ReasonPreflightCheckFailed and ReasonPreflightCheckNotRun
+	// are the reasons of the conditions contributed by preflight checks.
+	ReasonPreflightCheckPassed = "PreflightCheckPassed"
+	ReasonPreflightCheckFailed = "PreflightCheckFailed"
+	ReasonPreflightCheckNotRun = "PreflightCheckNotRun"
 )
 
 func init() {
@@ -104,6 +718,9 @@ func init() {
 		TypePackageDeprecated,
 		TypeChannelDeprecated,
 		TypeBundleDeprecated,
+		TypeCRDsEstablished,
+		TypeHealthy,
+		TypeUpgradeAvailable,
 	)
 	// TODO(user): add Reasons from above
 	conditionsets.ConditionReasons = append(conditionsets.ConditionReasons,
@@ -111,20 +728,95 @@ func init() {
 		ReasonResolutionFailed,
 		ReasonResolutionUnknown,
 		ReasonBundleLookupFailed,
+		ReasonBelowMinimumFloor,
+		ReasonCRDsNotEstablished,
+		ReasonPinnedBundleMismatch,
 		ReasonInstallationFailed,
 		ReasonInstallationStatusUnknown,
+		ReasonInstallPending,
+		ReasonDryRun,
 		ReasonInvalidSpec,
+		ReasonNoWorkloads,
 		ReasonSuccess,
+		ReasonUnhealthy,
+		ReasonUpgradeAvailable,
+		ReasonUpToDate,
 		ReasonDeprecated,
+		ReasonDependentConstraintViolation,
+		ReasonConstraintNotSatisfied,
+		ReasonResolutionAmbiguous,
+		ReasonClusterVersionIncompatible,
+		ReasonKubernetesVersionIncompatible,
+		ReasonPlatformUnsupported,
+		ReasonCatalogReferenceNotPinned,
+		ReasonCatalogSnapshotUnavailable,
+		ReasonResolutionTimedOut,
+		ReasonPreflightCheckPassed,
+		ReasonPreflightCheckFailed,
+		ReasonPreflightCheckNotRun,
 	)
 }
 
 // ClusterExtensionStatus defines the observed state of ClusterExtension
 type ClusterExtensionStatus struct {
 	// +optional
-	InstalledBundle *BundleMetadata `json:"installedBundle,omitempty"`
+	InstalledBundle *InstalledBundleMetadata `json:"installedBundle,omitempty"`
 	// +optional
 	ResolvedBundle *BundleMetadata `json:"resolvedBundle,omitempty"`
+	// resolvedPackageName is the name of the package the resolved bundle belongs to.
+	// This is most useful when the package is selected by spec.providedAPI.
+	// +optional
+	ResolvedPackageName string `json:"resolvedPackageName,omitempty"`
+	// resolution describes the catalogs considered during the most recent resolution.
+	// +optional
+	Resolution *ResolutionStatus `json:"resolution,omitempty"`
+	// preflightChecks lists the result of each preflight check run against the resolved bundle.
+	// +optional
+	PreflightChecks []PreflightCheckStatus `json:"preflightChecks,omitempty"`
+	// dependencies lists the packages the resolved bundle requires with
+	// olm.package.required properties and, transitively, the packages required by the
+	// bundles installed to satisfy them, with the ClusterExtension satisfying each.
+	// +optional
+	Dependencies []ResolvedDependency `json:"dependencies,omitempty"`
+	//+kubebuilder:validation:Enum:=Auth;Transient;Resolution;Default
+	// failureClass is the class of the error that failed the most recent reconcile,
+	// which determines how soon it is retried. It is empty when the reconcile succeeded.
+	// +optional
+	FailureClass FailureClass `json:"failureClass,omitempty"`
+	// retry describes the consecutive failed attempts to reconcile the extension and
+	// when it is next retried. It is cleared once a reconcile succeeds.
+	// +optional
+	Retry *RetryStatus `json:"retry,omitempty"`
+	// attention summarizes whether the extension needs human attention and why.
+	// It is derived from the conditions, which remain authoritative.
+	// +optional
+	Attention *AttentionStatus `json:"attention,omitempty"`
+	// dryRun describes what would be installed while spec.dryRun is set.
+	// +optional
+	DryRun *DryRunStatus `json:"dryRun,omitempty"`
+	// timings breaks down how long the phases of the most recent reconcile that changed
+	// the status took. Unpacking and rendering the bundle happen in rukpak and are not included.
+	// +optional
+	Timings *ReconcileTimings `json:"timings,omitempty"`
+	// uninstall describes an uninstall that is waiting for custom resources to be removed.
+	// +optional
+	Uninstall *UninstallStatus `json:"uninstall,omitempty"`
+	// pendingUpgrade describes a bundle that satisfies the spec and is preferred over
+	// the installed bundle but has not been installed, and why. When several bundles
+	// are held back, it describes the one closest to being installed: an upgrade
+	// awaiting approval, then one held back by the cluster version, then by dependent
+	// ClusterExtensions, then one that no upgrade edge leads to.
+	// +optional
+	PendingUpgrade *PendingUpgrade `json:"pendingUpgrade,omitempty"`
+	// installHistory lists the most recent successful installs of a catalog bundle,
+	// oldest first, up to 10 of them. A new revision is recorded whenever a different
+	// bundle image has been installed.
+	// +optional
+	InstallHistory []InstallRevision `json:"installHistory,omitempty"`
+	// paused is true when the controller has observed that spec.paused is set and has
+	// stopped reconciling the extension.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
 
 	// +patchMergeKey=type
 	// +patchStrategy=merge
@@ -133,6 +825,408 @@ type ClusterExtensionStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 }
 
+// InstalledBundleMetadata describes the installed bundle.
+type InstalledBundleMetadata struct {
+	BundleMetadata `json:",inline"`
+	// digest is the digest of the bundle image that was unpacked and applied, as
+	// reported by the BundleDeployment. It is empty if the bundle was not installed
+	// from an image.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+	// lastInstalled is when the bundle image with this digest was first observed as
+	// installed.
+	// +optional
+	LastInstalled *metav1.Time `json:"lastInstalled,omitempty"`
+}
+
+// RetryStatus describes consecutive failed attempts to reconcile a ClusterExtension.
+type RetryStatus struct {
+	// attempts is the number of consecutive failed attempts.
+	Attempts int32 `json:"attempts"`
+	// lastAttemptedBundle is the bundle the most recent attempt resolved and tried to
+	// install. It is not set if resolution failed.
+	// +optional
+	LastAttemptedBundle *BundleMetadata `json:"lastAttemptedBundle,omitempty"`
+	// firstFailureTime is when the first of the consecutive attempts failed.
+	FirstFailureTime metav1.Time `json:"firstFailureTime"`
+	// lastAttemptTime is when the most recent attempt failed.
+	LastAttemptTime metav1.Time `json:"lastAttemptTime"`
+	// nextRetryTime is when the controller retries according to the backoff of
+	// status.failureClass. A change to the extension or to its catalogs triggers an
+	// earlier retry.
+	NextRetryTime metav1.Time `json:"nextRetryTime"`
+}
+
+// ReconcileTimings are the durations of the phases of a reconcile. Phases that were
+// not reached are omitted.
+type ReconcileTimings struct {
+	// resolution is the time taken to resolve the bundle.
+	// +optional
+	Resolution *metav1.Duration `json:"resolution,omitempty"`
+	// preflight is the time taken to run the preflight checks against the resolved bundle.
+	// +optional
+	Preflight *metav1.Duration `json:"preflight,omitempty"`
+	// apply is the time taken to create or update the BundleDeployment.
+	// +optional
+	Apply *metav1.Duration `json:"apply,omitempty"`
+	// total is the time taken by the whole reconcile.
+	// +optional
+	Total *metav1.Duration `json:"total,omitempty"`
+}
+
+// UninstallStatus describes an uninstall that has not completed because of spec.uninstall.crPolicy.
+type UninstallStatus struct {
+	// message describes what the uninstall is waiting for.
+	Message string `json:"message"`
+	// remainingCustomResources lists up to 10 of the custom resources that remain, as
+	// <crd name>/<namespace>/<name>, or <crd name>/<name> for cluster-scoped resources.
+	// +optional
+	RemainingCustomResources []string `json:"remainingCustomResources,omitempty"`
+}
+
+// ResolutionStatus describes the inputs and outcome of a resolution.
+type ResolutionStatus struct {
+	// selected describes the bundle chosen by the most recent resolution and where it
+	// came from. It is not set when resolution failed.
+	// +optional
+	Selected *SelectedBundle `json:"selected,omitempty"`
+	// catalogs lists every catalog considered during resolution, ordered by name.
+	// +optional
+	Catalogs []CatalogResolutionStatus `json:"catalogs,omitempty"`
+	// catalogSelector is the controller's default catalog selector, in label selector
+	// syntax, when resolution was restricted to the catalogs matching it because
+	// spec.catalogSelector is not set. It is not set otherwise.
+	// +optional
+	CatalogSelector string `json:"catalogSelector,omitempty"`
+	// releaseLag describes the release targeted by spec.upgrade.lagReleases.
+	// +optional
+	ReleaseLag *ReleaseLagStatus `json:"releaseLag,omitempty"`
+	// upgradePath describes the upgrades planned to reach the bundle the spec resolves
+	// to when no upgrade edge leads to it directly from the installed bundle, but a
+	// sequence of upgrades does. The first bundle of the path is selected, and each
+	// later one is selected once the previous one is installed.
+	// +optional
+	UpgradePath *UpgradePath `json:"upgradePath,omitempty"`
+	// heldBackBy lists the constraints of dependent ClusterExtensions that excluded
+	// bundles which would otherwise have been preferred over the resolved bundle.
+	// +optional
+	HeldBackBy []DependentConstraint `json:"heldBackBy,omitempty"`
+	// heldBackByClusterVersion describes the bundle that would otherwise have been
+	// preferred over the resolved bundle, had it been compatible with the cluster
+	// version under spec.upgrade.clusterVersionPolicy.
+	// +optional
+	HeldBackByClusterVersion *ClusterVersionHold `json:"heldBackByClusterVersion,omitempty"`
+	// deprecatedFallback is true when the selected bundle is deprecated, or is only in
+	// deprecated channels. Such bundles are only selected when no other bundle satisfies
+	// every constraint of the ClusterExtension.
+	// +optional
+	DeprecatedFallback bool `json:"deprecatedFallback,omitempty"`
+	// tiedCatalogs lists the catalogs that provided candidates when there is more
+	// than one. Only the catalogs with the highest priority among those providing the
+	// package are resolved from, so these catalogs share the same priority and the
+	// selected bundle was chosen among their bundles by version, then by catalog name.
+	// Resolution fails with reason ResolutionAmbiguous instead if they provide
+	// different images for the version that would be resolved.
+	// +optional
+	TiedCatalogs []string `json:"tiedCatalogs,omitempty"`
+	// candidates lists the bundles that satisfied every constraint, most preferred
+	// first, up to spec.resolution.reportCandidates of them.
+	// +optional
+	Candidates []ResolutionCandidate `json:"candidates,omitempty"`
+	// explanation describes why resolution failed. It is only set when it did.
+	// +optional
+	Explanation *ResolutionExplanation `json:"explanation,omitempty"`
+}
+
+// ResolutionExplanation describes the constraints a failed resolution considered and
+// the bundles of the package they rejected.
+type ResolutionExplanation struct {
+	// constraints lists the constraints considered, in the order they were applied.
+	// +optional
+	Constraints []string `json:"constraints,omitempty"`
+	// rejected lists the bundles of the package that were rejected, newest version
+	// first, up to 20 of them.
+	// +optional
+	Rejected []RejectedBundle `json:"rejected,omitempty"`
+	// rejectedCount is the number of bundles of the package that were rejected,
+	// including those not listed in rejected.
+	// +optional
+	RejectedCount int32 `json:"rejectedCount,omitempty"`
+}
+
+// RejectionReason is the constraint that rejected a bundle during resolution.
+// +kubebuilder:validation:Enum:=Channel;VersionRange;MinimumVersion;Prerelease;SelectorExpression;VersionBlocklist;UpgradeEdge;ImageDigest;LagReleases;CatalogPriority;DependentConstraint;BundleConstraint;KubernetesVersion;Platform;ClusterVersion
+type RejectionReason string
+
+const (
+	RejectionReasonChannel             RejectionReason = "Channel"
+	RejectionReasonVersionRange        RejectionReason = "VersionRange"
+	RejectionReasonMinimumVersion      RejectionReason = "MinimumVersion"
+	RejectionReasonPrerelease          RejectionReason = "Prerelease"
+	RejectionReasonSelectorExpression  RejectionReason = "SelectorExpression"
+	RejectionReasonVersionBlocklist    RejectionReason = "VersionBlocklist"
+	RejectionReasonUpgradeEdge         RejectionReason = "UpgradeEdge"
+	RejectionReasonImageDigest         RejectionReason = "ImageDigest"
+	RejectionReasonLagReleases         RejectionReason = "LagReleases"
+	RejectionReasonCatalogPriority     RejectionReason = "CatalogPriority"
+	RejectionReasonDependentConstraint RejectionReason = "DependentConstraint"
+	RejectionReasonBundleConstraint    RejectionReason = "BundleConstraint"
+	RejectionReasonKubernetesVersion   RejectionReason = "KubernetesVersion"
+	RejectionReasonPlatform            RejectionReason = "Platform"
+	RejectionReasonClusterVersion      RejectionReason = "ClusterVersion"
+)
+
+// RejectedBundle is a bundle that a constraint rejected during resolution.
+type RejectedBundle struct {
+	// bundle is the name and version of the bundle.
+	Bundle BundleMetadata `json:"bundle"`
+	// catalog is the name of the catalog the bundle came from.
+	Catalog string `json:"catalog"`
+	// reason is the first constraint that rejected the bundle.
+	Reason RejectionReason `json:"reason"`
+	// message is a human readable description of why the bundle was rejected.
+	Message string `json:"message"`
+	// deprecated is true when the bundle is deprecated, or is only in deprecated
+	// channels, so it would have been ranked below bundles that are not.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// SelectedBundle describes the bundle chosen by resolution.
+type SelectedBundle struct {
+	// bundle is the name and version of the bundle.
+	Bundle BundleMetadata `json:"bundle"`
+	// package is the name of the package the bundle belongs to.
+	Package string `json:"package"`
+	// channels lists the channels of the package the bundle is published in.
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+	// catalog is the name of the catalog the bundle came from.
+	Catalog string `json:"catalog"`
+	// image is the image reference of the bundle, as published in its catalog.
+	Image string `json:"image"`
+	// tag is the tag of the image reference, if it has one.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+	// digest is the digest of the image reference, if it has one.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+// DryRunStatus describes the BundleDeployment that a dry run would apply.
+type DryRunStatus struct {
+	// bundle is the bundle that would be installed.
+	Bundle BundleMetadata `json:"bundle"`
+	// changes lists the fields of the BundleDeployment that would change, each as
+	// "<path>: <current> -> <desired>" with JSON values, in path order. It is empty
+	// if the BundleDeployment is up to date, and if it does not exist yet, every field
+	// is listed as changing from null.
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+	// objectCount is the number of objects the install would create. It is only set
+	// for configMapBundle installs: rukpak renders catalog and image bundles itself,
+	// so their objects are not known before the BundleDeployment is applied.
+	// +optional
+	ObjectCount *int32 `json:"objectCount,omitempty"`
+	// estimatedBytes is a rough estimate of the storage those objects need, as the
+	// total size of their JSON encodings. It is set along with objectCount.
+	// +optional
+	EstimatedBytes *int64 `json:"estimatedBytes,omitempty"`
+}
+
+// InstallRevision records a successful install of a bundle.
+type InstallRevision struct {
+	// revision numbers the installs of the ClusterExtension, starting at 1.
+	Revision int64 `json:"revision"`
+	// bundle is the installed bundle.
+	Bundle BundleMetadata `json:"bundle"`
+	// image is the image reference the bundle was installed from.
+	Image string `json:"image"`
+	// rollbackOf is the revision that this install rolled back to, if it was
+	// installed by spec.rollbackTo.
+	// +optional
+	RollbackOf int64 `json:"rollbackOf,omitempty"`
+}
+
+// PendingUpgradeReason is the reason a newer bundle has not been installed.
+type PendingUpgradeReason string
+
+const (
+	// The upgrade waits for its version to be set in spec.upgrade.approvedVersion.
+	PendingUpgradeReasonApprovalRequired PendingUpgradeReason = "ApprovalRequired"
+
+	// spec.upgrade.clusterVersionPolicy is Enforce and the bundle is not compatible
+	// with the current or next cluster version.
+	PendingUpgradeReasonClusterVersionIncompatible PendingUpgradeReason = "ClusterVersionIncompatible"
+
+	// The bundle would break a ClusterExtension that depends on the installed bundle.
+	PendingUpgradeReasonDependentConstraint PendingUpgradeReason = "DependentConstraint"
+
+	// spec.upgradeConstraintPolicy is Enforce and the catalog publishes no upgrade
+	// edge from the installed bundle to the bundle.
+	PendingUpgradeReasonNoUpgradeEdge PendingUpgradeReason = "NoUpgradeEdge"
+)
+
+// PendingUpgrade describes a bundle that satisfies the ClusterExtension's spec and
+// is preferred over the installed bundle, but has not been installed.
+type PendingUpgrade struct {
+	// bundle is the bundle that has not been installed.
+	Bundle BundleMetadata `json:"bundle"`
+	// image is the image reference of the bundle, as published in its catalog.
+	Image string `json:"image"`
+	// channels lists the channels of the package the bundle is published in.
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+	// reason is why the bundle has not been installed.
+	Reason PendingUpgradeReason `json:"reason"`
+	// message is a human readable description of why the bundle has not been installed.
+	Message string `json:"message"`
+}
+
+// ResolutionCandidate is a bundle that satisfied every constraint of the ClusterExtension.
+type ResolutionCandidate struct {
+	// name is the name of the bundle
+	Name string `json:"name"`
+	// version is the version of the bundle
+	Version string `json:"version"`
+	// catalog is the name of the catalog the bundle came from
+	Catalog string `json:"catalog"`
+	// deprecated is true when the bundle itself is deprecated.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// ClusterVersionHold describes a bundle excluded because it is not compatible with the cluster version.
+type ClusterVersionHold struct {
+	// bundle is the excluded bundle.
+	Bundle BundleMetadata `json:"bundle"`
+	// compatibleClusterVersions is the range of cluster versions the bundle declares it supports.
+	CompatibleClusterVersions string `json:"compatibleClusterVersions"`
+	// clusterVersion is the current version of the cluster.
+	ClusterVersion string `json:"clusterVersion"`
+	// nextClusterVersion is the version the cluster is upgrading to, if an upgrade is planned.
+	// +optional
+	NextClusterVersion string `json:"nextClusterVersion,omitempty"`
+}
+
+// DependentConstraint is a requirement that an installed ClusterExtension places on
+// the bundle of the ClusterExtension it depends on.
+type DependentConstraint struct {
+	// clusterExtension is the name of the dependent ClusterExtension
+	ClusterExtension string `json:"clusterExtension"`
+	// constraint describes the requirement, e.g. a package version range or an API.
+	Constraint string `json:"constraint"`
+}
+
+// UpgradePath describes a sequence of upgrades from the installed bundle.
+type UpgradePath struct {
+	// target is the bundle the upgrades lead to.
+	Target BundleMetadata `json:"target"`
+	// bundles lists the bundles still to be installed, in order, from the selected
+	// bundle to the target.
+	Bundles []BundleMetadata `json:"bundles"`
+}
+
+// ReleaseLagStatus describes how far the resolved bundle is behind the head of its channel.
+type ReleaseLagStatus struct {
+	// headVersion is the newest version in the channel.
+	HeadVersion string `json:"headVersion"`
+	// targetVersion is the newest version the ClusterExtension may resolve to,
+	// spec.upgrade.lagReleases releases behind the head.
+	TargetVersion string `json:"targetVersion"`
+	// releasesBehindHead is the number of releases in the channel that are newer
+	// than the resolved bundle.
+	ReleasesBehindHead int32 `json:"releasesBehindHead"`
+}
+
+// CatalogResolutionStatus describes how a single catalog contributed to a resolution.
+type CatalogResolutionStatus struct {
+	// name is the name of the catalog
+	Name string `json:"name"`
+	// priority is the priority of the catalog, from its olm.operatorframework.io/priority label.
+	Priority int32 `json:"priority"`
+	// candidates is the number of bundles from this catalog that satisfied
+	// every constraint of the ClusterExtension.
+	Candidates int32 `json:"candidates"`
+	// selected is true when the resolved bundle came from this catalog.
+	Selected bool `json:"selected"`
+	// resolvedRef is the digest reference of the catalog image the bundles were read
+	// from, which spec.catalogSnapshots can pin.
+	// +optional
+	ResolvedRef string `json:"resolvedRef,omitempty"`
+	// overriddenBundles lists the bundles of lower priority catalogs that were
+	// replaced by a bundle of the same name from this catalog.
+	// +optional
+	OverriddenBundles []OverriddenBundle `json:"overriddenBundles,omitempty"`
+}
+
+// OverriddenBundle identifies a bundle replaced by a higher priority catalog's bundle of the same name.
+type OverriddenBundle struct {
+	// name is the name of the overridden bundle
+	Name string `json:"name"`
+	// catalog is the name of the catalog the overridden bundle came from
+	Catalog string `json:"catalog"`
+}
+
+// PreflightCheckStatus is the result of a single preflight check.
+type PreflightCheckStatus struct {
+	// name is the name of the preflight check
+	Name string `json:"name"`
+	// conditionType is the type of the condition the check also reports its result
+	// under, for checks that contribute their own condition.
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+	// passed is true when the resolved bundle passed the check.
+	Passed bool `json:"passed"`
+	// message describes why the check failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ResolvedDependency describes a package dependency and the ClusterExtension satisfying it.
+type ResolvedDependency struct {
+	// package is the name of the required package.
+	Package string `json:"package"`
+	// versionRange is the range of versions of the package that is required.
+	VersionRange string `json:"versionRange"`
+	// requiredBy is the name of the package whose bundle requires the package.
+	RequiredBy string `json:"requiredBy"`
+	// clusterExtension is the name of the ClusterExtension satisfying the dependency.
+	// It is empty if no ClusterExtension satisfies it.
+	// +optional
+	ClusterExtension string `json:"clusterExtension,omitempty"`
+	// version is the version of the package the ClusterExtension has installed. It is
+	// empty while a ClusterExtension created for the dependency has not installed it yet.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// AttentionStatus summarizes the conditions that need human attention.
+type AttentionStatus struct {
+	// required is true when any condition needs human attention.
+	Required bool `json:"required"`
+	//+kubebuilder:validation:Enum:=None;Warning;Critical
+	// severity is the highest severity among the causes, or None.
+	Severity AttentionSeverity `json:"severity"`
+	// causes lists the conditions that need attention, most severe first.
+	// +optional
+	Causes []AttentionCause `json:"causes,omitempty"`
+}
+
+// AttentionCause is a condition that needs human attention.
+type AttentionCause struct {
+	// conditionType is the type of the condition
+	ConditionType string `json:"conditionType"`
+	// reason is the reason of the condition
+	Reason string `json:"reason"`
+	//+kubebuilder:validation:Enum:=Warning;Critical
+	// severity is how urgently the condition needs attention
+	Severity AttentionSeverity `json:"severity"`
+	// message is the message of the condition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Cluster
 //+kubebuilder:subresource:status
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterExtensionRevisionOwnerLabel is the label set on each ClusterExtensionRevision
+// to the name of the ClusterExtension it records an install of.
+const ClusterExtensionRevisionOwnerLabel = "olm.operatorframework.io/owner-name"
+
+// ClusterExtensionRevisionSpec records a successful install of a bundle.
+//
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type ClusterExtensionRevisionSpec struct {
+	// clusterExtensionName is the name of the ClusterExtension the bundle was installed for.
+	ClusterExtensionName string `json:"clusterExtensionName"`
+	// revision is the revision of the install in the ClusterExtension's status.installHistory.
+	Revision int64 `json:"revision"`
+	// bundle is the installed bundle.
+	Bundle BundleMetadata `json:"bundle"`
+	// image is the image reference the bundle was installed from.
+	Image string `json:"image"`
+	// manifestHash is a hash of the BundleDeployment spec the bundle was installed
+	// with, which determines the manifests rendered from the bundle image.
+	ManifestHash string `json:"manifestHash"`
+	// rollbackOf is the revision that this install rolled back to, if it was
+	// installed by spec.rollbackTo.
+	// +optional
+	RollbackOf int64 `json:"rollbackOf,omitempty"`
+	// installedAt is when the install was observed to have succeeded.
+	InstalledAt metav1.Time `json:"installedAt"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name=Extension,type=string,JSONPath=`.spec.clusterExtensionName`
+//+kubebuilder:printcolumn:name=Revision,type=integer,JSONPath=`.spec.revision`
+//+kubebuilder:printcolumn:name=Bundle,type=string,JSONPath=`.spec.bundle.name`
+//+kubebuilder:printcolumn:name=Age,type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterExtensionRevision is the Schema for the clusterextensionrevisions API. One is
+// created for each revision recorded in a ClusterExtension's status.installHistory and
+// is deleted with the ClusterExtension.
+type ClusterExtensionRevision struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterExtensionRevisionSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterExtensionRevisionList contains a list of ClusterExtensionRevision
+type ClusterExtensionRevisionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterExtensionRevision `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterExtensionRevision{}, &ClusterExtensionRevisionList{})
+}
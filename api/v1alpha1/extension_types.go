@@ -117,7 +117,10 @@ type BundleMetadata struct {
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Paused",type=string,JSONPath=`.status.paused`,description="The current reconciliation state of this extension"
 
-// Extension is the Schema for the extensions API
+// Extension is the Schema for the extensions API. An Extension installs a plain bundle
+// into its own namespace with the permissions of spec.serviceAccountName, so a tenant
+// admin can only grant it a namespaced footprint. Bundles that provide APIs need
+// cluster-scoped CustomResourceDefinitions and are refused.
 type Extension struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -0,0 +1,154 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResolutionRequestSpec holds the constraints to resolve a bundle for. They have the
+// same meaning as the fields of the same name in a ClusterExtension spec.
+//
+// +kubebuilder:validation:XValidation:rule="!has(self.channel) || !has(self.channels)",message="channel and channels are mutually exclusive"
+type ResolutionRequestSpec struct {
+	//+kubebuilder:validation:MaxLength:=48
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]+(-[a-z0-9]+)*$
+	//
+	// packageName is the name of the package to resolve.
+	PackageName string `json:"packageName"`
+
+	//+kubebuilder:validation:MaxLength:=64
+	//+kubebuilder:validation:Pattern=`^(\s*(=||!=|>|<|>=|=>|<=|=<|~|~>|\^)\s*(v?(0|[1-9]\d*|[x|X|\*])(\.(0|[1-9]\d*|x|X|\*]))?(\.(0|[1-9]\d*|x|X|\*))?(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?)\s*)((?:\s+|,\s*|\s*\|\|\s*)(=||!=|>|<|>=|=>|<=|=<|~|~>|\^)\s*(v?(0|[1-9]\d*|x|X|\*])(\.(0|[1-9]\d*|x|X|\*))?(\.(0|[1-9]\d*|x|X|\*]))?(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?)\s*)*$`
+	//+kubebuilder:Optional
+	//
+	// version is a semver constraint on the package version.
+	// Examples: 1.2.3, >=1.2.0 <2.0.0, ~1.2.0
+	Version string `json:"version,omitempty"`
+
+	//+kubebuilder:validation:MaxLength:=64
+	//+kubebuilder:validation:Pattern=`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?$`
+	//+kubebuilder:Optional
+	//
+	// minimumVersion is the lowest version of the package that may be resolved.
+	MinimumVersion string `json:"minimumVersion,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// allowPrerelease opts in to resolving pre-release versions of the package.
+	AllowPrerelease bool `json:"allowPrerelease,omitempty"`
+
+	//+kubebuilder:validation:MaxLength:=48
+	//+kubebuilder:validation:Pattern:=^[a-z0-9]+([\.-][a-z0-9]+)*$
+	//+kubebuilder:Optional
+	//
+	// channel restricts resolution to bundles published in the named channel.
+	Channel string `json:"channel,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=16
+	//+kubebuilder:Optional
+	//
+	// channels restricts resolution to bundles published in any of the named channels.
+	// Mutually exclusive with channel.
+	Channels []ChannelName `json:"channels,omitempty"`
+
+	//+kubebuilder:Optional
+	//
+	// catalogSelector restricts resolution to the catalogs whose labels match it. If
+	// unset, the controller's default catalog selector is used.
+	CatalogSelector *metav1.LabelSelector `json:"catalogSelector,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=16
+	//+kubebuilder:Optional
+	//+listType=map
+	//+listMapKey=catalog
+	//
+	// catalogSnapshots restricts resolution to the listed catalogs, each of which must
+	// currently be unpacked from the listed image.
+	CatalogSnapshots []CatalogSnapshot `json:"catalogSnapshots,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=8
+	//+kubebuilder:Optional
+	//
+	// selectorExpressions are CEL expressions that a bundle must all evaluate to true for
+	// to be resolved.
+	SelectorExpressions []string `json:"selectorExpressions,omitempty"`
+
+	//+kubebuilder:validation:MaxItems:=64
+	//+kubebuilder:Optional
+	//
+	// versionBlocklist lists versions of the package that are never resolved.
+	VersionBlocklist []string `json:"versionBlocklist,omitempty"`
+
+	//+kubebuilder:validation:Enum:=Ignore;Enforce
+	//+kubebuilder:default:=Ignore
+	//+kubebuilder:Optional
+	//
+	// clusterVersionPolicy defines whether bundles must be compatible with the cluster
+	// version, as spec.upgrade.clusterVersionPolicy does for a ClusterExtension.
+	ClusterVersionPolicy ClusterVersionPolicy `json:"clusterVersionPolicy,omitempty"`
+}
+
+// ResolutionRequestStatus is the outcome of resolving the constraints of a
+// ResolutionRequest.
+type ResolutionRequestStatus struct {
+	// resolvedBundle is the bundle a fresh install of a ClusterExtension with the
+	// requested constraints would install. It is not set when resolution failed.
+	// +optional
+	ResolvedBundle *BundleMetadata `json:"resolvedBundle,omitempty"`
+
+	// resolution describes how the bundle was chosen, and explains a failed resolution.
+	// +optional
+	Resolution *ResolutionStatus `json:"resolution,omitempty"`
+
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name=Package,type=string,JSONPath=`.spec.packageName`
+//+kubebuilder:printcolumn:name=Bundle,type=string,JSONPath=`.status.resolvedBundle.name`
+//+kubebuilder:printcolumn:name=Resolved,type=string,JSONPath=`.status.conditions[?(@.type=="Resolved")].status`
+//+kubebuilder:printcolumn:name=Age,type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ResolutionRequest is the Schema for the resolutionrequests API. It asks which bundle
+// a ClusterExtension with the given constraints would install, without installing it.
+// The answer is kept up to date as the catalogs change.
+type ResolutionRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ResolutionRequestSpec   `json:"spec"`
+	Status ResolutionRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ResolutionRequestList contains a list of ResolutionRequest
+type ResolutionRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ResolutionRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ResolutionRequest{}, &ResolutionRequestList{})
+}
@@ -25,6 +25,56 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttentionCause) DeepCopyInto(out *AttentionCause) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttentionCause.
+func (in *AttentionCause) DeepCopy() *AttentionCause {
+	if in == nil {
+		return nil
+	}
+	out := new(AttentionCause)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttentionStatus) DeepCopyInto(out *AttentionStatus) {
+	*out = *in
+	if in.Causes != nil {
+		in, out := &in.Causes, &out.Causes
+		*out = make([]AttentionCause, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttentionStatus.
+func (in *AttentionStatus) DeepCopy() *AttentionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AttentionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleImage) DeepCopyInto(out *BundleImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleImage.
+func (in *BundleImage) DeepCopy() *BundleImage {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundleMetadata) DeepCopyInto(out *BundleMetadata) {
 	*out = *in
@@ -40,6 +90,41 @@ func (in *BundleMetadata) DeepCopy() *BundleMetadata {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogResolutionStatus) DeepCopyInto(out *CatalogResolutionStatus) {
+	*out = *in
+	if in.OverriddenBundles != nil {
+		in, out := &in.OverriddenBundles, &out.OverriddenBundles
+		*out = make([]OverriddenBundle, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogResolutionStatus.
+func (in *CatalogResolutionStatus) DeepCopy() *CatalogResolutionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogResolutionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogSnapshot) DeepCopyInto(out *CatalogSnapshot) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSnapshot.
+func (in *CatalogSnapshot) DeepCopy() *CatalogSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterExtension) DeepCopyInto(out *ClusterExtension) {
 	*out = *in
@@ -99,14 +184,171 @@ func (in *ClusterExtensionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterExtensionRevision) DeepCopyInto(out *ClusterExtensionRevision) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExtensionRevision.
+func (in *ClusterExtensionRevision) DeepCopy() *ClusterExtensionRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterExtensionRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterExtensionRevision) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterExtensionRevisionList) DeepCopyInto(out *ClusterExtensionRevisionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterExtensionRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExtensionRevisionList.
+func (in *ClusterExtensionRevisionList) DeepCopy() *ClusterExtensionRevisionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterExtensionRevisionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterExtensionRevisionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterExtensionRevisionSpec) DeepCopyInto(out *ClusterExtensionRevisionSpec) {
+	*out = *in
+	out.Bundle = in.Bundle
+	in.InstalledAt.DeepCopyInto(&out.InstalledAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExtensionRevisionSpec.
+func (in *ClusterExtensionRevisionSpec) DeepCopy() *ClusterExtensionRevisionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterExtensionRevisionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterExtensionSpec) DeepCopyInto(out *ClusterExtensionSpec) {
 	*out = *in
+	if in.ProvidedAPI != nil {
+		in, out := &in.ProvidedAPI, &out.ProvidedAPI
+		*out = new(ProvidedAPI)
+		**out = **in
+	}
+	if in.ConfigMapBundle != nil {
+		in, out := &in.ConfigMapBundle, &out.ConfigMapBundle
+		*out = new(ConfigMapBundle)
+		**out = **in
+	}
+	if in.BundleImage != nil {
+		in, out := &in.BundleImage, &out.BundleImage
+		*out = new(BundleImage)
+		**out = **in
+	}
+	if in.SelectorExpressions != nil {
+		in, out := &in.SelectorExpressions, &out.SelectorExpressions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VersionBlocklist != nil {
+		in, out := &in.VersionBlocklist, &out.VersionBlocklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]ChannelName, len(*in))
+		copy(*out, *in)
+	}
+	if in.CatalogSelector != nil {
+		in, out := &in.CatalogSelector, &out.CatalogSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CatalogSnapshots != nil {
+		in, out := &in.CatalogSnapshots, &out.CatalogSnapshots
+		*out = make([]CatalogSnapshot, len(*in))
+		copy(*out, *in)
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(UpgradeConfig)
+		**out = **in
+	}
+	if in.Resolution != nil {
+		in, out := &in.Resolution, &out.Resolution
+		*out = new(ResolutionConfig)
+		**out = **in
+	}
 	if in.WatchNamespaces != nil {
 		in, out := &in.WatchNamespaces, &out.WatchNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Install != nil {
+		in, out := &in.Install, &out.Install
+		*out = new(InstallConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(PreflightConfig)
+		**out = **in
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = new(DependenciesConfig)
+		**out = **in
+	}
+	if in.Uninstall != nil {
+		in, out := &in.Uninstall, &out.Uninstall
+		*out = new(UninstallConfig)
+		**out = **in
+	}
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(RollbackConfig)
+		**out = **in
+	}
+	if in.RevisionLabels != nil {
+		in, out := &in.RevisionLabels, &out.RevisionLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExtensionSpec.
@@ -124,14 +366,64 @@ func (in *ClusterExtensionStatus) DeepCopyInto(out *ClusterExtensionStatus) {
 	*out = *in
 	if in.InstalledBundle != nil {
 		in, out := &in.InstalledBundle, &out.InstalledBundle
-		*out = new(BundleMetadata)
-		**out = **in
+		*out = new(InstalledBundleMetadata)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.ResolvedBundle != nil {
 		in, out := &in.ResolvedBundle, &out.ResolvedBundle
 		*out = new(BundleMetadata)
 		**out = **in
 	}
+	if in.Resolution != nil {
+		in, out := &in.Resolution, &out.Resolution
+		*out = new(ResolutionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreflightChecks != nil {
+		in, out := &in.PreflightChecks, &out.PreflightChecks
+		*out = make([]PreflightCheckStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]ResolvedDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retry != nil {
+		in, out := &in.Retry, &out.Retry
+		*out = new(RetryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Attention != nil {
+		in, out := &in.Attention, &out.Attention
+		*out = new(AttentionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(DryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timings != nil {
+		in, out := &in.Timings, &out.Timings
+		*out = new(ReconcileTimings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Uninstall != nil {
+		in, out := &in.Uninstall, &out.Uninstall
+		*out = new(UninstallStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingUpgrade != nil {
+		in, out := &in.PendingUpgrade, &out.PendingUpgrade
+		*out = new(PendingUpgrade)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstallHistory != nil {
+		in, out := &in.InstallHistory, &out.InstallHistory
+		*out = make([]InstallRevision, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -151,6 +443,98 @@ func (in *ClusterExtensionStatus) DeepCopy() *ClusterExtensionStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterVersionHold) DeepCopyInto(out *ClusterVersionHold) {
+	*out = *in
+	out.Bundle = in.Bundle
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterVersionHold.
+func (in *ClusterVersionHold) DeepCopy() *ClusterVersionHold {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterVersionHold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapBundle) DeepCopyInto(out *ConfigMapBundle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapBundle.
+func (in *ConfigMapBundle) DeepCopy() *ConfigMapBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependenciesConfig) DeepCopyInto(out *DependenciesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependenciesConfig.
+func (in *DependenciesConfig) DeepCopy() *DependenciesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DependenciesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependentConstraint) DeepCopyInto(out *DependentConstraint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependentConstraint.
+func (in *DependentConstraint) DeepCopy() *DependentConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(DependentConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DryRunStatus) DeepCopyInto(out *DryRunStatus) {
+	*out = *in
+	out.Bundle = in.Bundle
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ObjectCount != nil {
+		in, out := &in.ObjectCount, &out.ObjectCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EstimatedBytes != nil {
+		in, out := &in.EstimatedBytes, &out.EstimatedBytes
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DryRunStatus.
+func (in *DryRunStatus) DeepCopy() *DryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Extension) DeepCopyInto(out *Extension) {
 	*out = *in
@@ -292,3 +676,599 @@ func (in *ExtensionStatus) DeepCopy() *ExtensionStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallConfig) DeepCopyInto(out *InstallConfig) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallConfig.
+func (in *InstallConfig) DeepCopy() *InstallConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallRevision) DeepCopyInto(out *InstallRevision) {
+	*out = *in
+	out.Bundle = in.Bundle
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallRevision.
+func (in *InstallRevision) DeepCopy() *InstallRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstalledBundleMetadata) DeepCopyInto(out *InstalledBundleMetadata) {
+	*out = *in
+	out.BundleMetadata = in.BundleMetadata
+	if in.LastInstalled != nil {
+		in, out := &in.LastInstalled, &out.LastInstalled
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstalledBundleMetadata.
+func (in *InstalledBundleMetadata) DeepCopy() *InstalledBundleMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(InstalledBundleMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OverriddenBundle) DeepCopyInto(out *OverriddenBundle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverriddenBundle.
+func (in *OverriddenBundle) DeepCopy() *OverriddenBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(OverriddenBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingUpgrade) DeepCopyInto(out *PendingUpgrade) {
+	*out = *in
+	out.Bundle = in.Bundle
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingUpgrade.
+func (in *PendingUpgrade) DeepCopy() *PendingUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheckStatus) DeepCopyInto(out *PreflightCheckStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheckStatus.
+func (in *PreflightCheckStatus) DeepCopy() *PreflightCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightConfig) DeepCopyInto(out *PreflightConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightConfig.
+func (in *PreflightConfig) DeepCopy() *PreflightConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvidedAPI) DeepCopyInto(out *ProvidedAPI) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvidedAPI.
+func (in *ProvidedAPI) DeepCopy() *ProvidedAPI {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvidedAPI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileTimings) DeepCopyInto(out *ReconcileTimings) {
+	*out = *in
+	if in.Resolution != nil {
+		in, out := &in.Resolution, &out.Resolution
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Total != nil {
+		in, out := &in.Total, &out.Total
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileTimings.
+func (in *ReconcileTimings) DeepCopy() *ReconcileTimings {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileTimings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RejectedBundle) DeepCopyInto(out *RejectedBundle) {
+	*out = *in
+	out.Bundle = in.Bundle
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RejectedBundle.
+func (in *RejectedBundle) DeepCopy() *RejectedBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(RejectedBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseLagStatus) DeepCopyInto(out *ReleaseLagStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseLagStatus.
+func (in *ReleaseLagStatus) DeepCopy() *ReleaseLagStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseLagStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionCandidate) DeepCopyInto(out *ResolutionCandidate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionCandidate.
+func (in *ResolutionCandidate) DeepCopy() *ResolutionCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionConfig) DeepCopyInto(out *ResolutionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionConfig.
+func (in *ResolutionConfig) DeepCopy() *ResolutionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionExplanation) DeepCopyInto(out *ResolutionExplanation) {
+	*out = *in
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rejected != nil {
+		in, out := &in.Rejected, &out.Rejected
+		*out = make([]RejectedBundle, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionExplanation.
+func (in *ResolutionExplanation) DeepCopy() *ResolutionExplanation {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionExplanation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionRequest) DeepCopyInto(out *ResolutionRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionRequest.
+func (in *ResolutionRequest) DeepCopy() *ResolutionRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResolutionRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionRequestList) DeepCopyInto(out *ResolutionRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ResolutionRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionRequestList.
+func (in *ResolutionRequestList) DeepCopy() *ResolutionRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ResolutionRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionRequestSpec) DeepCopyInto(out *ResolutionRequestSpec) {
+	*out = *in
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]ChannelName, len(*in))
+		copy(*out, *in)
+	}
+	if in.CatalogSelector != nil {
+		in, out := &in.CatalogSelector, &out.CatalogSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CatalogSnapshots != nil {
+		in, out := &in.CatalogSnapshots, &out.CatalogSnapshots
+		*out = make([]CatalogSnapshot, len(*in))
+		copy(*out, *in)
+	}
+	if in.SelectorExpressions != nil {
+		in, out := &in.SelectorExpressions, &out.SelectorExpressions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VersionBlocklist != nil {
+		in, out := &in.VersionBlocklist, &out.VersionBlocklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionRequestSpec.
+func (in *ResolutionRequestSpec) DeepCopy() *ResolutionRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionRequestStatus) DeepCopyInto(out *ResolutionRequestStatus) {
+	*out = *in
+	if in.ResolvedBundle != nil {
+		in, out := &in.ResolvedBundle, &out.ResolvedBundle
+		*out = new(BundleMetadata)
+		**out = **in
+	}
+	if in.Resolution != nil {
+		in, out := &in.Resolution, &out.Resolution
+		*out = new(ResolutionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionRequestStatus.
+func (in *ResolutionRequestStatus) DeepCopy() *ResolutionRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolutionStatus) DeepCopyInto(out *ResolutionStatus) {
+	*out = *in
+	if in.Selected != nil {
+		in, out := &in.Selected, &out.Selected
+		*out = new(SelectedBundle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Catalogs != nil {
+		in, out := &in.Catalogs, &out.Catalogs
+		*out = make([]CatalogResolutionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReleaseLag != nil {
+		in, out := &in.ReleaseLag, &out.ReleaseLag
+		*out = new(ReleaseLagStatus)
+		**out = **in
+	}
+	if in.UpgradePath != nil {
+		in, out := &in.UpgradePath, &out.UpgradePath
+		*out = new(UpgradePath)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeldBackBy != nil {
+		in, out := &in.HeldBackBy, &out.HeldBackBy
+		*out = make([]DependentConstraint, len(*in))
+		copy(*out, *in)
+	}
+	if in.HeldBackByClusterVersion != nil {
+		in, out := &in.HeldBackByClusterVersion, &out.HeldBackByClusterVersion
+		*out = new(ClusterVersionHold)
+		**out = **in
+	}
+	if in.TiedCatalogs != nil {
+		in, out := &in.TiedCatalogs, &out.TiedCatalogs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]ResolutionCandidate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Explanation != nil {
+		in, out := &in.Explanation, &out.Explanation
+		*out = new(ResolutionExplanation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolutionStatus.
+func (in *ResolutionStatus) DeepCopy() *ResolutionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolutionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedDependency) DeepCopyInto(out *ResolvedDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedDependency.
+func (in *ResolvedDependency) DeepCopy() *ResolvedDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryStatus) DeepCopyInto(out *RetryStatus) {
+	*out = *in
+	if in.LastAttemptedBundle != nil {
+		in, out := &in.LastAttemptedBundle, &out.LastAttemptedBundle
+		*out = new(BundleMetadata)
+		**out = **in
+	}
+	in.FirstFailureTime.DeepCopyInto(&out.FirstFailureTime)
+	in.LastAttemptTime.DeepCopyInto(&out.LastAttemptTime)
+	in.NextRetryTime.DeepCopyInto(&out.NextRetryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryStatus.
+func (in *RetryStatus) DeepCopy() *RetryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollbackConfig) DeepCopyInto(out *RollbackConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackConfig.
+func (in *RollbackConfig) DeepCopy() *RollbackConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RollbackConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectedBundle) DeepCopyInto(out *SelectedBundle) {
+	*out = *in
+	out.Bundle = in.Bundle
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectedBundle.
+func (in *SelectedBundle) DeepCopy() *SelectedBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectedBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UninstallConfig) DeepCopyInto(out *UninstallConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UninstallConfig.
+func (in *UninstallConfig) DeepCopy() *UninstallConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UninstallConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UninstallStatus) DeepCopyInto(out *UninstallStatus) {
+	*out = *in
+	if in.RemainingCustomResources != nil {
+		in, out := &in.RemainingCustomResources, &out.RemainingCustomResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UninstallStatus.
+func (in *UninstallStatus) DeepCopy() *UninstallStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UninstallStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeConfig) DeepCopyInto(out *UpgradeConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeConfig.
+func (in *UpgradeConfig) DeepCopy() *UpgradeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePath) DeepCopyInto(out *UpgradePath) {
+	*out = *in
+	out.Target = in.Target
+	if in.Bundles != nil {
+		in, out := &in.Bundles, &out.Bundles
+		*out = make([]BundleMetadata, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePath.
+func (in *UpgradePath) DeepCopy() *UpgradePath {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePath)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,10 +20,14 @@ import (
 	"flag"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 	"go.uber.org/zap/zapcore"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
@@ -43,10 +47,19 @@ var (
 
 func main() {
 	var (
-		metricsAddr          string
-		enableLeaderElection bool
-		probeAddr            string
-		cachePath            string
+		metricsAddr            string
+		enableLeaderElection   bool
+		probeAddr              string
+		cachePath              string
+		defaultCatalogSelector string
+		bundleConfigMapNS      string
+		metricsPackages        string
+		backoffPolicies        string
+		maxConcurrentInstalls  int
+		requirePinnedCatalogs  bool
+		revisionHistoryLimit   int
+		revisionLabels         string
+		resolutionTimeout      time.Duration
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -54,6 +67,31 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&cachePath, "cache-path", "/var/cache", "The local directory path used for filesystem based caching")
+	flag.StringVar(&defaultCatalogSelector, "default-catalog-selector", "",
+		"A label selector restricting which catalogs are considered when resolving ClusterExtensions "+
+			"that do not set spec.catalogSelector. If empty, all catalogs are considered.")
+	flag.StringVar(&bundleConfigMapNS, "bundle-configmap-namespace", "rukpak-system",
+		"The namespace holding the ConfigMaps referenced by ClusterExtension spec.configMapBundle. "+
+			"It must be the namespace rukpak unpacks ConfigMap bundle sources from.")
+	flag.StringVar(&metricsPackages, "resolution-metrics-packages", "",
+		"A comma-separated list of packages whose resolution outcomes are reported under their own package label. "+
+			"Outcomes for all other packages are reported under the \"other\" package label.")
+	flag.StringVar(&backoffPolicies, "failure-backoff", "",
+		"A comma-separated list of class=base:max entries overriding how failed ClusterExtension reconciles are retried, "+
+			"e.g. \"Auth=1m:1h,Transient=500ms:1m\". The classes are Auth, Transient, Resolution and Default.")
+	flag.BoolVar(&requirePinnedCatalogs, "require-pinned-catalogs", false,
+		"Only resolve ClusterExtensions from catalogs whose image is referenced by digest. "+
+			"Catalogs referenced by a tag are ignored.")
+	flag.IntVar(&maxConcurrentInstalls, "max-concurrent-installs", 0,
+		"The maximum number of ClusterExtensions that may be installing at once. "+
+			"Further installs wait in the order they were requested. Zero means no limit.")
+	flag.IntVar(&revisionHistoryLimit, "revision-history-limit", controllers.DefaultRevisionHistoryLimit,
+		"The number of ClusterExtensionRevisions kept for each ClusterExtension. The oldest are deleted.")
+	flag.StringVar(&revisionLabels, "revision-labels", "",
+		"A comma-separated list of key=value labels set on every ClusterExtensionRevision when it is created, "+
+			"e.g. \"team=payments,env=prod\". A ClusterExtension's spec.revisionLabels overrides them.")
+	flag.DurationVar(&resolutionTimeout, "resolution-timeout", controllers.DefaultResolutionTimeout,
+		"How long resolving a ClusterExtension may take before it fails with the ResolutionTimedOut reason. Zero means no limit.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,12 +103,40 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts), zap.StacktraceLevel(zapcore.DPanicLevel)))
 
+	catalogSelector, err := labels.Parse(defaultCatalogSelector)
+	if err != nil {
+		setupLog.Error(err, "unable to parse default catalog selector")
+		os.Exit(1)
+	}
+
+	defaultRevisionLabels := labels.Set{}
+	if revisionLabels != "" {
+		if defaultRevisionLabels, err = labels.ConvertSelectorToLabelsMap(revisionLabels); err != nil {
+			setupLog.Error(err, "unable to parse revision labels")
+			os.Exit(1)
+		}
+	}
+
+	resolutionMetricsPackages := sets.New[string]()
+	for _, pkg := range strings.Split(metricsPackages, ",") {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			resolutionMetricsPackages.Insert(pkg)
+		}
+	}
+
+	failureBackoff, err := controllers.ParseBackoffPolicies(backoffPolicies)
+	if err != nil {
+		setupLog.Error(err, "unable to parse failure backoff policies")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme.Scheme,
 		Metrics:                server.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "9c4404e7.operatorframework.io",
+		Cache:                  controllers.CacheOptions(bundleConfigMapNS),
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -89,12 +155,32 @@ func main() {
 	}
 
 	cl := mgr.GetClient()
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
 	catalogClient := catalogclient.New(cl, cache.NewFilesystemCache(cachePath, &http.Client{Timeout: 10 * time.Second}))
 
 	if err = (&controllers.ClusterExtensionReconciler{
-		Client:         cl,
-		BundleProvider: catalogClient,
-		Scheme:         mgr.GetScheme(),
+		Client:                    cl,
+		BundleProvider:            catalogClient,
+		Scheme:                    mgr.GetScheme(),
+		DefaultCatalogSelector:    catalogSelector,
+		BundleConfigMapNamespace:  bundleConfigMapNS,
+		CRDReader:                 mgr.GetAPIReader(),
+		ResolutionMetricsPackages: resolutionMetricsPackages,
+		BackoffPolicies:           failureBackoff,
+		Recorder:                  mgr.GetEventRecorderFor("operator-controller"),
+		MaxConcurrentInstalls:     maxConcurrentInstalls,
+		ClusterVersions:           &controllers.OpenShiftClusterVersions{Reader: mgr.GetAPIReader()},
+		KubernetesVersion:         &controllers.DiscoveryKubernetesVersion{Discovery: discoveryClient},
+		NodePlatforms:             &controllers.ClusterNodePlatforms{Reader: cl},
+		RequirePinnedCatalogs:     requirePinnedCatalogs,
+		CatalogRevisions:          &controllers.ClusterCatalogRevision{Reader: cl},
+		RevisionHistoryLimit:      revisionHistoryLimit,
+		RevisionLabels:            defaultRevisionLabels,
+		ResolutionTimeout:         resolutionTimeout,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterExtension")
 		os.Exit(1)
@@ -107,6 +193,19 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Extension")
 		os.Exit(1)
 	}
+
+	if err = (&controllers.ResolutionRequestReconciler{
+		Client:                 cl,
+		BundleProvider:         catalogClient,
+		DefaultCatalogSelector: catalogSelector,
+		RequirePinnedCatalogs:  requirePinnedCatalogs,
+		ClusterVersions:        &controllers.OpenShiftClusterVersions{Reader: mgr.GetAPIReader()},
+		KubernetesVersion:      &controllers.DiscoveryKubernetesVersion{Discovery: discoveryClient},
+		NodePlatforms:          &controllers.ClusterNodePlatforms{Reader: cl},
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ResolutionRequest")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,252 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// resolve reports how a set of ClusterExtensions would resolve against a
+// file-based catalog, without talking to a cluster. It lets catalog authors
+// check their upgrade graphs before publishing a catalog.
+//
+// The catalog is read from a file, or from every YAML and JSON file under a directory.
+// The cases file is a YAML or JSON list of entries like:
+//
+//	# cases.yaml
+//	- name: upgrade-from-1.0
+//	  installedBundle: foo.v1.0.0
+//	  spec:
+//	    packageName: foo
+//	    channel: stable
+//
+// Results are written to stdout as JSON. The command exits non-zero if any
+// case fails to resolve.
+//
+// With --graph, the upgrade graph of the named package in the catalog is written to
+// stdout as JSON instead, and no cases are needed. Its nodes are the package's
+// bundles and its edges the upgrades the resolver allows between them.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogclient "github.com/operator-framework/operator-controller/internal/catalogmetadata/client"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+type resolutionCase struct {
+	Name string `json:"name"`
+	// InstalledBundle is the name of the bundle to treat as currently installed.
+	InstalledBundle string                          `json:"installedBundle,omitempty"`
+	Spec            ocv1alpha1.ClusterExtensionSpec `json:"spec"`
+}
+
+type resolutionResult struct {
+	Name   string                     `json:"name"`
+	Bundle *ocv1alpha1.BundleMetadata `json:"bundle,omitempty"`
+	Image  string                     `json:"image,omitempty"`
+	Error  string                     `json:"error,omitempty"`
+}
+
+func main() {
+	var (
+		catalogPath string
+		catalogName string
+		casesPath   string
+		graphPkg    string
+	)
+	flag.StringVar(&catalogPath, "catalog", "", "Path to a file-based catalog file or directory.")
+	flag.StringVar(&catalogName, "catalog-name", "proposed", "The name to give the catalog in results.")
+	flag.StringVar(&casesPath, "cases", "", "Path to a YAML or JSON list of resolution cases.")
+	flag.StringVar(&graphPkg, "graph", "", "Write the upgrade graph of this package instead of resolving cases.")
+	flag.Parse()
+
+	if catalogPath == "" || (casesPath == "" && graphPkg == "") {
+		fmt.Fprintln(os.Stderr, "--catalog and either --cases or --graph are required")
+		os.Exit(2)
+	}
+
+	bundles, err := loadCatalog(catalogName, catalogPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading catalog: %s\n", err)
+		os.Exit(2)
+	}
+
+	if graphPkg != "" {
+		graph, err := controllers.BuildUpgradeGraph(graphPkg, bundles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error building upgrade graph: %s\n", err)
+			os.Exit(2)
+		}
+		writeResults(graph)
+		return
+	}
+
+	cases, err := loadCases(casesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading cases: %s\n", err)
+		os.Exit(2)
+	}
+
+	results := make([]resolutionResult, 0, len(cases))
+	failed := false
+	for _, c := range cases {
+		result := resolveCase(c, bundles)
+		if result.Error != "" {
+			failed = true
+		}
+		results = append(results, result)
+	}
+
+	writeResults(results)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// writeResults writes the results to stdout as indented JSON.
+func writeResults(results any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(results); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing results: %s\n", err)
+		os.Exit(2)
+	}
+}
+
+// loadCatalog reads the catalog from the file at path, or from every YAML and JSON
+// file under path if it is a directory.
+func loadCatalog(catalogName, path string) ([]*catalogmetadata.Bundle, error) {
+	var contents bytes.Buffer
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if p != path {
+			switch filepath.Ext(p) {
+			case ".yaml", ".yml", ".json":
+			default:
+				return nil
+			}
+		}
+		return appendCatalogFile(&contents, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bundles, parseErrs, err := catalogclient.ParseCatalog(catalogName, &contents)
+	if err != nil {
+		return nil, err
+	}
+	for _, parseErr := range parseErrs {
+		fmt.Fprintf(os.Stderr, "skipped invalid catalog entry: %s\n", parseErr)
+	}
+	return bundles, nil
+}
+
+// appendCatalogFile appends every document in the YAML or JSON file to contents as a
+// line of JSON, so that the documents of files in different formats can be read as
+// one stream.
+func appendCatalogFile(contents *bytes.Buffer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var doc json.RawMessage
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+		if err := json.Compact(contents, doc); err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		contents.WriteString("\n")
+	}
+}
+
+func loadCases(path string) ([]resolutionCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []resolutionCase
+	if err := yaml.NewYAMLOrJSONDecoder(f, 4096).Decode(&cases); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+func resolveCase(c resolutionCase, bundles []*catalogmetadata.Bundle) resolutionResult {
+	result := resolutionResult{Name: c.Name}
+	ext := &ocv1alpha1.ClusterExtension{Spec: c.Spec}
+	if ext.Spec.ConfigMapBundle != nil {
+		result.Error = "spec.configMapBundle is not resolved from a catalog"
+		return result
+	}
+	if ext.Spec.BundleImage != nil {
+		result.Error = "spec.bundleImage is not resolved from a catalog"
+		return result
+	}
+
+	var installedBundle *catalogmetadata.Bundle
+	if c.InstalledBundle != "" {
+		for _, b := range bundles {
+			if b.Name == c.InstalledBundle {
+				installedBundle = b
+				break
+			}
+		}
+		if installedBundle == nil {
+			result.Error = fmt.Sprintf("installed bundle %q not found in catalog", c.InstalledBundle)
+			return result
+		}
+	}
+
+	candidates, err := controllers.Resolve(ext, bundles, installedBundle)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	version, err := candidates[0].Version()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Bundle = &ocv1alpha1.BundleMetadata{Name: candidates[0].Name, Version: version.String()}
+	result.Image = candidates[0].Image
+	return result
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+const testPackageYAML = `---
+schema: olm.package
+name: foo
+defaultChannel: stable
+---
+schema: olm.channel
+name: stable
+package: foo
+entries:
+  - name: foo.v1.0.0
+  - name: foo.v1.1.0
+    replaces: foo.v1.0.0
+  - name: foo.v2.0.0
+    replaces: foo.v1.1.0
+`
+
+const testBundlesJSON = `{"schema":"olm.bundle","name":"foo.v1.0.0","package":"foo","image":"quay.io/example/foo:v1.0.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.0.0"}}]}
+{"schema":"olm.bundle","name":"foo.v1.1.0","package":"foo","image":"quay.io/example/foo:v1.1.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"1.1.0"}}]}
+{"schema":"olm.bundle","name":"foo.v2.0.0","package":"foo","image":"quay.io/example/foo:v2.0.0","properties":[{"type":"olm.package","value":{"packageName":"foo","version":"2.0.0"}}]}
+`
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func bundleNames(bundles []*catalogmetadata.Bundle) []string {
+	names := make([]string, 0, len(bundles))
+	for _, b := range bundles {
+		names = append(names, b.Name)
+	}
+	return names
+}
+
+func TestLoadCatalog(t *testing.T) {
+	wantBundles := []string{"foo.v1.0.0", "foo.v1.1.0", "foo.v2.0.0"}
+
+	t.Run("file", func(t *testing.T) {
+		// The extension of a catalog file given directly does not matter.
+		path := filepath.Join(t.TempDir(), "catalog")
+		writeFile(t, path, testPackageYAML+"---\n"+strings.ReplaceAll(testBundlesJSON, "}\n{", "}\n---\n{"))
+
+		bundles, err := loadCatalog("proposed", path)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, wantBundles, bundleNames(bundles))
+		for _, b := range bundles {
+			assert.Equal(t, "proposed", b.CatalogName)
+			require.Len(t, b.InChannels, 1)
+			assert.Equal(t, "stable", b.InChannels[0].Name)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "foo", "package.yaml"), testPackageYAML)
+		writeFile(t, filepath.Join(dir, "foo", "bundles", "bundles.json"), testBundlesJSON)
+		writeFile(t, filepath.Join(dir, "README.md"), "# Not part of the catalog: {")
+
+		bundles, err := loadCatalog("proposed", dir)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, wantBundles, bundleNames(bundles))
+		for _, b := range bundles {
+			require.Len(t, b.InChannels, 1)
+			assert.Equal(t, "stable", b.InChannels[0].Name)
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := loadCatalog("proposed", filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+	})
+}
+
+func TestResolveCases(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "catalog", "package.yaml"), testPackageYAML)
+	writeFile(t, filepath.Join(dir, "catalog", "bundles.json"), testBundlesJSON)
+	writeFile(t, filepath.Join(dir, "cases.yaml"), `
+- name: fresh-install
+  spec:
+    packageName: foo
+    channel: stable
+- name: upgrade-from-1.0
+  installedBundle: foo.v1.0.0
+  spec:
+    packageName: foo
+    channel: stable
+- name: missing-installed-bundle
+  installedBundle: foo.v0.9.0
+  spec:
+    packageName: foo
+- name: unknown-package
+  spec:
+    packageName: bar
+- name: configmap-bundle
+  spec:
+    configMapBundle:
+      name: foo
+`)
+
+	bundles, err := loadCatalog("proposed", filepath.Join(dir, "catalog"))
+	require.NoError(t, err)
+	cases, err := loadCases(filepath.Join(dir, "cases.yaml"))
+	require.NoError(t, err)
+
+	results := make(map[string]resolutionResult, len(cases))
+	for _, c := range cases {
+		results[c.Name] = resolveCase(c, bundles)
+	}
+	require.Len(t, results, 5)
+
+	assert.Equal(t, resolutionResult{
+		Name:   "fresh-install",
+		Bundle: &ocv1alpha1.BundleMetadata{Name: "foo.v2.0.0", Version: "2.0.0"},
+		Image:  "quay.io/example/foo:v2.0.0",
+	}, results["fresh-install"])
+	assert.Equal(t, resolutionResult{
+		Name:   "upgrade-from-1.0",
+		Bundle: &ocv1alpha1.BundleMetadata{Name: "foo.v1.1.0", Version: "1.1.0"},
+		Image:  "quay.io/example/foo:v1.1.0",
+	}, results["upgrade-from-1.0"])
+	assert.Equal(t, `installed bundle "foo.v0.9.0" not found in catalog`, results["missing-installed-bundle"].Error)
+	assert.NotEmpty(t, results["unknown-package"].Error)
+	assert.Nil(t, results["unknown-package"].Bundle)
+	assert.Equal(t, "spec.configMapBundle is not resolved from a catalog", results["configmap-bundle"].Error)
+}
+
+func TestUpgradeGraph(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "package.yaml"), testPackageYAML)
+	writeFile(t, filepath.Join(dir, "bundles.json"), testBundlesJSON)
+
+	bundles, err := loadCatalog("proposed", dir)
+	require.NoError(t, err)
+	graph, err := controllers.BuildUpgradeGraph("foo", bundles)
+	require.NoError(t, err)
+	assert.Equal(t, &controllers.UpgradeGraph{
+		Nodes: []controllers.UpgradeGraphNode{
+			{Name: "foo.v1.0.0", Version: "1.0.0", Catalogs: []string{"proposed"}},
+			{Name: "foo.v1.1.0", Version: "1.1.0", Catalogs: []string{"proposed"}},
+			{Name: "foo.v2.0.0", Version: "2.0.0", Catalogs: []string{"proposed"}},
+		},
+		Edges: []controllers.UpgradeGraphEdge{
+			{From: "foo.v1.0.0", To: "foo.v1.1.0"},
+			{From: "foo.v1.1.0", To: "foo.v2.0.0"},
+		},
+	}, graph)
+}
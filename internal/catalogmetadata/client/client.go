@@ -3,12 +3,15 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
@@ -44,6 +47,7 @@ type Client struct {
 }
 
 func (c *Client) Bundles(ctx context.Context) ([]*catalogmetadata.Bundle, error) {
+	l := log.FromContext(ctx)
 	var allBundles []*catalogmetadata.Bundle
 
 	var catalogList catalogd.CatalogList
@@ -55,51 +59,32 @@ func (c *Client) Bundles(ctx context.Context) ([]*catalogmetadata.Bundle, error)
 		if !meta.IsStatusConditionPresentAndEqual(catalog.Status.Conditions, catalogd.TypeUnpacked, metav1.ConditionTrue) {
 			continue
 		}
-		channels := []*catalogmetadata.Channel{}
-		bundles := []*catalogmetadata.Bundle{}
-		deprecations := []*catalogmetadata.Deprecation{}
-
 		rc, err := c.fetcher.FetchCatalogContents(ctx, catalog.DeepCopy())
 		if err != nil {
 			return nil, fmt.Errorf("error fetching catalog contents: %s", err)
 		}
 		defer rc.Close()
 
-		err = declcfg.WalkMetasReader(rc, func(meta *declcfg.Meta, err error) error {
-			if err != nil {
-				return fmt.Errorf("error was provided to the WalkMetasReaderFunc: %s", err)
-			}
-			switch meta.Schema {
-			case declcfg.SchemaChannel:
-				var content catalogmetadata.Channel
-				if err := json.Unmarshal(meta.Blob, &content); err != nil {
-					return fmt.Errorf("error unmarshalling channel from catalog metadata: %s", err)
-				}
-				channels = append(channels, &content)
-			case declcfg.SchemaBundle:
-				var content catalogmetadata.Bundle
-				if err := json.Unmarshal(meta.Blob, &content); err != nil {
-					return fmt.Errorf("error unmarshalling bundle from catalog metadata: %s", err)
-				}
-				bundles = append(bundles, &content)
-			case declcfg.SchemaDeprecation:
-				var content catalogmetadata.Deprecation
-				if err := json.Unmarshal(meta.Blob, &content); err != nil {
-					return fmt.Errorf("error unmarshalling deprecation from catalog metadata: %s", err)
-				}
-				deprecations = append(deprecations, &content)
-			}
-
-			return nil
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error processing response: %s", err)
-		}
-
-		bundles, err = PopulateExtraFields(catalog.Name, channels, bundles, deprecations)
+		bundles, parseErrs, err := ParseCatalog(catalog.Name, rc)
 		if err != nil {
 			return nil, err
 		}
+		var catalogRef string
+		if catalog.Spec.Source.Image != nil {
+			catalogRef = catalog.Spec.Source.Image.Ref
+		}
+		var catalogResolvedRef string
+		if catalog.Status.ResolvedSource != nil && catalog.Status.ResolvedSource.Image != nil {
+			catalogResolvedRef = catalog.Status.ResolvedSource.Image.ResolvedRef
+		}
+		for i := range bundles {
+			bundles[i].CatalogLabels = catalog.Labels
+			bundles[i].CatalogRef = catalogRef
+			bundles[i].CatalogResolvedRef = catalogResolvedRef
+		}
+		if len(parseErrs) > 0 {
+			l.Info("skipped invalid catalog entries", "catalog", catalog.Name, "count", len(parseErrs), "sample", parseErrs[0].Error())
+		}
 
 		allBundles = append(allBundles, bundles...)
 	}
@@ -107,7 +92,69 @@ func (c *Client) Bundles(ctx context.Context) ([]*catalogmetadata.Bundle, error)
 	return allBundles, nil
 }
 
-func PopulateExtraFields(catalogName string, channels []*catalogmetadata.Channel, bundles []*catalogmetadata.Bundle, deprecations []*catalogmetadata.Deprecation) ([]*catalogmetadata.Bundle, error) {
+// ParseCatalog reads file-based catalog contents and returns the bundles they contain,
+// associated with the named catalog and with their channels and deprecations.
+// Entries that can not be parsed are skipped and reported in the returned errors.
+// The final error is only non-nil if the contents can not be read at all.
+func ParseCatalog(catalogName string, r io.Reader) ([]*catalogmetadata.Bundle, []error, error) {
+	channels := []*catalogmetadata.Channel{}
+	bundles := []*catalogmetadata.Bundle{}
+	deprecations := []*catalogmetadata.Deprecation{}
+
+	// Entries that can not be parsed are skipped rather than failing the
+	// whole catalog, so that one malformed entry does not make every other
+	// package in the catalog unavailable for resolution.
+	var parseErrs []error
+	dec := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var blob json.RawMessage
+		if err := dec.Decode(&blob); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// The remainder of the stream can not be read reliably.
+			return nil, nil, fmt.Errorf("error processing response: %s", err)
+		}
+
+		var meta declcfg.Meta
+		if err := json.Unmarshal(blob, &meta); err != nil {
+			parseErrs = append(parseErrs, fmt.Errorf("error unmarshalling catalog metadata: %s", err))
+			continue
+		}
+		switch meta.Schema {
+		case declcfg.SchemaChannel:
+			var content catalogmetadata.Channel
+			if err := json.Unmarshal(meta.Blob, &content); err != nil {
+				parseErrs = append(parseErrs, fmt.Errorf("error unmarshalling channel %q from catalog metadata: %s", meta.Name, err))
+				continue
+			}
+			channels = append(channels, &content)
+		case declcfg.SchemaBundle:
+			var content catalogmetadata.Bundle
+			if err := json.Unmarshal(meta.Blob, &content); err != nil {
+				parseErrs = append(parseErrs, fmt.Errorf("error unmarshalling bundle %q from catalog metadata: %s", meta.Name, err))
+				continue
+			}
+			bundles = append(bundles, &content)
+		case declcfg.SchemaDeprecation:
+			var content catalogmetadata.Deprecation
+			if err := json.Unmarshal(meta.Blob, &content); err != nil {
+				parseErrs = append(parseErrs, fmt.Errorf("error unmarshalling deprecation for package %q from catalog metadata: %s", meta.Package, err))
+				continue
+			}
+			deprecations = append(deprecations, &content)
+		}
+	}
+
+	bundles, populateErrs := PopulateExtraFields(catalogName, channels, bundles, deprecations)
+	return bundles, append(parseErrs, populateErrs...), nil
+}
+
+// PopulateExtraFields associates bundles with the catalog, channels and deprecations they belong to.
+// Channel entries referencing bundles that are not present in the catalog are skipped
+// and reported in the returned errors.
+func PopulateExtraFields(catalogName string, channels []*catalogmetadata.Channel, bundles []*catalogmetadata.Bundle, deprecations []*catalogmetadata.Deprecation) ([]*catalogmetadata.Bundle, []error) {
+	var errs []error
 	bundlesMap := map[string]*catalogmetadata.Bundle{}
 	for i := range bundles {
 		bundleKey := fmt.Sprintf("%s-%s", bundles[i].Package, bundles[i].Name)
@@ -121,7 +168,8 @@ func PopulateExtraFields(catalogName string, channels []*catalogmetadata.Channel
 			bundleKey := fmt.Sprintf("%s-%s", ch.Package, chEntry.Name)
 			bundle, ok := bundlesMap[bundleKey]
 			if !ok {
-				return nil, fmt.Errorf("bundle %q not found in catalog %q (package %q, channel %q)", chEntry.Name, catalogName, ch.Package, ch.Name)
+				errs = append(errs, fmt.Errorf("bundle %q not found in catalog %q (package %q, channel %q)", chEntry.Name, catalogName, ch.Package, ch.Name))
+				continue
 			}
 
 			bundle.InChannels = append(bundle.InChannels, ch)
@@ -158,5 +206,5 @@ func PopulateExtraFields(catalogName string, channels []*catalogmetadata.Channel
 		}
 	}
 
-	return bundles, nil
+	return bundles, errs
 }
@@ -45,7 +45,7 @@ func TestClient(t *testing.T) {
 			{
 				name: "channel has a ref to a missing bundle",
 				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
-					objs, _, catalogContentMap := defaultFakeCatalog()
+					objs, bundles, catalogContentMap := defaultFakeCatalog()
 
 					catalogContentMap["catalog-1"] = append(catalogContentMap["catalog-1"], []byte(`{
 								"schema": "olm.channel",
@@ -58,47 +58,70 @@ func TestClient(t *testing.T) {
 								]
 							}`)...)
 
-					return objs, nil, catalogContentMap
+					return objs, bundles, catalogContentMap
 				},
-				wantErr: `bundle "fake1.v9.9.9" not found in catalog "catalog-1" (package "fake1", channel "channel-with-missing-bundle")`,
 				fetcher: &MockFetcher{},
 			},
 			{
-				name: "invalid meta",
+				name: "invalid meta is skipped",
 				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
-					objs, _, catalogContentMap := defaultFakeCatalog()
+					objs, bundles, catalogContentMap := defaultFakeCatalog()
 
 					catalogContentMap["catalog-1"] = append(catalogContentMap["catalog-1"], []byte(`{"schema": "olm.bundle", "name":123123123}`)...)
 
-					return objs, nil, catalogContentMap
+					return objs, bundles, catalogContentMap
 				},
-				wantErr: `error processing response: error was provided to the WalkMetasReaderFunc: expected value for key "name" to be a string, got %!t(float64=1.23123123e+08): 1.23123123e+08`,
 				fetcher: &MockFetcher{},
 			},
 			{
-				name: "invalid bundle",
+				name: "invalid bundle is skipped",
 				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
-					objs, _, catalogContentMap := defaultFakeCatalog()
+					objs, bundles, catalogContentMap := defaultFakeCatalog()
 
 					catalogContentMap["catalog-1"] = append(catalogContentMap["catalog-1"],
 						[]byte(`{"schema": "olm.bundle", "name":"foo", "package":"bar", "image":123123123}`)...)
 
-					return objs, nil, catalogContentMap
+					return objs, bundles, catalogContentMap
 				},
-				wantErr: "error processing response: error unmarshalling bundle from catalog metadata: json: cannot unmarshal number into Go struct field Bundle.image of type string",
 				fetcher: &MockFetcher{},
 			},
 			{
-				name: "invalid channel",
+				name: "invalid channel is skipped",
 				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
-					objs, _, catalogContentMap := defaultFakeCatalog()
+					objs, bundles, catalogContentMap := defaultFakeCatalog()
 
 					catalogContentMap["catalog-1"] = append(catalogContentMap["catalog-1"],
 						[]byte(`{"schema": "olm.channel", "name":"foo", "package":"bar", "entries":[{"name":123123123}]}`)...)
 
+					return objs, bundles, catalogContentMap
+				},
+				fetcher: &MockFetcher{},
+			},
+			{
+				name: "several invalid entries among valid ones",
+				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
+					objs, bundles, catalogContentMap := defaultFakeCatalog()
+
+					catalogContentMap["catalog-1"] = append([]byte(strings.Join([]string{
+						`{"schema": "olm.bundle", "name":123123123}`,
+						`{"schema": "olm.bundle", "name":"foo", "package":"bar", "image":123123123}`,
+						`{"schema": "olm.deprecations", "package":"fake1", "entries":123}`,
+					}, "\n")), catalogContentMap["catalog-1"]...)
+
+					return objs, bundles, catalogContentMap
+				},
+				fetcher: &MockFetcher{},
+			},
+			{
+				name: "unreadable catalog contents",
+				fakeCatalog: func() ([]client.Object, []*catalogmetadata.Bundle, map[string][]byte) {
+					objs, _, catalogContentMap := defaultFakeCatalog()
+
+					catalogContentMap["catalog-1"] = append(catalogContentMap["catalog-1"], []byte(`{"schema": "olm.bundle", "name":`)...)
+
 					return objs, nil, catalogContentMap
 				},
-				wantErr: "error processing response: error unmarshalling channel from catalog metadata: json: cannot unmarshal number into Go struct field ChannelEntry.entries.name of type string",
+				wantErr: "error processing response: unexpected EOF",
 				fetcher: &MockFetcher{},
 			},
 			{
@@ -224,6 +247,12 @@ func defaultFakeCatalog() ([]client.Object, []*catalogmetadata.Bundle, map[strin
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "catalog-1",
 			},
+			Spec: catalogd.CatalogSpec{
+				Source: catalogd.CatalogSource{
+					Type:  catalogd.SourceTypeImage,
+					Image: &catalogd.ImageSource{Ref: "quay.io/example/catalog-1@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+				},
+			},
 			Status: catalogd.CatalogStatus{
 				Conditions: []metav1.Condition{
 					{
@@ -238,7 +267,20 @@ func defaultFakeCatalog() ([]client.Object, []*catalogmetadata.Bundle, map[strin
 			ObjectMeta: metav1.ObjectMeta{
 				Name: "catalog-2",
 			},
+			Spec: catalogd.CatalogSpec{
+				Source: catalogd.CatalogSource{
+					Type:  catalogd.SourceTypeImage,
+					Image: &catalogd.ImageSource{Ref: "quay.io/example/catalog-2:latest"},
+				},
+			},
 			Status: catalogd.CatalogStatus{
+				ResolvedSource: &catalogd.ResolvedCatalogSource{
+					Type: catalogd.SourceTypeImage,
+					Image: &catalogd.ResolvedImageSource{
+						Ref:         "quay.io/example/catalog-2:latest",
+						ResolvedRef: "quay.io/example/catalog-2@sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210",
+					},
+				},
 				Conditions: []metav1.Condition{
 					{
 						Type:   catalogd.TypeUnpacked,
@@ -253,6 +295,7 @@ func defaultFakeCatalog() ([]client.Object, []*catalogmetadata.Bundle, map[strin
 	expectedBundles := []*catalogmetadata.Bundle{
 		{
 			CatalogName: "catalog-1",
+			CatalogRef:  "quay.io/example/catalog-1@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 			Bundle: declcfg.Bundle{
 				Schema:  declcfg.SchemaBundle,
 				Name:    "fake1.v1.0.0",
@@ -293,7 +336,9 @@ func defaultFakeCatalog() ([]client.Object, []*catalogmetadata.Bundle, map[strin
 			},
 		},
 		{
-			CatalogName: "catalog-2",
+			CatalogName:        "catalog-2",
+			CatalogRef:         "quay.io/example/catalog-2:latest",
+			CatalogResolvedRef: "quay.io/example/catalog-2@sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210",
 			Bundle: declcfg.Bundle{
 				Schema:  declcfg.SchemaBundle,
 				Name:    "fake1.v1.0.0",
@@ -1,10 +1,18 @@
 package filter
 
 import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
 	mmsemver "github.com/Masterminds/semver/v3"
 	bsemver "github.com/blang/semver/v4"
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
 
 	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
 )
@@ -54,12 +62,145 @@ func InChannel(channelName string) Predicate[catalogmetadata.Bundle] {
 	}
 }
 
+// ProvidingAPI returns a predicate that keeps bundles which
+// advertise, via olm.gvk properties, an API with the given group and kind.
+func ProvidingAPI(group, kind string) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		gvks, err := bundle.ProvidedGVKs()
+		if err != nil {
+			return false
+		}
+		for _, gvk := range gvks {
+			if gvk.Group == group && gvk.Kind == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ProvidingGVK returns a predicate that keeps bundles which advertise,
+// via olm.gvk properties, the given API group, version and kind.
+func ProvidingGVK(gvk property.GVK) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		gvks, err := bundle.ProvidedGVKs()
+		if err != nil {
+			return false
+		}
+		for _, provided := range gvks {
+			if provided == gvk {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// CompatibleWithClusterVersions returns a predicate that keeps bundles whose declared
+// cluster version range includes every one of the given cluster versions. Bundles that
+// declare no range are compatible with any cluster version, and bundles whose range
+// cannot be parsed are compatible with none.
+func CompatibleWithClusterVersions(clusterVersions ...bsemver.Version) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		rangeValue, err := bundle.ClusterVersionRange()
+		if err != nil {
+			return false
+		}
+		if rangeValue == "" {
+			return true
+		}
+		compatible, err := bsemver.ParseRange(rangeValue)
+		if err != nil {
+			return false
+		}
+		for _, v := range clusterVersions {
+			if !compatible(v) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// CompatibleWithKubernetesVersion returns a predicate that keeps bundles whose declared
+// minimum Kubernetes version is at most the given version. Pre-release and build
+// metadata of the given version, which distributions append to their releases, are
+// ignored. Bundles that declare no minimum are compatible with any version, and
+// bundles whose minimum cannot be parsed are compatible with none.
+func CompatibleWithKubernetesVersion(kubernetesVersion bsemver.Version) Predicate[catalogmetadata.Bundle] {
+	release := bsemver.Version{Major: kubernetesVersion.Major, Minor: kubernetesVersion.Minor, Patch: kubernetesVersion.Patch}
+	return func(bundle *catalogmetadata.Bundle) bool {
+		minValue, err := bundle.MinKubeVersion()
+		if err != nil {
+			return false
+		}
+		if minValue == "" {
+			return true
+		}
+		minVersion, err := bsemver.ParseTolerant(minValue)
+		if err != nil {
+			return false
+		}
+		return release.GTE(minVersion)
+	}
+}
+
+// SupportingPlatforms returns a predicate that keeps bundles that support every one
+// of the given platforms. Bundles that declare no architectures support any
+// architecture, bundles that declare no operating systems support any operating
+// system, and bundles whose CSV metadata cannot be read support no platform.
+func SupportingPlatforms(platforms ...catalogmetadata.Platform) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		archs, err := bundle.SupportedArchitectures()
+		if err != nil {
+			return len(platforms) == 0
+		}
+		oses, err := bundle.SupportedOperatingSystems()
+		if err != nil {
+			return len(platforms) == 0
+		}
+		for _, p := range platforms {
+			if len(archs) > 0 && !slices.Contains(archs, p.Architecture) {
+				return false
+			}
+			if len(oses) > 0 && !slices.Contains(oses, p.OS) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// InCatalogsMatching returns a predicate that keeps bundles read from
+// catalogs whose labels match the given selector.
+func InCatalogsMatching(selector labels.Selector) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		return selector.Matches(labels.Set(bundle.CatalogLabels))
+	}
+}
+
+// FromPinnedCatalog returns a predicate that keeps bundles read from catalogs
+// whose image is referenced by digest rather than by a mutable tag.
+func FromPinnedCatalog() Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		return strings.Contains(bundle.CatalogRef, "@")
+	}
+}
+
 func WithBundleImage(bundleImage string) Predicate[catalogmetadata.Bundle] {
 	return func(bundle *catalogmetadata.Bundle) bool {
 		return bundle.Image == bundleImage
 	}
 }
 
+// WithBundleImageDigest returns a predicate that selects bundles whose image
+// is referenced by the given digest, e.g. "sha256:...".
+func WithBundleImageDigest(digest string) Predicate[catalogmetadata.Bundle] {
+	return func(bundle *catalogmetadata.Bundle) bool {
+		return strings.HasSuffix(bundle.Image, "@"+digest)
+	}
+}
+
 func LegacySuccessor(installedBundle *catalogmetadata.Bundle) Predicate[catalogmetadata.Bundle] {
 	isSuccessor := func(candidateBundleEntry declcfg.ChannelEntry) bool {
 		if candidateBundleEntry.Replaces == installedBundle.Name {
@@ -97,3 +238,57 @@ func WithDeprecation(deprecated bool) Predicate[catalogmetadata.Bundle] {
 		return bundle.HasDeprecation() == deprecated
 	}
 }
+
+// MatchingSelectorExpression compiles the CEL expression and returns a predicate that
+// keeps bundles for which it evaluates to true. The expression can refer to the
+// bundle's name and version, and to properties, which maps each property type of the
+// bundle to the list of values of its properties of that type. Bundles for which the
+// expression fails to evaluate, or evaluates to a value that is not a boolean, are not
+// kept. It returns an error if the expression
+// does not compile to a boolean.
+func MatchingSelectorExpression(expression string) (Predicate[catalogmetadata.Bundle], error) {
+	env, err := cel.NewEnv(
+		cel.Variable("name", cel.StringType),
+		cel.Variable("version", cel.StringType),
+		cel.Variable("properties", cel.MapType(cel.StringType, cel.ListType(cel.DynType))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression evaluates to %s, not bool", t)
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(bundle *catalogmetadata.Bundle) bool {
+		var version string
+		if v, err := bundle.Version(); err == nil {
+			version = v.String()
+		}
+		properties := map[string][]interface{}{}
+		for _, p := range bundle.Properties {
+			var value interface{}
+			if err := json.Unmarshal(p.Value, &value); err != nil {
+				continue
+			}
+			properties[p.Type] = append(properties[p.Type], value)
+		}
+		out, _, err := program.Eval(map[string]interface{}{
+			"name":       bundle.Name,
+			"version":    version,
+			"properties": properties,
+		})
+		if err != nil {
+			return false
+		}
+		matched, ok := out.Value().(bool)
+		return ok && matched
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	mmsemver "github.com/Masterminds/semver/v3"
 	bsemver "github.com/blang/semver/v4"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/property"
@@ -115,6 +116,187 @@ func TestInChannel(t *testing.T) {
 	assert.False(t, f(b3))
 }
 
+func TestProvidingAPI(t *testing.T) {
+	b1 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`{"group": "example.com", "kind": "Widget", "version": "v1"}`),
+			},
+		},
+	}}
+	b2 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`{"group": "example.com", "kind": "Gadget", "version": "v1"}`),
+			},
+		},
+	}}
+	b3 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`broken`),
+			},
+		},
+	}}
+	b4 := &catalogmetadata.Bundle{}
+
+	f := filter.ProvidingAPI("example.com", "Widget")
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+	assert.False(t, f(b4))
+}
+
+func TestProvidingGVK(t *testing.T) {
+	b1 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`{"group": "example.com", "kind": "Widget", "version": "v1"}`),
+			},
+		},
+	}}
+	b2 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`{"group": "example.com", "kind": "Widget", "version": "v2"}`),
+			},
+		},
+	}}
+	b3 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+		Properties: []property.Property{
+			{
+				Type:  property.TypeGVK,
+				Value: json.RawMessage(`broken`),
+			},
+		},
+	}}
+	b4 := &catalogmetadata.Bundle{}
+
+	f := filter.ProvidingGVK(property.GVK{Group: "example.com", Kind: "Widget", Version: "v1"})
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+	assert.False(t, f(b4))
+}
+
+func TestCompatibleWithClusterVersions(t *testing.T) {
+	withRange := func(value string) *catalogmetadata.Bundle {
+		return &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+			Properties: []property.Property{
+				{
+					Type:  catalogmetadata.PropertyClusterVersionRange,
+					Value: json.RawMessage(value),
+				},
+			},
+		}}
+	}
+	b1 := withRange(`">=4.14.0 <4.17.0"`)
+	b2 := withRange(`">=4.14.0 <4.16.0"`)
+	b3 := withRange(`"not a range"`)
+	b4 := withRange(`broken`)
+	b5 := &catalogmetadata.Bundle{}
+
+	f := filter.CompatibleWithClusterVersions(bsemver.MustParse("4.15.2"), bsemver.MustParse("4.16.0"))
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+	assert.False(t, f(b4))
+	assert.True(t, f(b5))
+}
+
+func TestCompatibleWithKubernetesVersion(t *testing.T) {
+	withMinKubeVersion := func(value string) *catalogmetadata.Bundle {
+		return &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+			Properties: []property.Property{
+				{
+					Type:  property.TypeCSVMetadata,
+					Value: json.RawMessage(`{"minKubeVersion":` + value + `}`),
+				},
+			},
+		}}
+	}
+	b1 := withMinKubeVersion(`"1.28.0"`)
+	b2 := withMinKubeVersion(`"v1.29"`)
+	b3 := withMinKubeVersion(`"1.29.4"`)
+	b4 := withMinKubeVersion(`"not a version"`)
+	b5 := withMinKubeVersion(`broken`)
+	b6 := withMinKubeVersion(`""`)
+	b7 := &catalogmetadata.Bundle{}
+
+	f := filter.CompatibleWithKubernetesVersion(bsemver.MustParse("1.29.3-gke.100"))
+
+	assert.True(t, f(b1))
+	assert.True(t, f(b2))
+	assert.False(t, f(b3))
+	assert.False(t, f(b4))
+	assert.False(t, f(b5))
+	assert.True(t, f(b6))
+	assert.True(t, f(b7))
+}
+
+func TestSupportingPlatforms(t *testing.T) {
+	withLabels := func(value string) *catalogmetadata.Bundle {
+		return &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+			Properties: []property.Property{
+				{
+					Type:  property.TypeCSVMetadata,
+					Value: json.RawMessage(`{"labels":` + value + `}`),
+				},
+			},
+		}}
+	}
+	b1 := withLabels(`{"operatorframework.io/arch.amd64":"supported","operatorframework.io/arch.arm64":"supported","operatorframework.io/os.linux":"supported"}`)
+	b2 := withLabels(`{"operatorframework.io/arch.amd64":"supported"}`)
+	b3 := withLabels(`{"operatorframework.io/os.windows":"supported"}`)
+	b4 := withLabels(`{"app":"fake"}`)
+	b5 := withLabels(`broken`)
+	b6 := &catalogmetadata.Bundle{}
+
+	f := filter.SupportingPlatforms(
+		catalogmetadata.Platform{OS: "linux", Architecture: "amd64"},
+		catalogmetadata.Platform{OS: "linux", Architecture: "arm64"},
+	)
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+	assert.True(t, f(b4))
+	assert.False(t, f(b5))
+	assert.True(t, f(b6))
+}
+
+func TestInCatalogsMatching(t *testing.T) {
+	b1 := &catalogmetadata.Bundle{CatalogLabels: map[string]string{"tier": "certified"}}
+	b2 := &catalogmetadata.Bundle{CatalogLabels: map[string]string{"tier": "community"}}
+	b3 := &catalogmetadata.Bundle{}
+
+	f := filter.InCatalogsMatching(labels.SelectorFromSet(labels.Set{"tier": "certified"}))
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+}
+
+func TestFromPinnedCatalog(t *testing.T) {
+	b1 := &catalogmetadata.Bundle{CatalogRef: "quay.io/example/catalog@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}
+	b2 := &catalogmetadata.Bundle{CatalogRef: "quay.io/example/catalog:latest"}
+	b3 := &catalogmetadata.Bundle{}
+
+	f := filter.FromPinnedCatalog()
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+}
+
 func TestWithBundleImage(t *testing.T) {
 	b1 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{Image: "fake-image-uri-1"}}
 	b2 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{Image: "fake-image-uri-2"}}
@@ -127,6 +309,20 @@ func TestWithBundleImage(t *testing.T) {
 	assert.False(t, f(b3))
 }
 
+func TestWithBundleImageDigest(t *testing.T) {
+	b1 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{Image: "quay.io/example/foo@sha256:1234"}}
+	b2 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{Image: "quay.io/example/foo@sha256:5678"}}
+	b3 := &catalogmetadata.Bundle{Bundle: declcfg.Bundle{Image: "quay.io/example/foo:sha256"}}
+	b4 := &catalogmetadata.Bundle{}
+
+	f := filter.WithBundleImageDigest("sha256:1234")
+
+	assert.True(t, f(b1))
+	assert.False(t, f(b2))
+	assert.False(t, f(b3))
+	assert.False(t, f(b4))
+}
+
 func TestLegacySuccessor(t *testing.T) {
 	fakeChannel := &catalogmetadata.Channel{
 		Channel: declcfg.Channel{
@@ -201,3 +397,39 @@ func TestWithDeprecation(t *testing.T) {
 	assert.True(t, f(b1))
 	assert.False(t, f(b2))
 }
+
+func TestMatchingSelectorExpression(t *testing.T) {
+	bundle := func(name, version string, props ...property.Property) *catalogmetadata.Bundle {
+		return &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+			Name: name,
+			Properties: append([]property.Property{
+				{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"package1","version":"` + version + `"}`)},
+			}, props...),
+		}}
+	}
+	fips := bundle("package1.v1.0.0", "1.0.0", property.Property{Type: "features.fips", Value: json.RawMessage(`true`)})
+	nonFIPS := bundle("package1.v1.1.0", "1.1.0", property.Property{Type: "features.fips", Value: json.RawMessage(`false`)})
+	undeclared := bundle("package1.v2.0.0", "2.0.0")
+
+	f, err := filter.MatchingSelectorExpression(`properties['features.fips'].exists(v, v == true)`)
+	assert.NoError(t, err)
+	assert.True(t, f(fips))
+	assert.False(t, f(nonFIPS))
+	assert.False(t, f(undeclared))
+
+	f, err = filter.MatchingSelectorExpression(`version.startsWith("1.") && name != "package1.v1.1.0"`)
+	assert.NoError(t, err)
+	assert.True(t, f(fips))
+	assert.False(t, f(nonFIPS))
+	assert.False(t, f(undeclared))
+
+	f, err = filter.MatchingSelectorExpression(`properties['olm.package'][0].packageName == "package1"`)
+	assert.NoError(t, err)
+	assert.True(t, f(undeclared))
+
+	_, err = filter.MatchingSelectorExpression(`name.size()`)
+	assert.EqualError(t, err, "expression evaluates to int, not bool")
+
+	_, err = filter.MatchingSelectorExpression(`unknown == "x"`)
+	assert.Error(t, err)
+}
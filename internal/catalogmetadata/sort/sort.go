@@ -19,16 +19,17 @@ func ByVersion(b1, b2 *catalogmetadata.Bundle) bool {
 }
 
 // ByDeprecation is a sort "less" function that orders bundles
-// that are deprecated lower than ones without deprecations
+// that are deprecated, or only in deprecated channels, lower than
+// ones without deprecations
 func ByDeprecated(b1, b2 *catalogmetadata.Bundle) bool {
 	b1Val := 1
 	b2Val := 1
 
-	if b1.IsDeprecated() {
+	if b1.IsDeprecated() || b1.InDeprecatedChannels() {
 		b1Val = b1Val - 1
 	}
 
-	if b2.IsDeprecated() {
+	if b2.IsDeprecated() || b2.InDeprecatedChannels() {
 		b2Val = b2Val - 1
 	}
 
@@ -159,4 +159,24 @@ func TestByDeprecated(t *testing.T) {
 	require.Len(t, toSort, 2)
 	assert.Equal(t, b1, toSort[0])
 	assert.Equal(t, b2, toSort[1])
+
+	b1.Deprecations = nil
+	b2.Deprecations = []declcfg.DeprecationEntry{
+		{
+			Reference: declcfg.PackageScopedReference{
+				Schema: "olm.channel",
+				Name:   "badchannel",
+			},
+		},
+	}
+	b2.InChannels = []*catalogmetadata.Channel{{Channel: declcfg.Channel{Name: "badchannel"}}}
+
+	toSort = []*catalogmetadata.Bundle{b2, b1}
+	sort.SliceStable(toSort, func(i, j int) bool {
+		return catalogsort.ByDeprecated(toSort[i], toSort[j])
+	})
+	// b2 is only in a deprecated channel, b2 should be preferred less
+	require.Len(t, toSort, 2)
+	assert.Equal(t, b1, toSort[0])
+	assert.Equal(t, b2, toSort[1])
 }
@@ -3,6 +3,9 @@ package catalogmetadata
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 
 	bsemver "github.com/blang/semver/v4"
@@ -15,6 +18,21 @@ const (
 	MediaTypePlain          = "plain+v0"
 	MediaTypeRegistry       = "registry+v1"
 	PropertyBundleMediaType = "olm.bundle.mediatype"
+	// PropertyClusterVersionRange is the bundle property holding the semver range of
+	// cluster versions the bundle is compatible with, e.g. ">=4.14.0 <4.17.0".
+	PropertyClusterVersionRange = "olm.clusterVersionRange"
+
+	// LabelCatalogPriority is the catalog label holding the catalog's priority.
+	// Bundles from higher priority catalogs override bundles with the same
+	// name from lower priority catalogs. Catalogs without it have priority 0.
+	LabelCatalogPriority = "olm.operatorframework.io/priority"
+
+	// LabelArchitecturePrefix and LabelOperatingSystemPrefix prefix the CSV labels,
+	// such as operatorframework.io/arch.arm64, with which a bundle declares the
+	// platforms its images support. Their value is LabelValueSupported.
+	LabelArchitecturePrefix    = "operatorframework.io/arch."
+	LabelOperatingSystemPrefix = "operatorframework.io/os."
+	LabelValueSupported        = "supported"
 )
 
 type Schemas interface {
@@ -38,11 +56,27 @@ type PackageRequired struct {
 	SemverRange bsemver.Range `json:"-"`
 }
 
+// Platform is an operating system and architecture, such as those of a node.
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Architecture
+}
+
 type Bundle struct {
 	declcfg.Bundle
-	CatalogName  string
-	InChannels   []*Channel
-	Deprecations []declcfg.DeprecationEntry
+	CatalogName string
+	// CatalogLabels are the labels of the catalog the bundle was read from.
+	CatalogLabels map[string]string
+	// CatalogRef is the image reference the catalog the bundle was read from is sourced from.
+	CatalogRef string
+	// CatalogResolvedRef is the digest reference of the catalog image the bundle was read from.
+	CatalogResolvedRef string
+	InChannels         []*Channel
+	Deprecations       []declcfg.DeprecationEntry
 
 	mu sync.RWMutex
 	// these properties are lazy loaded as they are requested
@@ -50,7 +84,11 @@ type Bundle struct {
 	bundlePackage    *property.Package
 	semVersion       *bsemver.Version
 	requiredPackages []PackageRequired
+	providedGVKs     []property.GVK
+	requiredGVKs     []property.GVKRequired
 	mediaType        *string
+	clusterVersions  *string
+	csvMetadata      *property.CSVMetadata
 }
 
 func (b *Bundle) Version() (*bsemver.Version, error) {
@@ -67,6 +105,20 @@ func (b *Bundle) RequiredPackages() ([]PackageRequired, error) {
 	return b.requiredPackages, nil
 }
 
+func (b *Bundle) ProvidedGVKs() ([]property.GVK, error) {
+	if err := b.loadProvidedGVKs(); err != nil {
+		return nil, err
+	}
+	return b.providedGVKs, nil
+}
+
+func (b *Bundle) RequiredGVKs() ([]property.GVKRequired, error) {
+	if err := b.loadRequiredGVKs(); err != nil {
+		return nil, err
+	}
+	return b.requiredGVKs, nil
+}
+
 func (b *Bundle) MediaType() (string, error) {
 	if err := b.loadMediaType(); err != nil {
 		return "", err
@@ -75,6 +127,42 @@ func (b *Bundle) MediaType() (string, error) {
 	return *b.mediaType, nil
 }
 
+// ClusterVersionRange returns the range of cluster versions the bundle declares it
+// is compatible with, or an empty string if it declares none.
+func (b *Bundle) ClusterVersionRange() (string, error) {
+	if err := b.loadClusterVersionRange(); err != nil {
+		return "", err
+	}
+	return *b.clusterVersions, nil
+}
+
+// MinKubeVersion returns the minimum Kubernetes version the bundle declares in its
+// olm.csv.metadata property, or an empty string if it declares none.
+func (b *Bundle) MinKubeVersion() (string, error) {
+	if err := b.loadCSVMetadata(); err != nil {
+		return "", err
+	}
+	return b.csvMetadata.MinKubeVersion, nil
+}
+
+// SupportedArchitectures returns the architectures the bundle declares support for
+// with CSV labels, sorted, or nil if it declares none.
+func (b *Bundle) SupportedArchitectures() ([]string, error) {
+	if err := b.loadCSVMetadata(); err != nil {
+		return nil, err
+	}
+	return supportedLabelSuffixes(b.csvMetadata.Labels, LabelArchitecturePrefix), nil
+}
+
+// SupportedOperatingSystems returns the operating systems the bundle declares support
+// for with CSV labels, sorted, or nil if it declares none.
+func (b *Bundle) SupportedOperatingSystems() ([]string, error) {
+	if err := b.loadCSVMetadata(); err != nil {
+		return nil, err
+	}
+	return supportedLabelSuffixes(b.csvMetadata.Labels, LabelOperatingSystemPrefix), nil
+}
+
 func (b *Bundle) loadPackage() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -120,6 +208,32 @@ func (b *Bundle) loadRequiredPackages() error {
 	return nil
 }
 
+func (b *Bundle) loadProvidedGVKs() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.providedGVKs == nil {
+		providedGVKs, err := loadFromProps[property.GVK](b, property.TypeGVK, false)
+		if err != nil {
+			return fmt.Errorf("error determining bundle provided GVKs for bundle %q: %s", b.Name, err)
+		}
+		b.providedGVKs = providedGVKs
+	}
+	return nil
+}
+
+func (b *Bundle) loadRequiredGVKs() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.requiredGVKs == nil {
+		requiredGVKs, err := loadFromProps[property.GVKRequired](b, property.TypeGVKRequired, false)
+		if err != nil {
+			return fmt.Errorf("error determining bundle required GVKs for bundle %q: %s", b.Name, err)
+		}
+		b.requiredGVKs = requiredGVKs
+	}
+	return nil
+}
+
 func (b *Bundle) loadMediaType() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -133,6 +247,43 @@ func (b *Bundle) loadMediaType() error {
 	return nil
 }
 
+func (b *Bundle) loadClusterVersionRange() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.clusterVersions == nil {
+		clusterVersions, err := loadOneFromProps[string](b, PropertyClusterVersionRange, false)
+		if err != nil {
+			return fmt.Errorf("error determining cluster version range for bundle %q: %s", b.Name, err)
+		}
+		b.clusterVersions = &clusterVersions
+	}
+	return nil
+}
+
+func (b *Bundle) loadCSVMetadata() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.csvMetadata == nil {
+		metadata, err := loadOneFromProps[property.CSVMetadata](b, property.TypeCSVMetadata, false)
+		if err != nil {
+			return fmt.Errorf("error determining CSV metadata for bundle %q: %s", b.Name, err)
+		}
+		b.csvMetadata = &metadata
+	}
+	return nil
+}
+
+func supportedLabelSuffixes(labels map[string]string, prefix string) []string {
+	var suffixes []string
+	for k, v := range labels {
+		if suffix, ok := strings.CutPrefix(k, prefix); ok && suffix != "" && v == LabelValueSupported {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	sort.Strings(suffixes)
+	return suffixes
+}
+
 func (b *Bundle) propertiesByType(propType string) []*property.Property {
 	if b.propertiesMap == nil {
 		b.propertiesMap = make(map[string][]*property.Property)
@@ -174,6 +325,39 @@ func (b *Bundle) IsDeprecated() bool {
 	return false
 }
 
+// InDeprecatedChannels returns true if the bundle is in at
+// least one channel and every channel it is in has been deprecated,
+// so that it can only be selected via a deprecated channel.
+func (b *Bundle) InDeprecatedChannels() bool {
+	if len(b.InChannels) == 0 {
+		return false
+	}
+	for _, ch := range b.InChannels {
+		deprecated := false
+		for _, dep := range b.Deprecations {
+			if dep.Reference.Schema == declcfg.SchemaChannel && dep.Reference.Name == ch.Name {
+				deprecated = true
+				break
+			}
+		}
+		if !deprecated {
+			return false
+		}
+	}
+	return true
+}
+
+// CatalogPriority returns the priority of the catalog the bundle was read from,
+// as set by its LabelCatalogPriority label. It returns 0 when the label is
+// missing or is not an integer.
+func (b *Bundle) CatalogPriority() int {
+	priority, err := strconv.Atoi(b.CatalogLabels[LabelCatalogPriority])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
 func loadOneFromProps[T any](bundle *Bundle, propType string, required bool) (T, error) {
 	r, err := loadFromProps[T](bundle, propType, required)
 	if err != nil {
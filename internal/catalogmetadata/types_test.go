@@ -147,6 +147,123 @@ func TestBundleRequiredPackages(t *testing.T) {
 	}
 }
 
+func TestBundleProvidedGVKs(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		bundle           *catalogmetadata.Bundle
+		wantProvidedGVKs []property.GVK
+		wantErr          string
+	}{
+		{
+			name: "valid provided GVKs",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.v1",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeGVK,
+						Value: json.RawMessage(`{"group": "example.com", "kind": "Widget", "version": "v1"}`),
+					},
+					{
+						Type:  property.TypeGVK,
+						Value: json.RawMessage(`{"group": "example.com", "kind": "Gadget", "version": "v1alpha1"}`),
+					},
+				},
+			}},
+			wantProvidedGVKs: []property.GVK{
+				{Group: "example.com", Kind: "Widget", Version: "v1"},
+				{Group: "example.com", Kind: "Gadget", Version: "v1alpha1"},
+			},
+		},
+		{
+			name: "no provided GVKs",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.noGVKs",
+			}},
+			wantProvidedGVKs: nil,
+		},
+		{
+			name: "bad provided GVK",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.badGVK",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeGVK,
+						Value: json.RawMessage(`badGVKStructure`),
+					},
+				},
+			}},
+			wantProvidedGVKs: nil,
+			wantErr:          `error determining bundle provided GVKs for bundle "fake-bundle.badGVK": property "olm.gvk" with value "badGVKStructure" could not be parsed: invalid character 'b' looking for beginning of value`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gvks, err := tt.bundle.ProvidedGVKs()
+			assert.Equal(t, tt.wantProvidedGVKs, gvks)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBundleRequiredGVKs(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		bundle           *catalogmetadata.Bundle
+		wantRequiredGVKs []property.GVKRequired
+		wantErr          string
+	}{
+		{
+			name: "valid required GVKs",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.v1",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeGVKRequired,
+						Value: json.RawMessage(`{"group": "example.com", "kind": "Widget", "version": "v1"}`),
+					},
+				},
+			}},
+			wantRequiredGVKs: []property.GVKRequired{
+				{Group: "example.com", Kind: "Widget", Version: "v1"},
+			},
+		},
+		{
+			name: "no required GVKs",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.noGVKs",
+			}},
+			wantRequiredGVKs: nil,
+		},
+		{
+			name: "bad required GVK",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.badGVK",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeGVKRequired,
+						Value: json.RawMessage(`badGVKStructure`),
+					},
+				},
+			}},
+			wantRequiredGVKs: nil,
+			wantErr:          `error determining bundle required GVKs for bundle "fake-bundle.badGVK": property "olm.gvk.required" with value "badGVKStructure" could not be parsed: invalid character 'b' looking for beginning of value`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			gvks, err := tt.bundle.RequiredGVKs()
+			assert.Equal(t, tt.wantRequiredGVKs, gvks)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestBundleMediaType(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
@@ -202,6 +319,199 @@ func TestBundleMediaType(t *testing.T) {
 	}
 }
 
+func TestBundleClusterVersionRange(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		bundle    *catalogmetadata.Bundle
+		wantRange string
+		wantErr   string
+	}{
+		{
+			name: "valid cluster version range property",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.v1",
+				Properties: []property.Property{
+					{
+						Type:  catalogmetadata.PropertyClusterVersionRange,
+						Value: json.RawMessage(`">=4.14.0 <4.17.0"`),
+					},
+				},
+			}},
+			wantRange: ">=4.14.0 <4.17.0",
+		},
+		{
+			name: "no cluster version range provided",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name:       "fake-bundle.noRange",
+				Properties: []property.Property{},
+			}},
+			wantRange: "",
+		},
+		{
+			name: "malformed cluster version range",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.badRange",
+				Properties: []property.Property{
+					{
+						Type:  catalogmetadata.PropertyClusterVersionRange,
+						Value: json.RawMessage("4.14"),
+					},
+				},
+			}},
+			wantRange: "",
+			wantErr:   `error determining cluster version range for bundle "fake-bundle.badRange": property "olm.clusterVersionRange" with value "4.14" could not be parsed: json: cannot unmarshal number into Go value of type string`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterVersions, err := tt.bundle.ClusterVersionRange()
+			assert.Equal(t, tt.wantRange, clusterVersions)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBundleMinKubeVersion(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		bundle      *catalogmetadata.Bundle
+		wantVersion string
+		wantErr     string
+	}{
+		{
+			name: "csv metadata declaring a minimum Kubernetes version",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.v1",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"displayName":"Fake","minKubeVersion":"1.29.0"}`),
+					},
+				},
+			}},
+			wantVersion: "1.29.0",
+		},
+		{
+			name: "csv metadata without a minimum Kubernetes version",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.noMinKubeVersion",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"displayName":"Fake"}`),
+					},
+				},
+			}},
+			wantVersion: "",
+		},
+		{
+			name: "no csv metadata provided",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name:       "fake-bundle.noMetadata",
+				Properties: []property.Property{},
+			}},
+			wantVersion: "",
+		},
+		{
+			name: "malformed csv metadata",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.badMetadata",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"minKubeVersion":129}`),
+					},
+				},
+			}},
+			wantVersion: "",
+			wantErr:     `error determining CSV metadata for bundle "fake-bundle.badMetadata": property "olm.csv.metadata" with value "{\"minKubeVersion\":129}" could not be parsed: json: cannot unmarshal number into Go struct field CSVMetadata.minKubeVersion of type string`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			minKubeVersion, err := tt.bundle.MinKubeVersion()
+			assert.Equal(t, tt.wantVersion, minKubeVersion)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBundleSupportedPlatforms(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		bundle    *catalogmetadata.Bundle
+		wantArchs []string
+		wantOSes  []string
+		wantErr   string
+	}{
+		{
+			name: "csv labels declaring supported platforms",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.v1",
+				Properties: []property.Property{
+					{
+						Type: property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"labels":{
+							"operatorframework.io/arch.arm64":"supported",
+							"operatorframework.io/arch.amd64":"supported",
+							"operatorframework.io/arch.s390x":"unsupported",
+							"operatorframework.io/os.linux":"supported",
+							"app":"fake"
+						}}`),
+					},
+				},
+			}},
+			wantArchs: []string{"amd64", "arm64"},
+			wantOSes:  []string{"linux"},
+		},
+		{
+			name: "no platform labels",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.noLabels",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"labels":{"app":"fake"}}`),
+					},
+				},
+			}},
+		},
+		{
+			name: "malformed csv metadata",
+			bundle: &catalogmetadata.Bundle{Bundle: declcfg.Bundle{
+				Name: "fake-bundle.badMetadata",
+				Properties: []property.Property{
+					{
+						Type:  property.TypeCSVMetadata,
+						Value: json.RawMessage(`{"labels":[]}`),
+					},
+				},
+			}},
+			wantErr: `error determining CSV metadata for bundle "fake-bundle.badMetadata": property "olm.csv.metadata" with value "{\"labels\":[]}" could not be parsed: json: cannot unmarshal array into Go struct field CSVMetadata.labels of type map[string]string`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			archs, archErr := tt.bundle.SupportedArchitectures()
+			oses, osErr := tt.bundle.SupportedOperatingSystems()
+			assert.Equal(t, tt.wantArchs, archs)
+			assert.Equal(t, tt.wantOSes, oses)
+			if tt.wantErr != "" {
+				assert.EqualError(t, archErr, tt.wantErr)
+				assert.EqualError(t, osErr, tt.wantErr)
+			} else {
+				assert.NoError(t, archErr)
+				assert.NoError(t, osErr)
+			}
+		})
+	}
+}
+
 func TestBundleHasDeprecation(t *testing.T) {
 	for _, tt := range []struct {
 		name       string
@@ -281,3 +591,60 @@ func TestBundleIsDeprecated(t *testing.T) {
 		})
 	}
 }
+
+func TestBundleCatalogPriority(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		labels       map[string]string
+		wantPriority int
+	}{
+		{name: "no labels", wantPriority: 0},
+		{name: "priority label", labels: map[string]string{catalogmetadata.LabelCatalogPriority: "10"}, wantPriority: 10},
+		{name: "invalid priority label", labels: map[string]string{catalogmetadata.LabelCatalogPriority: "high"}, wantPriority: 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &catalogmetadata.Bundle{CatalogLabels: tt.labels}
+			assert.Equal(t, tt.wantPriority, b.CatalogPriority())
+		})
+	}
+}
+
+func TestBundleInDeprecatedChannels(t *testing.T) {
+	stable := &catalogmetadata.Channel{Channel: declcfg.Channel{Name: "stable"}}
+	legacy := &catalogmetadata.Channel{Channel: declcfg.Channel{Name: "legacy"}}
+	legacyDeprecation := declcfg.DeprecationEntry{
+		Reference: declcfg.PackageScopedReference{
+			Schema: "olm.channel",
+			Name:   "legacy",
+		},
+	}
+	for _, tt := range []struct {
+		name       string
+		bundle     *catalogmetadata.Bundle
+		deprecated bool
+	}{
+		{
+			name:   "in no channels, not deprecated",
+			bundle: &catalogmetadata.Bundle{Deprecations: []declcfg.DeprecationEntry{legacyDeprecation}},
+		},
+		{
+			name: "in a deprecated and a non-deprecated channel, not deprecated",
+			bundle: &catalogmetadata.Bundle{
+				InChannels:   []*catalogmetadata.Channel{stable, legacy},
+				Deprecations: []declcfg.DeprecationEntry{legacyDeprecation},
+			},
+		},
+		{
+			name: "only in deprecated channels, deprecated",
+			bundle: &catalogmetadata.Bundle{
+				InChannels:   []*catalogmetadata.Channel{legacy},
+				Deprecations: []declcfg.DeprecationEntry{legacyDeprecation},
+			},
+			deprecated: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.deprecated, tt.bundle.InDeprecatedChannels())
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// checkRequiredGVKs returns an error describing the first olm.gvk.required dependency
+// of the bundle that is neither served by a CustomResourceDefinition on the cluster
+// nor provided by the bundle another ClusterExtension has installed. It consults the
+// CRDs rukpak installed and the installed bundles first, and only reads every CRD on
+// the cluster through the CRDReader if neither provides the API.
+func (r *ClusterExtensionReconciler) checkRequiredGVKs(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, otherInstalled map[string]*catalogmetadata.Bundle) error {
+	requiredGVKs, err := bundle.RequiredGVKs()
+	if err != nil {
+		return fmt.Errorf("bundle %q has an invalid %q property: %w", bundle.Name, property.TypeGVKRequired, err)
+	}
+	if len(requiredGVKs) == 0 {
+		return nil
+	}
+
+	bundleCRDs := apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, &bundleCRDs, client.MatchingLabels{rukpakOwnerKindKey: rukpakv1alpha2.BundleDeploymentKind}); err != nil {
+		return err
+	}
+	var installedBundles []*catalogmetadata.Bundle
+	var allCRDs *apiextensionsv1.CustomResourceDefinitionList
+	for _, required := range requiredGVKs {
+		gvk := property.GVK{Group: required.Group, Kind: required.Kind, Version: required.Version}
+		if crdsServe(bundleCRDs.Items, gvk) {
+			continue
+		}
+		if installedBundles == nil {
+			byExtension, err := r.otherInstalledBundlesFor(ctx, ext, otherInstalled)
+			if err != nil {
+				return err
+			}
+			installedBundles = sortedBundles(byExtension)
+		}
+		if len(catalogfilter.Filter(installedBundles, catalogfilter.ProvidingGVK(gvk))) > 0 {
+			continue
+		}
+		if allCRDs == nil {
+			allCRDs = &apiextensionsv1.CustomResourceDefinitionList{}
+			if err := r.crdReader().List(ctx, allCRDs); err != nil {
+				return err
+			}
+		}
+		if crdsServe(allCRDs.Items, gvk) {
+			continue
+		}
+		return fmt.Errorf("bundle %q requires API %s/%s %s, which is not provided by any installed ClusterExtension or CustomResourceDefinition",
+			bundle.Name, gvk.Group, gvk.Version, gvk.Kind)
+	}
+	return nil
+}
+
+// crdsServe reports whether one of the CustomResourceDefinitions serves the GVK.
+func crdsServe(crds []apiextensionsv1.CustomResourceDefinition, gvk property.GVK) bool {
+	for i := range crds {
+		crd := &crds[i]
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Name == gvk.Version && v.Served {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// otherInstalledBundles returns the bundles from allBundles installed by every
+// ClusterExtension other than ext. ClusterExtensions that are being deleted or whose
+// installed bundle can no longer be found in a catalog are not considered.
+func (r *ClusterExtensionReconciler) otherInstalledBundles(ctx context.Context, allBundles []*catalogmetadata.Bundle, ext *ocv1alpha1.ClusterExtension) ([]*catalogmetadata.Bundle, error) {
+	byExtension, err := r.otherInstalledBundlesByExtension(ctx, allBundles, ext)
+	if err != nil {
+		return nil, err
+	}
+	return sortedBundles(byExtension), nil
+}
+
+// sortedBundles returns the bundles ordered by the name of the ClusterExtension that
+// installed them.
+func sortedBundles(byExtension map[string]*catalogmetadata.Bundle) []*catalogmetadata.Bundle {
+	installed := []*catalogmetadata.Bundle{}
+	for _, name := range sets.List(sets.KeySet(byExtension)) {
+		installed = append(installed, byExtension[name])
+	}
+	return installed
+}
+
+// otherInstalledBundlesFor returns otherInstalled, the bundles installed by every
+// ClusterExtension other than ext as resolution looked them up, or looks them up in
+// the catalogs if it is nil, e.g. for a bundle that was not resolved from them.
+func (r *ClusterExtensionReconciler) otherInstalledBundlesFor(ctx context.Context, ext *ocv1alpha1.ClusterExtension, otherInstalled map[string]*catalogmetadata.Bundle) (map[string]*catalogmetadata.Bundle, error) {
+	if otherInstalled != nil {
+		return otherInstalled, nil
+	}
+	allBundles, err := r.BundleProvider.Bundles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.otherInstalledBundlesByExtension(ctx, allBundles, ext)
+}
+
+// otherInstalledBundlesByExtension returns the bundles otherInstalledBundles returns,
+// keyed by the name of the ClusterExtension that installed them.
+func (r *ClusterExtensionReconciler) otherInstalledBundlesByExtension(ctx context.Context, allBundles []*catalogmetadata.Bundle, ext *ocv1alpha1.ClusterExtension) (map[string]*catalogmetadata.Bundle, error) {
+	clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, &clusterExtensions); err != nil {
+		return nil, err
+	}
+
+	installed := map[string]*catalogmetadata.Bundle{}
+	for i := range clusterExtensions.Items {
+		other := &clusterExtensions.Items[i]
+		if other.Name == ext.Name || other.Status.InstalledBundle == nil || !other.DeletionTimestamp.IsZero() ||
+			other.Spec.ConfigMapBundle != nil || other.Spec.BundleImage != nil {
+			continue
+		}
+		bundle, err := r.installedBundle(ctx, allBundles, other)
+		if err != nil || bundle == nil {
+			continue
+		}
+		installed[other.Name] = bundle
+	}
+	return installed, nil
+}
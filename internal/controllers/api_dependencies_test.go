@@ -0,0 +1,126 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+// bundleDeploymentCRDsOnly lists CustomResourceDefinitions like the manager's cache,
+// which only holds those rukpak installed for a BundleDeployment.
+type bundleDeploymentCRDsOnly struct {
+	client.Client
+}
+
+func (c bundleDeploymentCRDsOnly) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*apiextensionsv1.CustomResourceDefinitionList); ok {
+		opts = append([]client.ListOption{client.MatchingLabels{"core.rukpak.io/owner-kind": rukpakv1alpha2.BundleDeploymentKind}}, opts...)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestClusterExtensionRequiredGVKs(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	group := fmt.Sprintf("%s.example.com", rand.String(8))
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Singular: "widget", Kind: "Widget", ListKind: "WidgetList"},
+			Scope: apiextensionsv1.ClusterScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"},
+				},
+			}},
+		},
+	}
+	deleteAllOnCleanup(t, cl)
+
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle("widgets", "1.0.0", property.MustBuildGVK(group, "v1", "Widget")),
+		testutil.NewBundle("gadgets", "1.0.0", property.MustBuildGVKRequired(group, "v1", "Widget")),
+		testutil.NewBundle("sprockets", "1.0.0", property.MustBuildGVKRequired(group, "v2", "Widget")),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         bundleDeploymentCRDsOnly{Client: cl},
+		CRDReader:      cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err != nil {
+			ext = &ocv1alpha1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name}, Spec: ocv1alpha1.ClusterExtensionSpec{PackageName: name}}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	wantErr := func(name, version string) string {
+		return fmt.Sprintf(`bundle "%s.v1.0.0" requires API %s/%s Widget, which is not provided by any installed ClusterExtension or CustomResourceDefinition`, name, group, version)
+	}
+
+	t.Log("It fails the preflight check when nothing provides the required API")
+	_, err := reconcile("gadgets")
+	require.EqualError(t, err, wantErr("gadgets", "v1"))
+
+	t.Log("It passes the preflight check when a CRD on the cluster serves the required API")
+	require.NoError(t, cl.Create(ctx, crd))
+	ext, err := reconcile("gadgets")
+	require.NoError(t, err)
+	require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "RequiredDependencies", Passed: true})
+	require.NoError(t, cl.Delete(ctx, crd))
+	require.Eventually(t, func() bool {
+		return apierrors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: crd.Name}, &apiextensionsv1.CustomResourceDefinition{}))
+	}, 10*time.Second, 100*time.Millisecond)
+
+	t.Log("It passes the preflight check when another installed extension provides the required API")
+	_, err = reconcile("widgets")
+	require.NoError(t, err)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + "widgets"}, bd))
+	bd.Status.ObservedGeneration = bd.GetGeneration()
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+		Message: "installed",
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+	_, err = reconcile("widgets")
+	require.NoError(t, err)
+	_, err = reconcile("gadgets")
+	require.NoError(t, err)
+
+	t.Log("It fails the preflight check when the required API version is not provided")
+	_, err = reconcile("sprockets")
+	require.EqualError(t, err, wantErr("sprockets", "v2"))
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// attentionRules lists, in order of importance, the condition statuses that need
+// human attention and how severe they are. Unknown statuses are not included:
+// they are expected while an installation is still in progress.
+var attentionRules = []struct {
+	conditionType string
+	status        metav1.ConditionStatus
+	severity      ocv1alpha1.AttentionSeverity
+}{
+	{ocv1alpha1.TypeResolved, metav1.ConditionFalse, ocv1alpha1.AttentionSeverityCritical},
+	{ocv1alpha1.TypeInstalled, metav1.ConditionFalse, ocv1alpha1.AttentionSeverityCritical},
+	{ocv1alpha1.TypeHealthy, metav1.ConditionFalse, ocv1alpha1.AttentionSeverityCritical},
+	{ocv1alpha1.TypeCRDsEstablished, metav1.ConditionFalse, ocv1alpha1.AttentionSeverityWarning},
+	{ocv1alpha1.TypePackageDeprecated, metav1.ConditionTrue, ocv1alpha1.AttentionSeverityWarning},
+	{ocv1alpha1.TypeChannelDeprecated, metav1.ConditionTrue, ocv1alpha1.AttentionSeverityWarning},
+	{ocv1alpha1.TypeBundleDeprecated, metav1.ConditionTrue, ocv1alpha1.AttentionSeverityWarning},
+}
+
+// attentionFor summarizes which of the given conditions need human attention.
+func attentionFor(conditions []metav1.Condition) *ocv1alpha1.AttentionStatus {
+	attention := &ocv1alpha1.AttentionStatus{Severity: ocv1alpha1.AttentionSeverityNone}
+	for _, rule := range attentionRules {
+		cond := apimeta.FindStatusCondition(conditions, rule.conditionType)
+		if cond == nil || cond.Status != rule.status {
+			continue
+		}
+		attention.Causes = append(attention.Causes, ocv1alpha1.AttentionCause{
+			ConditionType: cond.Type,
+			Reason:        cond.Reason,
+			Severity:      rule.severity,
+			Message:       cond.Message,
+		})
+		if attention.Severity != ocv1alpha1.AttentionSeverityCritical {
+			attention.Severity = rule.severity
+		}
+	}
+	attention.Required = len(attention.Causes) > 0
+	return attention
+}
@@ -0,0 +1,52 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionAttention(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	reconcileSpec := func(spec ocv1alpha1.ClusterExtensionSpec) *ocv1alpha1.ClusterExtension {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, _ = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		return ext
+	}
+
+	t.Log("It needs no attention while an installation is in progress")
+	ext := reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	require.Equal(t, metav1.ConditionUnknown, apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled).Status)
+	require.Equal(t, &ocv1alpha1.AttentionStatus{Severity: ocv1alpha1.AttentionSeverityNone}, ext.Status.Attention)
+
+	t.Log("It needs critical attention when resolution fails")
+	ext = reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"})
+	require.Equal(t, &ocv1alpha1.AttentionStatus{
+		Required: true,
+		Severity: ocv1alpha1.AttentionSeverityCritical,
+		Causes: []ocv1alpha1.AttentionCause{{
+			ConditionType: ocv1alpha1.TypeResolved,
+			Reason:        ocv1alpha1.ReasonResolutionFailed,
+			Severity:      ocv1alpha1.AttentionSeverityCritical,
+			Message:       `no package "prometheus" matching version "99.0.0" found`,
+		}},
+	}, ext.Status.Attention)
+}
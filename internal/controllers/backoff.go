@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/util/workqueue"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// BackoffPolicy is the exponential backoff applied to retries of a failure class.
+// The delay starts at Base and doubles on every consecutive failure, up to Max.
+type BackoffPolicy struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+// DefaultBackoffPolicies returns the backoff used for each failure class unless
+// configured otherwise. The Default class matches controller-runtime's default.
+func DefaultBackoffPolicies() map[ocv1alpha1.FailureClass]BackoffPolicy {
+	return map[ocv1alpha1.FailureClass]BackoffPolicy{
+		ocv1alpha1.FailureClassAuth:       {Base: 30 * time.Second, Max: 30 * time.Minute},
+		ocv1alpha1.FailureClassTransient:  {Base: time.Second, Max: 5 * time.Minute},
+		ocv1alpha1.FailureClassResolution: {Base: 10 * time.Second, Max: 10 * time.Minute},
+		ocv1alpha1.FailureClassDefault:    {Base: 5 * time.Millisecond, Max: 1000 * time.Second},
+	}
+}
+
+// ParseBackoffPolicies parses a comma-separated list of class=base:max entries, e.g.
+// "Auth=1m:1h,Transient=500ms:1m", and returns the default policies with the listed
+// classes overridden.
+func ParseBackoffPolicies(value string) (map[ocv1alpha1.FailureClass]BackoffPolicy, error) {
+	policies := DefaultBackoffPolicies()
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		class, durations, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid backoff policy %q: expected class=base:max", entry)
+		}
+		if _, known := policies[ocv1alpha1.FailureClass(class)]; !known {
+			return nil, fmt.Errorf("invalid backoff policy %q: unknown failure class %q", entry, class)
+		}
+		baseValue, maxValue, ok := strings.Cut(durations, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid backoff policy %q: expected class=base:max", entry)
+		}
+		base, err := time.ParseDuration(baseValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backoff policy %q: %w", entry, err)
+		}
+		maxDelay, err := time.ParseDuration(maxValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backoff policy %q: %w", entry, err)
+		}
+		if base <= 0 || maxDelay < base {
+			return nil, fmt.Errorf("invalid backoff policy %q: base must be positive and no greater than max", entry)
+		}
+		policies[ocv1alpha1.FailureClass(class)] = BackoffPolicy{Base: base, Max: maxDelay}
+	}
+	return policies, nil
+}
+
+// classifyFailure returns the failure class of an error returned by reconcile.
+func classifyFailure(err error, ext *ocv1alpha1.ClusterExtension) ocv1alpha1.FailureClass {
+	var netErr net.Error
+	switch {
+	case apierrors.IsUnauthorized(err) || apierrors.IsForbidden(err):
+		return ocv1alpha1.FailureClassAuth
+	case apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded):
+		return ocv1alpha1.FailureClassTransient
+	case apimeta.IsStatusConditionFalse(ext.Status.Conditions, ocv1alpha1.TypeResolved):
+		return ocv1alpha1.FailureClassResolution
+	default:
+		return ocv1alpha1.FailureClassDefault
+	}
+}
+
+// failureClassRateLimiter delays the retry of each request according to the
+// class of its most recent failure.
+type failureClassRateLimiter struct {
+	classes  *sync.Map
+	limiters map[ocv1alpha1.FailureClass]workqueue.RateLimiter
+}
+
+func newFailureClassRateLimiter(classes *sync.Map, policies map[ocv1alpha1.FailureClass]BackoffPolicy) workqueue.RateLimiter {
+	// Classes without a configured policy use their default.
+	merged := DefaultBackoffPolicies()
+	for class, policy := range policies {
+		merged[class] = policy
+	}
+	limiters := map[ocv1alpha1.FailureClass]workqueue.RateLimiter{}
+	for class, policy := range merged {
+		limiters[class] = workqueue.NewItemExponentialFailureRateLimiter(policy.Base, policy.Max)
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		&failureClassRateLimiter{classes: classes, limiters: limiters},
+		// Matches the overall limit of controller-runtime's default rate limiter.
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+func (l *failureClassRateLimiter) limiterFor(item interface{}) workqueue.RateLimiter {
+	if class, ok := l.classes.Load(item); ok {
+		if limiter, ok := l.limiters[class.(ocv1alpha1.FailureClass)]; ok {
+			return limiter
+		}
+	}
+	return l.limiters[ocv1alpha1.FailureClassDefault]
+}
+
+func (l *failureClassRateLimiter) When(item interface{}) time.Duration {
+	return l.limiterFor(item).When(item)
+}
+
+func (l *failureClassRateLimiter) Forget(item interface{}) {
+	for _, limiter := range l.limiters {
+		limiter.Forget(item)
+	}
+}
+
+func (l *failureClassRateLimiter) NumRequeues(item interface{}) int {
+	return l.limiterFor(item).NumRequeues(item)
+}
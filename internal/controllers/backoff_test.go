@@ -0,0 +1,141 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+func TestParseBackoffPolicies(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		value   string
+		want    map[ocv1alpha1.FailureClass]controllers.BackoffPolicy
+		wantErr string
+	}{
+		{
+			name: "empty uses defaults",
+			want: controllers.DefaultBackoffPolicies(),
+		},
+		{
+			name:  "overrides listed classes",
+			value: "Auth=1m:1h, Transient=500ms:1m",
+			want: func() map[ocv1alpha1.FailureClass]controllers.BackoffPolicy {
+				policies := controllers.DefaultBackoffPolicies()
+				policies[ocv1alpha1.FailureClassAuth] = controllers.BackoffPolicy{Base: time.Minute, Max: time.Hour}
+				policies[ocv1alpha1.FailureClassTransient] = controllers.BackoffPolicy{Base: 500 * time.Millisecond, Max: time.Minute}
+				return policies
+			}(),
+		},
+		{
+			name:    "unknown class",
+			value:   "Network=1s:1m",
+			wantErr: `invalid backoff policy "Network=1s:1m": unknown failure class "Network"`,
+		},
+		{
+			name:    "missing max",
+			value:   "Auth=1s",
+			wantErr: `invalid backoff policy "Auth=1s": expected class=base:max`,
+		},
+		{
+			name:    "max below base",
+			value:   "Auth=1m:1s",
+			wantErr: `invalid backoff policy "Auth=1m:1s": base must be positive and no greater than max`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := controllers.ParseBackoffPolicies(tt.value)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterExtensionFailureClass(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	t.Log("It reports the Resolution failure class when resolution fails")
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Error(t, err)
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Equal(t, ocv1alpha1.FailureClassResolution, clusterExtension.Status.FailureClass)
+
+	t.Log("It clears the failure class once the reconcile succeeds")
+	clusterExtension.Spec.Version = ""
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	require.Empty(t, clusterExtension.Status.FailureClass)
+}
+
+func TestClusterExtensionRetryStatus(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.BackoffPolicies = map[ocv1alpha1.FailureClass]controllers.BackoffPolicy{
+		ocv1alpha1.FailureClassResolution: {Base: time.Minute, Max: 3 * time.Minute},
+	}
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcile := func() (*ocv1alpha1.RetryStatus, error) {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		return clusterExtension.Status.Retry, err
+	}
+
+	t.Log("It records the first failed attempt and when it is retried")
+	retry, err := reconcile()
+	require.Error(t, err)
+	require.NotNil(t, retry)
+	require.Equal(t, int32(1), retry.Attempts)
+	require.Nil(t, retry.LastAttemptedBundle)
+	require.Equal(t, retry.FirstFailureTime, retry.LastAttemptTime)
+	require.Equal(t, time.Minute, retry.NextRetryTime.Sub(retry.LastAttemptTime.Time))
+	firstFailure := retry.FirstFailureTime
+
+	t.Log("It counts consecutive failed attempts and backs off up to the policy's maximum")
+	for _, want := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		retry, err = reconcile()
+		require.Error(t, err)
+		require.NotNil(t, retry)
+		require.Equal(t, firstFailure, retry.FirstFailureTime)
+		require.Equal(t, want, retry.NextRetryTime.Sub(retry.LastAttemptTime.Time))
+	}
+	require.Equal(t, int32(3), retry.Attempts)
+
+	t.Log("It clears the bookkeeping once the reconcile succeeds")
+	clusterExtension.Spec.Version = ""
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+	retry, err = reconcile()
+	require.NoError(t, err)
+	require.Nil(t, retry)
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bsemver "github.com/blang/semver/v4"
+
+	"github.com/operator-framework/api/pkg/constraints"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+var celEnvironment = constraints.NewCelEnvironment()
+
+// hasBundleConstraints reports whether any of the bundles declares an olm.constraint property.
+func hasBundleConstraints(bundles []*catalogmetadata.Bundle) bool {
+	for _, b := range bundles {
+		for _, p := range b.Properties {
+			if p.Type == property.TypeConstraint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyBundleConstraints returns the candidates, in their original order, whose
+// olm.constraint properties are each satisfied by one of the installed bundles. It
+// returns an error naming the constraint the most preferred candidate violates if
+// no candidate remains.
+func applyBundleConstraints(ext *ocv1alpha1.ClusterExtension, candidates []*catalogmetadata.Bundle, installed []*catalogmetadata.Bundle) ([]*catalogmetadata.Bundle, error) {
+	var firstViolation string
+	compatible := catalogfilter.Filter(candidates, func(b *catalogmetadata.Bundle) bool {
+		violation := unsatisfiedBundleConstraint(b, installed)
+		if violation != "" && firstViolation == "" {
+			firstViolation = fmt.Sprintf("bundle %q requires %s", b.Name, violation)
+		}
+		return violation == ""
+	})
+	if len(compatible) == 0 {
+		return nil, &resolutionError{
+			reason: ocv1alpha1.ReasonConstraintNotSatisfied,
+			err:    fmt.Errorf("no bundle of %s has its olm.constraint properties satisfied by the installed bundles: %s", describePackage(ext), firstViolation),
+		}
+	}
+	return compatible, nil
+}
+
+// unsatisfiedBundleConstraint describes the first olm.constraint property of the
+// bundle that the installed bundles do not satisfy, or returns an empty string if
+// every constraint is satisfied. A constraint is satisfied if one of the installed
+// bundles satisfies it, or, for a top-level not constraint, if none of them
+// satisfies any of its constraints.
+func unsatisfiedBundleConstraint(bundle *catalogmetadata.Bundle, installed []*catalogmetadata.Bundle) string {
+	for _, p := range bundle.Properties {
+		if p.Type != property.TypeConstraint {
+			continue
+		}
+		c, err := constraints.Parse(p.Value)
+		if err != nil {
+			return fmt.Sprintf("a valid %s property: %v", property.TypeConstraint, err)
+		}
+		universal := c.Not != nil
+		matching := c
+		if universal {
+			matching = constraints.Constraint{Any: c.Not}
+		}
+		matches, err := compileConstraint(matching)
+		if err != nil {
+			return fmt.Sprintf("%s, which could not be evaluated: %v", describeConstraint(c), err)
+		}
+		matched := false
+		for _, b := range installed {
+			if ok, err := matches(b); err != nil {
+				return fmt.Sprintf("%s, which could not be evaluated: %v", describeConstraint(c), err)
+			} else if ok {
+				matched = true
+				break
+			}
+		}
+		if matched == universal {
+			return describeConstraint(c)
+		}
+	}
+	return ""
+}
+
+// bundleMatcher reports whether a bundle satisfies a compiled constraint.
+type bundleMatcher func(*catalogmetadata.Bundle) (bool, error)
+
+// compileConstraint compiles the constraint, including its CEL rules and version
+// ranges, into a matcher that can be evaluated against many bundles.
+func compileConstraint(c constraints.Constraint) (bundleMatcher, error) {
+	switch {
+	case c.Cel != nil:
+		program, err := celEnvironment.Validate(c.Cel.Rule)
+		if err != nil {
+			return nil, err
+		}
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			props, err := celProperties(bundle)
+			if err != nil {
+				return false, err
+			}
+			return program.Evaluate(map[string]interface{}{constraints.PropertiesKey: props})
+		}, nil
+	case c.Package != nil:
+		versionRange, err := bsemver.ParseRange(c.Package.VersionRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %w", c.Package.VersionRange, err)
+		}
+		inRange := catalogfilter.InBlangSemverRange(versionRange)
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			return bundle.Package == c.Package.PackageName && inRange(bundle), nil
+		}, nil
+	case c.GVK != nil:
+		providing := catalogfilter.ProvidingGVK(property.GVK{Group: c.GVK.Group, Kind: c.GVK.Kind, Version: c.GVK.Version})
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			return providing(bundle), nil
+		}, nil
+	case c.All != nil:
+		subs, err := compileConstraints(c.All.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			for _, sub := range subs {
+				if ok, err := sub(bundle); err != nil || !ok {
+					return false, err
+				}
+			}
+			return true, nil
+		}, nil
+	case c.Any != nil:
+		subs, err := compileConstraints(c.Any.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			for _, sub := range subs {
+				if ok, err := sub(bundle); err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}, nil
+	case c.Not != nil:
+		subs, err := compileConstraints(c.Not.Constraints)
+		if err != nil {
+			return nil, err
+		}
+		return func(bundle *catalogmetadata.Bundle) (bool, error) {
+			for _, sub := range subs {
+				if ok, err := sub(bundle); err != nil || ok {
+					return false, err
+				}
+			}
+			return true, nil
+		}, nil
+	}
+	return nil, fmt.Errorf("constraint has no known value schema")
+}
+
+func compileConstraints(cs []constraints.Constraint) ([]bundleMatcher, error) {
+	matchers := make([]bundleMatcher, 0, len(cs))
+	for _, c := range cs {
+		m, err := compileConstraint(c)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// celProperties returns the bundle's properties in the form CEL rules are evaluated
+// against: a list of maps holding each property's type and decoded value.
+func celProperties(bundle *catalogmetadata.Bundle) ([]map[string]interface{}, error) {
+	props := make([]map[string]interface{}, 0, len(bundle.Properties))
+	for _, p := range bundle.Properties {
+		var value interface{}
+		if err := json.Unmarshal(p.Value, &value); err != nil {
+			return nil, fmt.Errorf("error decoding %q property of bundle %q: %w", p.Type, bundle.Name, err)
+		}
+		props = append(props, map[string]interface{}{"type": p.Type, "value": value})
+	}
+	return props, nil
+}
+
+// describeConstraint returns the constraint's failure message, or a human readable
+// description of the constraint if it has none.
+func describeConstraint(c constraints.Constraint) string {
+	if c.FailureMessage != "" {
+		return c.FailureMessage
+	}
+	describeAll := func(cs []constraints.Constraint) string {
+		descriptions := make([]string, 0, len(cs))
+		for _, sub := range cs {
+			descriptions = append(descriptions, describeConstraint(sub))
+		}
+		return strings.Join(descriptions, ", ")
+	}
+	switch {
+	case c.Cel != nil:
+		return fmt.Sprintf("a bundle matching %q", c.Cel.Rule)
+	case c.Package != nil:
+		return fmt.Sprintf("package %q in range %q", c.Package.PackageName, c.Package.VersionRange)
+	case c.GVK != nil:
+		return fmt.Sprintf("API %s/%s %s", c.GVK.Group, c.GVK.Version, c.GVK.Kind)
+	case c.All != nil:
+		return fmt.Sprintf("all of (%s)", describeAll(c.All.Constraints))
+	case c.Any != nil:
+		return fmt.Sprintf("any of (%s)", describeAll(c.Any.Constraints))
+	case c.Not != nil:
+		return fmt.Sprintf("none of (%s)", describeAll(c.Not.Constraints))
+	}
+	return "an unknown constraint"
+}
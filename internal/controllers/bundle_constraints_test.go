@@ -0,0 +1,109 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionBundleConstraints(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	constraint := func(value string) property.Property {
+		return property.Property{Type: property.TypeConstraint, Value: json.RawMessage(value)}
+	}
+	widgetsRule := `properties.exists(p, p.type == 'olm.package' && p.value.packageName == 'widgets' && semver_compare(p.value.version, '2.0.0') >= 0)`
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle("widgets", "2.0.0"),
+		testutil.NewBundle("gadgets", "1.0.0"),
+		testutil.NewBundle("gadgets", "2.0.0", constraint(`{"cel":{"rule":"`+widgetsRule+`"}}`)),
+		testutil.NewBundle("sprockets", "1.0.0", constraint(`{"failureMessage":"sprockets requires gizmos","package":{"packageName":"gizmos","versionRange":">=1.0.0"}}`)),
+		testutil.NewBundle("doohickeys", "1.0.0", constraint(`{"failureMessage":"doohickeys conflicts with widgets","not":{"constraints":[{"package":{"packageName":"widgets","versionRange":">=2.0.0"}}]}}`)),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err != nil {
+			// The bundles have no upgrade edges, so upgrades ignore them.
+			ext = &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: name, UpgradeConstraintPolicy: ocv1alpha1.UpgradeConstraintPolicyIgnore},
+			}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It excludes bundles whose CEL constraint no installed bundle satisfies")
+	ext, err := reconcile("gadgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "gadgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It reports the failed constraint when no bundle satisfies its constraints")
+	ext, err = reconcile("sprockets")
+	require.EqualError(t, err, `no bundle of package "sprockets" has its olm.constraint properties satisfied by the installed bundles: bundle "sprockets.v1.0.0" requires sprockets requires gizmos`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonConstraintNotSatisfied, cond.Reason)
+
+	t.Log("It resolves bundles whose not constraint matches no installed bundle when none is installed")
+	ext, err = reconcile("doohickeys")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "doohickeys.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It resolves bundles whose CEL constraint an installed bundle satisfies")
+	_, err = reconcile("widgets")
+	require.NoError(t, err)
+	install := func(name string) {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + name}, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+		_, err := reconcile(name)
+		require.NoError(t, err)
+	}
+	install("widgets")
+	ext, err = reconcile("gadgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "gadgets.v2.0.0", Version: "2.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It excludes bundles whose not constraint any one of the installed bundles conflicts with")
+	install("gadgets")
+	ext, err = reconcile("doohickeys")
+	require.EqualError(t, err, `no bundle of package "doohickeys" has its olm.constraint properties satisfied by the installed bundles: bundle "doohickeys.v1.0.0" requires doohickeys conflicts with widgets`)
+	require.Nil(t, ext.Status.ResolvedBundle)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// bundleFromImage returns a bundle describing the bundle image referenced by the
+// ClusterExtension. The image is installed as a registry+v1 bundle without consulting
+// any catalog, so the package name and version are taken from the spec.
+func bundleFromImage(ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, error) {
+	ref := ext.Spec.BundleImage
+	if _, err := bsemver.Parse(ref.Version); err != nil {
+		return nil, fmt.Errorf("bundle image %q has invalid version %q: %w", ref.Ref, ref.Version, err)
+	}
+
+	pkgProperty, err := json.Marshal(property.Package{PackageName: ref.PackageName, Version: ref.Version})
+	if err != nil {
+		return nil, err
+	}
+	return &catalogmetadata.Bundle{
+		Bundle: declcfg.Bundle{
+			Name:    fmt.Sprintf("%s.v%s", ref.PackageName, ref.Version),
+			Package: ref.PackageName,
+			Image:   ref.Ref,
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: pkgProperty},
+			},
+		},
+	}, nil
+}
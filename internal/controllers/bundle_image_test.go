@@ -0,0 +1,71 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionBundleImage(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			BundleImage: &ocv1alpha1.BundleImage{Ref: "quay.io/example/prometheus-bundle:hotfix", PackageName: "prometheus", Version: "2.0.1"},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcileImage := func(image ocv1alpha1.BundleImage) (*rukpakv1alpha2.BundleDeployment, error) {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		clusterExtension.Spec.BundleImage = &image
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		return bd, err
+	}
+
+	t.Log("It installs the bundle image without resolving it from a catalog")
+	bd, err := reconcileImage(*clusterExtension.Spec.BundleImage)
+	require.NoError(t, err)
+	require.Equal(t, "core-rukpak-io-registry", bd.Spec.ProvisionerClassName)
+	require.Equal(t, rukpakv1alpha2.SourceTypeImage, bd.Spec.Source.Type)
+	require.Equal(t, "quay.io/example/prometheus-bundle:hotfix", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "prometheus.v2.0.1", Version: "2.0.1"}, clusterExtension.Status.ResolvedBundle)
+	require.Nil(t, clusterExtension.Status.Resolution)
+	cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, `resolved to "quay.io/example/prometheus-bundle:hotfix"`, cond.Message)
+
+	t.Log("It installs a different image without consulting the upgrade graph")
+	bd, err = reconcileImage(ocv1alpha1.BundleImage{Ref: "quay.io/example/prometheus-bundle:ci-1234", PackageName: "prometheus", Version: "1.0.0-ci.1234"})
+	require.NoError(t, err)
+	require.Equal(t, "quay.io/example/prometheus-bundle:ci-1234", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "prometheus.v1.0.0-ci.1234", Version: "1.0.0-ci.1234"}, clusterExtension.Status.ResolvedBundle)
+
+	t.Log("It fails when the version is not a semver version")
+	_, err = reconcileImage(ocv1alpha1.BundleImage{Ref: "quay.io/example/prometheus-bundle:latest", PackageName: "prometheus", Version: "latest"})
+	require.EqualError(t, err, `bundle image "quay.io/example/prometheus-bundle:latest" has invalid version "latest": No Major.Minor.Patch elements found`)
+	cond = apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonResolutionFailed, cond.Reason)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// ExtensionsAffectedByCatalog returns the ClusterExtensions, ordered by name, whose
+// resolution may change when the named catalog changes. These are the extensions
+// that select a package the catalog provided when its bundles were last read during
+// resolution, the extensions whose last resolution found candidates in the catalog,
+// so that removing a package from a catalog is also reported, and the extensions that
+// have not resolved to a bundle yet. If the catalog's bundles have not been read, as
+// for a new catalog, every extension is affected. ClusterExtensions installing from a
+// ConfigMap or a bundle image are never affected. It does not read catalog content.
+func (r *ClusterExtensionReconciler) ExtensionsAffectedByCatalog(ctx context.Context, catalogName string) ([]ocv1alpha1.ClusterExtension, error) {
+	packages, known := r.catalogPackages.packages(catalogName)
+
+	clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, &clusterExtensions); err != nil {
+		return nil, err
+	}
+
+	var affected []ocv1alpha1.ClusterExtension
+	for _, ext := range clusterExtensions.Items {
+		ext := ext
+		if ext.Spec.ConfigMapBundle != nil || ext.Spec.BundleImage != nil {
+			continue
+		}
+		if !known || packages.Has(ext.Spec.PackageName) || resolvedFromCatalog(&ext, catalogName) ||
+			ext.Status.Resolution == nil || ext.Status.Resolution.Selected == nil {
+			affected = append(affected, ext)
+		}
+	}
+	sort.Slice(affected, func(i, j int) bool {
+		return affected[i].Name < affected[j].Name
+	})
+	return affected, nil
+}
+
+// catalogPackageIndex holds the packages each catalog provided when its bundles
+// were last read.
+type catalogPackageIndex struct {
+	mu       sync.Mutex
+	catalogs map[string]sets.Set[string]
+}
+
+// record replaces the index with the packages of the bundles.
+func (i *catalogPackageIndex) record(bundles []*catalogmetadata.Bundle) {
+	catalogs := map[string]sets.Set[string]{}
+	for _, b := range bundles {
+		if catalogs[b.CatalogName] == nil {
+			catalogs[b.CatalogName] = sets.New[string]()
+		}
+		catalogs[b.CatalogName].Insert(b.Package)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.catalogs = catalogs
+}
+
+// packages returns the packages the named catalog provided, and whether its bundles
+// have been read.
+func (i *catalogPackageIndex) packages(catalogName string) (sets.Set[string], bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	packages, ok := i.catalogs[catalogName]
+	return packages, ok
+}
+
+// resolvedFromCatalog reports whether the last resolution of the ClusterExtension
+// found any candidates in the named catalog.
+func resolvedFromCatalog(ext *ocv1alpha1.ClusterExtension, catalogName string) bool {
+	if ext.Status.Resolution == nil {
+		return false
+	}
+	for _, c := range ext.Status.Resolution.Catalogs {
+		if c.Name == catalogName && c.Candidates > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// catalogContentChanged filters out updates of a Catalog that cannot change what it
+// resolves to, such as those that only record another poll of its image. Updates
+// that change its labels, its resolved image reference, its content URL or its
+// Unpacked condition pass, as do creations and deletions.
+func catalogContentChanged() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCatalog, ok := e.ObjectOld.(*catalogd.Catalog)
+			if !ok {
+				return true
+			}
+			newCatalog, ok := e.ObjectNew.(*catalogd.Catalog)
+			if !ok {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(oldCatalog.Labels, newCatalog.Labels) ||
+				resolvedCatalogRef(oldCatalog) != resolvedCatalogRef(newCatalog) ||
+				oldCatalog.Status.ContentURL != newCatalog.Status.ContentURL ||
+				!equality.Semantic.DeepEqual(
+					apimeta.FindStatusCondition(oldCatalog.Status.Conditions, catalogd.TypeUnpacked),
+					apimeta.FindStatusCondition(newCatalog.Status.Conditions, catalogd.TypeUnpacked))
+		},
+	}
+}
+
+// resolvedCatalogRef returns the digest reference of the catalog's unpacked image, or
+// an empty string if it has none.
+func resolvedCatalogRef(catalog *catalogd.Catalog) string {
+	if catalog.Status.ResolvedSource == nil || catalog.Status.ResolvedSource.Image == nil {
+		return ""
+	}
+	return catalog.Status.ResolvedSource.Image.ResolvedRef
+}
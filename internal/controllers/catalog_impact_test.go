@@ -0,0 +1,177 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestExtensionsAffectedByCatalog(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, catalog string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, "1.0.0")
+		b.Image = fmt.Sprintf("quay.io/%s/%s@fake1.0.0", catalog, pkg)
+		b.CatalogName = catalog
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("foo", "alpha"),
+		bundle("bar", "beta"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("impact-%s-", rand.String(8))
+	create := func(name string, spec ocv1alpha1.ClusterExtensionSpec) *ocv1alpha1.ClusterExtension {
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: prefix + name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		return ext
+	}
+	create("foo", ocv1alpha1.ClusterExtensionSpec{PackageName: "foo"})
+	create("bar", ocv1alpha1.ClusterExtensionSpec{PackageName: "bar"})
+	create("baz", ocv1alpha1.ClusterExtensionSpec{PackageName: "baz"})
+	create("configmap", ocv1alpha1.ClusterExtensionSpec{ConfigMapBundle: &ocv1alpha1.ConfigMapBundle{Name: "foo"}})
+
+	affectedNames := func(catalogName string) []string {
+		affected, err := reconciler.ExtensionsAffectedByCatalog(ctx, catalogName)
+		require.NoError(t, err)
+		var names []string
+		for _, ext := range affected {
+			if strings.HasPrefix(ext.Name, prefix) {
+				names = append(names, strings.TrimPrefix(ext.Name, prefix))
+			}
+		}
+		return names
+	}
+
+	t.Log("It reports every extension while the catalog's bundles have not been read")
+	require.Equal(t, []string{"bar", "baz", "foo"}, affectedNames("alpha"))
+
+	for _, name := range []string{"foo", "bar", "baz"} {
+		_, _ = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: prefix + name}})
+	}
+
+	t.Log("It reports the extensions selecting one of the catalog's packages and those that did not resolve")
+	require.Equal(t, []string{"baz", "foo"}, affectedNames("alpha"))
+	require.Equal(t, []string{"bar", "baz"}, affectedNames("beta"))
+
+	t.Log("It reports the extensions whose last resolution found candidates in the catalog")
+	removed := create("removed", ocv1alpha1.ClusterExtensionSpec{PackageName: "qux"})
+	removed.Status.Resolution = &ocv1alpha1.ResolutionStatus{
+		Selected: &ocv1alpha1.SelectedBundle{Bundle: ocv1alpha1.BundleMetadata{Name: "qux.v1.0.0", Version: "1.0.0"}, Package: "qux", Catalog: "alpha"},
+		Catalogs: []ocv1alpha1.CatalogResolutionStatus{{Name: "alpha", Candidates: 1, Selected: true}},
+	}
+	require.NoError(t, cl.Status().Update(ctx, removed))
+	require.Equal(t, []string{"baz", "foo", "removed"}, affectedNames("alpha"))
+	require.Equal(t, []string{"bar", "baz"}, affectedNames("beta"))
+
+	t.Log("It reports every extension for a catalog whose bundles have not been read")
+	require.Equal(t, []string{"bar", "baz", "foo", "removed"}, affectedNames("gamma"))
+
+	t.Log("It maps a catalog change to reconcile requests for the affected extensions")
+	mapCatalog := controllers.ClusterExtensionRequestsForCatalog(reconciler, logr.Discard())
+	requested := func(catalogName string) []string {
+		var names []string
+		for _, req := range mapCatalog(ctx, &catalogd.Catalog{ObjectMeta: metav1.ObjectMeta{Name: catalogName}}) {
+			if strings.HasPrefix(req.Name, prefix) {
+				names = append(names, strings.TrimPrefix(req.Name, prefix))
+			}
+		}
+		return names
+	}
+	require.Equal(t, []string{"bar", "baz"}, requested("beta"))
+	require.Equal(t, []string{"bar", "baz", "foo", "removed"}, requested("gamma"))
+}
+
+func TestCatalogContentChanged(t *testing.T) {
+	catalog := func(mutate func(*catalogd.Catalog)) *catalogd.Catalog {
+		c := &catalogd.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "catalog", Labels: map[string]string{"env": "prod"}},
+			Status: catalogd.CatalogStatus{
+				ResolvedSource: &catalogd.ResolvedCatalogSource{
+					Type:  catalogd.SourceTypeImage,
+					Image: &catalogd.ResolvedImageSource{Ref: "quay.io/example/catalog:latest", ResolvedRef: "quay.io/example/catalog@sha256:1"},
+				},
+				ContentURL: "https://catalogd/catalogs/catalog/all.json",
+				Conditions: []metav1.Condition{{Type: catalogd.TypeUnpacked, Status: metav1.ConditionTrue, Reason: catalogd.ReasonUnpackSuccessful}},
+			},
+		}
+		if mutate != nil {
+			mutate(c)
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		name    string
+		mutate  func(*catalogd.Catalog)
+		changed bool
+	}{
+		{name: "no change", changed: false},
+		{
+			name:    "another poll of the image",
+			mutate:  func(c *catalogd.Catalog) { c.Status.ResolvedSource.Image.LastPollAttempt = metav1.Now() },
+			changed: false,
+		},
+		{
+			name:    "label",
+			mutate:  func(c *catalogd.Catalog) { c.Labels["env"] = "staging" },
+			changed: true,
+		},
+		{
+			name: "resolvedRef",
+			mutate: func(c *catalogd.Catalog) {
+				c.Status.ResolvedSource.Image.ResolvedRef = "quay.io/example/catalog@sha256:2"
+			},
+			changed: true,
+		},
+		{
+			name:    "contentURL",
+			mutate:  func(c *catalogd.Catalog) { c.Status.ContentURL = "https://catalogd/catalogs/catalog/v2/all.json" },
+			changed: true,
+		},
+		{
+			name: "Unpacked condition",
+			mutate: func(c *catalogd.Catalog) {
+				c.Status.Conditions[0].Status = metav1.ConditionFalse
+				c.Status.Conditions[0].Reason = catalogd.ReasonUnpackFailed
+			},
+			changed: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			changed := controllers.CatalogContentChanged().Update(event.UpdateEvent{ObjectOld: catalog(nil), ObjectNew: catalog(tc.mutate)})
+			assert.Equal(t, tc.changed, changed)
+		})
+	}
+
+	t.Run("create and delete", func(t *testing.T) {
+		assert.True(t, controllers.CatalogContentChanged().Create(event.CreateEvent{Object: catalog(nil)}))
+		assert.True(t, controllers.CatalogContentChanged().Delete(event.DeleteEvent{Object: catalog(nil)}))
+	})
+}
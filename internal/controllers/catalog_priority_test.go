@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionCatalogPriority(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	// Every catalog publishes the same image for a version, as mirrors do.
+	bundle := func(pkg, version, catalog, priority string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, version)
+		b.Name = fmt.Sprintf("%s.%s.v%s", catalog, pkg, version)
+		b.CatalogName = catalog
+		b.CatalogLabels = map[string]string{catalogmetadata.LabelCatalogPriority: priority}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "2.0.0", "community", "0"),
+		bundle("widgets", "1.0.0", "certified", "10"),
+		bundle("gadgets", "1.0.0", "mirror-b", "5"),
+		bundle("gadgets", "1.0.0", "mirror-a", "5"),
+		bundle("gadgets", "2.0.0", "community", "0"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(pkg string) *ocv1alpha1.ClusterExtension {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext
+	}
+
+	t.Log("It resolves from the highest priority catalog providing the package, even when another catalog has a newer version")
+	ext := reconcile("widgets")
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "certified.widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.Empty(t, ext.Status.Resolution.TiedCatalogs)
+
+	t.Log("It reports catalogs of equal priority that provide the same content and selects among them by catalog name")
+	ext = reconcile("gadgets")
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "mirror-a.gadgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, []string{"mirror-a", "mirror-b"}, ext.Status.Resolution.TiedCatalogs)
+}
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// catalogSnapshotPredicate returns a predicate that keeps the bundles of the catalogs
+// listed in spec.catalogSnapshots. It returns an error with reason
+// CatalogSnapshotUnavailable naming every listed catalog that none of the bundles
+// were read from at the listed image. Every bundle is kept if no snapshots are listed.
+func catalogSnapshotPredicate(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle) (catalogfilter.Predicate[catalogmetadata.Bundle], error) {
+	snapshots := ext.Spec.CatalogSnapshots
+	if len(snapshots) == 0 {
+		return func(*catalogmetadata.Bundle) bool { return true }, nil
+	}
+
+	current := map[string]string{}
+	for _, b := range allBundles {
+		current[b.CatalogName] = b.CatalogResolvedRef
+	}
+	pinned := make(map[string]struct{}, len(snapshots))
+	var unavailable []string
+	for _, snapshot := range snapshots {
+		pinned[snapshot.Catalog] = struct{}{}
+		ref, ok := current[snapshot.Catalog]
+		switch {
+		case !ok:
+			unavailable = append(unavailable, fmt.Sprintf("catalog %q is not available", snapshot.Catalog))
+		case ref != snapshot.ResolvedRef:
+			unavailable = append(unavailable, fmt.Sprintf("catalog %q is unpacked from %q, not %q", snapshot.Catalog, ref, snapshot.ResolvedRef))
+		}
+	}
+	if len(unavailable) > 0 {
+		return nil, &resolutionError{
+			reason: ocv1alpha1.ReasonCatalogSnapshotUnavailable,
+			err:    fmt.Errorf("catalog snapshots are unavailable: %s", strings.Join(unavailable, "; ")),
+		}
+	}
+	return func(b *catalogmetadata.Bundle) bool {
+		_, ok := pinned[b.CatalogName]
+		return ok
+	}, nil
+}
@@ -0,0 +1,79 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionCatalogSnapshots(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(version, catalog, resolvedRef string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.CatalogName = catalog
+		b.CatalogRef = "quay.io/example/" + catalog + ":latest"
+		b.CatalogResolvedRef = resolvedRef
+		return b
+	}
+	stableRef := "quay.io/example/stable@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	advancedRef := "quay.io/example/stable@sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	otherRef := "quay.io/example/other@sha256:00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+	catalogs := func(bundles ...*catalogmetadata.Bundle) controllers.BundleProvider {
+		fakeCatalogClient := testutil.NewFakeCatalogClient(bundles)
+		return &fakeCatalogClient
+	}
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:                cl,
+		BundleProvider:        catalogs(bundle("1.0.0", "stable", stableRef), bundle("2.0.0", "other", otherRef)),
+		RequirePinnedCatalogs: true,
+	}
+
+	reconcile := func(snapshots ...ocv1alpha1.CatalogSnapshot) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", CatalogSnapshots: snapshots},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It only resolves from the catalogs at their snapshots, even if they are referenced by a tag")
+	ext, err := reconcile(ocv1alpha1.CatalogSnapshot{Catalog: "stable", ResolvedRef: stableRef})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, []ocv1alpha1.CatalogResolutionStatus{
+		{Name: "stable", Candidates: 1, Selected: true, ResolvedRef: stableRef},
+	}, ext.Status.Resolution.Catalogs)
+
+	t.Log("It fails to resolve once a catalog has advanced past its snapshot")
+	reconciler.BundleProvider = catalogs(bundle("1.1.0", "stable", advancedRef), bundle("2.0.0", "other", otherRef))
+	ext, err = reconcile(
+		ocv1alpha1.CatalogSnapshot{Catalog: "stable", ResolvedRef: stableRef},
+		ocv1alpha1.CatalogSnapshot{Catalog: "missing", ResolvedRef: otherRef},
+	)
+	require.EqualError(t, err, fmt.Sprintf(`catalog snapshots are unavailable: catalog "stable" is unpacked from %q, not %q; catalog "missing" is not available`, advancedRef, stableRef))
+	require.Nil(t, ext.Status.ResolvedBundle)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonCatalogSnapshotUnavailable, cond.Reason)
+}
@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// ClusterVersions are the current version of the cluster and, if an upgrade is
+// planned or in progress, the version it is upgrading to.
+type ClusterVersions struct {
+	Current bsemver.Version
+	Next    *bsemver.Version
+}
+
+func (v ClusterVersions) all() []bsemver.Version {
+	if v.Next == nil {
+		return []bsemver.Version{v.Current}
+	}
+	return []bsemver.Version{v.Current, *v.Next}
+}
+
+func (v ClusterVersions) String() string {
+	if v.Next == nil {
+		return fmt.Sprintf("cluster version %q", v.Current.String())
+	}
+	return fmt.Sprintf("cluster version %q and next cluster version %q", v.Current.String(), v.Next.String())
+}
+
+// ClusterVersionProvider returns the versions of the cluster that bundles must be
+// compatible with under spec.upgrade.clusterVersionPolicy Enforce.
+type ClusterVersionProvider interface {
+	ClusterVersions(ctx context.Context) (ClusterVersions, error)
+}
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get
+
+// OpenShiftClusterVersions reads the cluster versions from the OpenShift ClusterVersion
+// resource. The current version is the most recently completed update, and the next
+// version is the desired update if it differs from it.
+type OpenShiftClusterVersions struct {
+	Reader client.Reader
+}
+
+var clusterVersionGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ClusterVersion"}
+
+func (o *OpenShiftClusterVersions) ClusterVersions(ctx context.Context) (ClusterVersions, error) {
+	cv := &unstructured.Unstructured{}
+	cv.SetGroupVersionKind(clusterVersionGVK)
+	if err := o.Reader.Get(ctx, types.NamespacedName{Name: "version"}, cv); err != nil {
+		return ClusterVersions{}, fmt.Errorf("error getting the OpenShift cluster version: %w", err)
+	}
+
+	history, _, _ := unstructured.NestedSlice(cv.Object, "status", "history")
+	var current string
+	for _, h := range history {
+		entry, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if state, _, _ := unstructured.NestedString(entry, "state"); state == "Completed" {
+			current, _, _ = unstructured.NestedString(entry, "version")
+			break
+		}
+	}
+	if current == "" {
+		return ClusterVersions{}, errors.New("the OpenShift cluster version has no completed update")
+	}
+	currentVersion, err := bsemver.ParseTolerant(current)
+	if err != nil {
+		return ClusterVersions{}, fmt.Errorf("error parsing the OpenShift cluster version %q: %w", current, err)
+	}
+	versions := ClusterVersions{Current: currentVersion}
+
+	desired, _, _ := unstructured.NestedString(cv.Object, "spec", "desiredUpdate", "version")
+	if desired == "" {
+		desired, _, _ = unstructured.NestedString(cv.Object, "status", "desired", "version")
+	}
+	if desired != "" && desired != current {
+		nextVersion, err := bsemver.ParseTolerant(desired)
+		if err != nil {
+			return ClusterVersions{}, fmt.Errorf("error parsing the desired OpenShift cluster version %q: %w", desired, err)
+		}
+		versions.Next = &nextVersion
+	}
+	return versions, nil
+}
+
+// clusterVersionPolicy returns the ClusterExtension's cluster version policy.
+func clusterVersionPolicy(ext *ocv1alpha1.ClusterExtension) ocv1alpha1.ClusterVersionPolicy {
+	if ext.Spec.Upgrade == nil || ext.Spec.Upgrade.ClusterVersionPolicy == "" {
+		return ocv1alpha1.ClusterVersionPolicyIgnore
+	}
+	return ext.Spec.Upgrade.ClusterVersionPolicy
+}
+
+func (r *ClusterExtensionReconciler) clusterVersions(ctx context.Context) (ClusterVersions, error) {
+	if r.ClusterVersions == nil {
+		return ClusterVersions{}, errors.New("spec.upgrade.clusterVersionPolicy is Enforce but the cluster version is not known")
+	}
+	return r.ClusterVersions.ClusterVersions(ctx)
+}
+
+// applyClusterVersionPolicy returns the candidates compatible with the cluster versions,
+// in their original order, and the most preferred candidate if it was excluded.
+func applyClusterVersionPolicy(candidates []*catalogmetadata.Bundle, versions ClusterVersions) ([]*catalogmetadata.Bundle, *catalogmetadata.Bundle) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+	compatible := catalogfilter.Filter(candidates, catalogfilter.CompatibleWithClusterVersions(versions.all()...))
+	if len(compatible) > 0 && compatible[0] == candidates[0] {
+		return compatible, nil
+	}
+	return compatible, candidates[0]
+}
+
+// clusterVersionError reports that no candidate is compatible with the cluster versions.
+func clusterVersionError(ext *ocv1alpha1.ClusterExtension, versions ClusterVersions) error {
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonClusterVersionIncompatible,
+		err:    fmt.Errorf("no bundle of %s is compatible with %s", describePackage(ext), versions),
+	}
+}
+
+// clusterVersionHoldStatus describes the held back bundle for status.resolution.heldBackByClusterVersion.
+func clusterVersionHoldStatus(heldBack *catalogmetadata.Bundle, versions ClusterVersions) (*ocv1alpha1.ClusterVersionHold, error) {
+	if heldBack == nil {
+		return nil, nil
+	}
+	version, err := heldBack.Version()
+	if err != nil {
+		return nil, err
+	}
+	// A range that cannot be read excluded the bundle too, and is reported as empty.
+	compatibleVersions, _ := heldBack.ClusterVersionRange()
+	hold := &ocv1alpha1.ClusterVersionHold{
+		Bundle:                    ocv1alpha1.BundleMetadata{Name: heldBack.Name, Version: version.String()},
+		CompatibleClusterVersions: compatibleVersions,
+		ClusterVersion:            versions.Current.String(),
+	}
+	if versions.Next != nil {
+		hold.NextClusterVersion = versions.Next.String()
+	}
+	return hold, nil
+}
@@ -0,0 +1,200 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+type fakeClusterVersions struct {
+	versions controllers.ClusterVersions
+}
+
+func (f *fakeClusterVersions) ClusterVersions(context.Context) (controllers.ClusterVersions, error) {
+	return f.versions, nil
+}
+
+func TestOpenShiftClusterVersions(t *testing.T) {
+	clusterVersion := func(spec, status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "ClusterVersion",
+			"metadata":   map[string]interface{}{"name": "version"},
+			"spec":       spec,
+			"status":     status,
+		}}
+	}
+	history := func(entries ...string) []interface{} {
+		var result []interface{}
+		for i := 0; i < len(entries); i += 2 {
+			result = append(result, map[string]interface{}{"state": entries[i], "version": entries[i+1]})
+		}
+		return result
+	}
+	next := bsemver.MustParse("4.16.0")
+	for _, tt := range []struct {
+		name    string
+		cv      *unstructured.Unstructured
+		want    controllers.ClusterVersions
+		wantErr string
+	}{
+		{
+			name: "no upgrade planned",
+			cv: clusterVersion(map[string]interface{}{}, map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.15.2"},
+				"history": history("Completed", "4.15.2", "Completed", "4.14.8"),
+			}),
+			want: controllers.ClusterVersions{Current: bsemver.MustParse("4.15.2")},
+		},
+		{
+			name: "upgrade in progress",
+			cv: clusterVersion(map[string]interface{}{}, map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.16.0"},
+				"history": history("Partial", "4.16.0", "Completed", "4.15.2"),
+			}),
+			want: controllers.ClusterVersions{Current: bsemver.MustParse("4.15.2"), Next: &next},
+		},
+		{
+			name: "upgrade requested",
+			cv: clusterVersion(map[string]interface{}{
+				"desiredUpdate": map[string]interface{}{"version": "4.16.0"},
+			}, map[string]interface{}{
+				"desired": map[string]interface{}{"version": "4.15.2"},
+				"history": history("Completed", "4.15.2"),
+			}),
+			want: controllers.ClusterVersions{Current: bsemver.MustParse("4.15.2"), Next: &next},
+		},
+		{
+			name: "installation not completed",
+			cv: clusterVersion(map[string]interface{}{}, map[string]interface{}{
+				"history": history("Partial", "4.15.2"),
+			}),
+			wantErr: "the OpenShift cluster version has no completed update",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &controllers.OpenShiftClusterVersions{Reader: fake.NewClientBuilder().WithObjects(tt.cv).Build()}
+			got, err := provider.ClusterVersions(context.Background())
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClusterExtensionClusterVersionPolicy(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.0.0"},
+			{Name: "widgets.v1.1.0", Replaces: "widgets.v1.0.0"},
+			{Name: "widgets.v2.0.0", Replaces: "widgets.v1.1.0"},
+		},
+	}}
+	bundle := func(version, clusterVersions string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version,
+			property.Property{Type: catalogmetadata.PropertyClusterVersionRange, Value: json.RawMessage(`"` + clusterVersions + `"`)})
+		b.InChannels = []*catalogmetadata.Channel{&channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0", ">=4.14.0 <4.18.0"),
+		bundle("1.1.0", ">=4.14.0 <4.17.0"),
+		bundle("2.0.0", ">=4.16.0"),
+	})
+	clusterVersions := &fakeClusterVersions{versions: controllers.ClusterVersions{Current: bsemver.MustParse("4.15.0")}}
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:          cl,
+		BundleProvider:  &fakeCatalogClient,
+		ClusterVersions: clusterVersions,
+	}
+
+	reconcile := func(extKey types.NamespacedName, spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err == nil {
+			ext.Spec = spec
+			require.NoError(t, cl.Update(ctx, ext))
+		} else {
+			ext = &ocv1alpha1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name}, Spec: spec}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	newKey := func() types.NamespacedName {
+		return types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	}
+	enforce := &ocv1alpha1.UpgradeConfig{ClusterVersionPolicy: ocv1alpha1.ClusterVersionPolicyEnforce}
+
+	t.Log("It ignores the cluster version by default")
+	ext, err := reconcile(newKey(), ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"}, ext.Status.ResolvedBundle)
+	require.Nil(t, ext.Status.Resolution.HeldBackByClusterVersion)
+
+	t.Log("It holds back bundles that are not compatible with the current cluster version")
+	extKey := newKey()
+	ext, err = reconcile(extKey, ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Upgrade: enforce})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, &ocv1alpha1.ClusterVersionHold{
+		Bundle:                    ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"},
+		CompatibleClusterVersions: ">=4.16.0",
+		ClusterVersion:            "4.15.0",
+	}, ext.Status.Resolution.HeldBackByClusterVersion)
+
+	t.Log("It fails resolution when no successor is compatible with the next cluster version")
+	next := bsemver.MustParse("4.17.0")
+	clusterVersions.versions.Next = &next
+	ext, err = reconcile(extKey, ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Upgrade: enforce})
+	require.EqualError(t, err, `no bundle of package "widgets" is compatible with cluster version "4.15.0" and next cluster version "4.17.0"`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonClusterVersionIncompatible, cond.Reason)
+
+	t.Log("It installs a bundle compatible with both the current and the next cluster version")
+	ext, err = reconcile(newKey(), ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Upgrade: enforce})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, &ocv1alpha1.ClusterVersionHold{
+		Bundle:                    ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"},
+		CompatibleClusterVersions: ">=4.16.0",
+		ClusterVersion:            "4.15.0",
+		NextClusterVersion:        "4.17.0",
+	}, ext.Status.Resolution.HeldBackByClusterVersion)
+
+	t.Log("It fails resolution when the cluster version is not known")
+	reconciler.ClusterVersions = nil
+	_, err = reconcile(newKey(), ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Upgrade: enforce})
+	require.EqualError(t, err, "spec.upgrade.clusterVersionPolicy is Enforce but the cluster version is not known")
+}
@@ -2,6 +2,7 @@ package controllers_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,13 +14,14 @@ import (
 func TestClusterExtensionAdmissionPackageName(t *testing.T) {
 	tooLongError := "spec.packageName: Too long: may not be longer than 48"
 	regexMismatchError := "spec.packageName in body should match"
+	noPackageError := "exactly one of packageName, providedAPI, configMapBundle or bundleImage must be set"
 
 	testCases := []struct {
 		name    string
 		pkgName string
 		errMsg  string
 	}{
-		{"no package name", "", regexMismatchError},
+		{"no package name", "", noPackageError},
 		{"long package name", "this-is-a-really-long-package-name-that-is-greater-than-48-characters", tooLongError},
 		{"leading digits with hypens", "0my-1package-9name", ""},
 		{"trailing digits with hypens", "my0-package1-name9", ""},
@@ -186,6 +188,462 @@ func TestClusterExtensionAdmissionChannel(t *testing.T) {
 	}
 }
 
+func TestClusterExtensionAdmissionChannels(t *testing.T) {
+	testCases := []struct {
+		name     string
+		channel  string
+		channels []ocv1alpha1.ChannelName
+		errMsg   string
+	}{
+		{"no channels", "", nil, ""},
+		{"several channels", "", []ocv1alpha1.ChannelName{"stable", "fast"}, ""},
+		{"invalid channel name", "", []ocv1alpha1.ChannelName{"stable", "Fast"}, "spec.channels[1] in body should match"},
+		{"channel and channels", "stable", []ocv1alpha1.ChannelName{"fast"}, "channel and channels are mutually exclusive"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: "package",
+				Channel:     tc.channel,
+				Channels:    tc.channels,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for channels %q: %w", tc.channels, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionMinimumVersion(t *testing.T) {
+	regexMismatchError := "spec.minimumVersion in body should match"
+
+	testCases := []struct {
+		name           string
+		minimumVersion string
+		errMsg         string
+	}{
+		{"no minimum version", "", ""},
+		{"simple semver", "1.2.3", ""},
+		{"with 'v' prefix", "v1.2.3", ""},
+		{"semver with pre-release and metadata", "1.2.3-alpha.1+metadata", ""},
+		{"range operator", ">=1.2.3", regexMismatchError},
+		{"missing patch", "1.2", regexMismatchError},
+		{"wildcard", "1.2.x", regexMismatchError},
+		{"leading zero", "01.2.3", regexMismatchError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:    "package",
+				MinimumVersion: tc.minimumVersion,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for minimum version %q: %w", tc.minimumVersion, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionLagReleases(t *testing.T) {
+	testCases := []struct {
+		name        string
+		lagReleases int32
+		errMsg      string
+	}{
+		{"zero", 0, ""},
+		{"positive", 3, ""},
+		{"negative", -1, "spec.upgrade.lagReleases in body should be greater than or equal to 0"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: "package",
+				Upgrade:     &ocv1alpha1.UpgradeConfig{LagReleases: tc.lagReleases},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for lagReleases %d: %w", tc.lagReleases, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionReportCandidates(t *testing.T) {
+	testCases := []struct {
+		name             string
+		reportCandidates int32
+		errMsg           string
+	}{
+		{"zero", 0, ""},
+		{"maximum", 50, ""},
+		{"negative", -1, "spec.resolution.reportCandidates in body should be greater than or equal to 0"},
+		{"above maximum", 51, "spec.resolution.reportCandidates in body should be less than or equal to 50"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: "package",
+				Resolution:  &ocv1alpha1.ResolutionConfig{ReportCandidates: tc.reportCandidates},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for reportCandidates %d: %w", tc.reportCandidates, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionUninstallCRPolicy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		crPolicy ocv1alpha1.CRPolicy
+		errMsg   string
+	}{
+		{"default", "", ""},
+		{"retain", ocv1alpha1.CRPolicyRetain, ""},
+		{"delete", ocv1alpha1.CRPolicyDelete, ""},
+		{"block", ocv1alpha1.CRPolicyBlock, ""},
+		{"unknown policy", "Orphan", `spec.uninstall.crPolicy: Unsupported value: "Orphan"`},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: "package",
+				Uninstall:   &ocv1alpha1.UninstallConfig{CRPolicy: tc.crPolicy},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for crPolicy %q: %w", tc.crPolicy, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionUninstallPropagationPolicy(t *testing.T) {
+	testCases := []struct {
+		name              string
+		propagationPolicy ocv1alpha1.PropagationPolicy
+		errMsg            string
+	}{
+		{"default", "", ""},
+		{"delete", ocv1alpha1.PropagationPolicyDelete, ""},
+		{"orphan", ocv1alpha1.PropagationPolicyOrphan, ""},
+		{"unknown policy", "Background", `spec.uninstall.propagationPolicy: Unsupported value: "Background"`},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: "package",
+				Uninstall:   &ocv1alpha1.UninstallConfig{PropagationPolicy: tc.propagationPolicy},
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for propagationPolicy %q: %w", tc.propagationPolicy, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionProvidedAPI(t *testing.T) {
+	exclusivityError := "exactly one of packageName, providedAPI, configMapBundle or bundleImage must be set"
+	groupMismatchError := "spec.providedAPI.group in body should match"
+	kindMismatchError := "spec.providedAPI.kind in body should match"
+
+	testCases := []struct {
+		name        string
+		pkgName     string
+		providedAPI *ocv1alpha1.ProvidedAPI
+		errMsg      string
+	}{
+		{"provided API only", "", &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "Widget"}, ""},
+		{"provided API and package name", "package", &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "Widget"}, exclusivityError},
+		{"neither provided API nor package name", "", nil, exclusivityError},
+		{"uppercase group", "", &ocv1alpha1.ProvidedAPI{Group: "Example.com", Kind: "Widget"}, groupMismatchError},
+		{"lowercase kind", "", &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "widget"}, kindMismatchError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName: tc.pkgName,
+				ProvidedAPI: tc.providedAPI,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for provided API %v: %w", tc.providedAPI, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionConfigMapBundle(t *testing.T) {
+	exclusivityError := "exactly one of packageName, providedAPI, configMapBundle or bundleImage must be set"
+	regexMismatchError := "spec.configMapBundle.name in body should match"
+
+	testCases := []struct {
+		name            string
+		pkgName         string
+		configMapBundle *ocv1alpha1.ConfigMapBundle
+		errMsg          string
+	}{
+		{"configmap bundle only", "", &ocv1alpha1.ConfigMapBundle{Name: "my-bundle"}, ""},
+		{"configmap bundle and package name", "package", &ocv1alpha1.ConfigMapBundle{Name: "my-bundle"}, exclusivityError},
+		{"invalid configmap name", "", &ocv1alpha1.ConfigMapBundle{Name: "My_Bundle"}, regexMismatchError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:     tc.pkgName,
+				ConfigMapBundle: tc.configMapBundle,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for configmap bundle %v: %w", tc.configMapBundle, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionBundleImage(t *testing.T) {
+	exclusivityError := "exactly one of packageName, providedAPI, configMapBundle or bundleImage must be set"
+	digestError := "resolvedBundleDigest cannot be used with bundleImage"
+	bundleImage := &ocv1alpha1.BundleImage{Ref: "quay.io/example/widgets-bundle:v1.0.0", PackageName: "widgets", Version: "1.0.0"}
+
+	testCases := []struct {
+		name        string
+		pkgName     string
+		bundleImage *ocv1alpha1.BundleImage
+		digest      string
+		errMsg      string
+	}{
+		{"bundle image only", "", bundleImage, "", ""},
+		{"bundle image and package name", "package", bundleImage, "", exclusivityError},
+		{"bundle image and resolved bundle digest", "", bundleImage, "sha256:" + strings.Repeat("a", 64), digestError},
+		{"missing image reference", "", &ocv1alpha1.BundleImage{PackageName: "widgets", Version: "1.0.0"}, "", "spec.bundleImage.ref in body should be at least 1 chars long"},
+		{"invalid package name", "", &ocv1alpha1.BundleImage{Ref: bundleImage.Ref, PackageName: "Widgets", Version: "1.0.0"}, "", "spec.bundleImage.packageName in body should match"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:          tc.pkgName,
+				BundleImage:          tc.bundleImage,
+				ResolvedBundleDigest: tc.digest,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for bundle image %v: %w", tc.bundleImage, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionResolvedBundleDigest(t *testing.T) {
+	regexMismatchError := "spec.resolvedBundleDigest in body should match"
+	configMapBundleError := "resolvedBundleDigest cannot be used with configMapBundle"
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	testCases := []struct {
+		name            string
+		pkgName         string
+		configMapBundle *ocv1alpha1.ConfigMapBundle
+		digest          string
+		errMsg          string
+	}{
+		{"no digest", "package", nil, "", ""},
+		{"sha256 digest", "package", nil, digest, ""},
+		{"missing algorithm", "package", nil, strings.Repeat("a", 64), regexMismatchError},
+		{"image reference", "package", nil, "quay.io/example/foo@" + digest, regexMismatchError},
+		{"with configmap bundle", "", &ocv1alpha1.ConfigMapBundle{Name: "my-bundle"}, digest, configMapBundleError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:          tc.pkgName,
+				ConfigMapBundle:      tc.configMapBundle,
+				ResolvedBundleDigest: tc.digest,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for resolved bundle digest %q: %w", tc.digest, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionPinnedImage(t *testing.T) {
+	regexMismatchError := "spec.pinnedImage in body should match"
+	digest := "sha256:" + strings.Repeat("a", 64)
+	image := "quay.io/example/widgets-bundle@" + digest
+
+	testCases := []struct {
+		name   string
+		spec   ocv1alpha1.ClusterExtensionSpec
+		errMsg string
+	}{
+		{"digest reference", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: image}, ""},
+		{"tag reference", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: "quay.io/example/widgets-bundle:v1.0.0"}, regexMismatchError},
+		{"bare digest", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: digest}, regexMismatchError},
+		{"without package name", ocv1alpha1.ClusterExtensionSpec{ProvidedAPI: &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "Widget"}, PinnedImage: image}, "pinnedImage requires packageName"},
+		{"with resolved bundle digest", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: image, ResolvedBundleDigest: digest}, "pinnedImage cannot be used with resolvedBundleDigest or rollbackTo"},
+		{"with rollback", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: image, RollbackTo: &ocv1alpha1.RollbackConfig{Revision: 1}}, "pinnedImage cannot be used with resolvedBundleDigest or rollbackTo"},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(tc.spec))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for pinned image %q: %w", tc.spec.PinnedImage, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionWatchNamespaces(t *testing.T) {
+	emptyStringError := "watchNamespaces cannot contain an empty string when it lists more than one namespace"
+
+	testCases := []struct {
+		name            string
+		watchNamespaces []string
+		errMsg          string
+	}{
+		{"unset", nil, ""},
+		{"all namespaces", []string{""}, ""},
+		{"single namespace", []string{"alpha"}, ""},
+		{"multiple namespaces", []string{"alpha", "beta"}, ""},
+		{"empty string among multiple namespaces", []string{"alpha", ""}, emptyStringError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:     "package",
+				WatchNamespaces: tc.watchNamespaces,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for watch namespaces %q: %w", tc.watchNamespaces, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
+func TestClusterExtensionAdmissionRevisionLabels(t *testing.T) {
+	ownerLabelError := "revisionLabels cannot set the olm.operatorframework.io/owner-name label"
+
+	testCases := []struct {
+		name           string
+		revisionLabels map[string]string
+		errMsg         string
+	}{
+		{"unset", nil, ""},
+		{"custom labels", map[string]string{"team": "payments", "example.com/env": "prod"}, ""},
+		{"owner label", map[string]string{ocv1alpha1.ClusterExtensionRevisionOwnerLabel: "other"}, ownerLabelError},
+	}
+
+	t.Parallel()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			cl := newClient(t)
+			err := cl.Create(context.Background(), buildClusterExtension(ocv1alpha1.ClusterExtensionSpec{
+				PackageName:    "package",
+				RevisionLabels: tc.revisionLabels,
+			}))
+			if tc.errMsg == "" {
+				require.NoError(t, err, "unexpected error for revision labels %q: %w", tc.revisionLabels, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.errMsg)
+			}
+		})
+	}
+}
+
 func buildClusterExtension(spec ocv1alpha1.ClusterExtensionSpec) *ocv1alpha1.ClusterExtension {
 	return &ocv1alpha1.ClusterExtension{
 		ObjectMeta: metav1.ObjectMeta{
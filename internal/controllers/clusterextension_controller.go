@@ -18,30 +18,44 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	mmsemver "github.com/Masterminds/semver/v3"
+	bsemver "github.com/blang/semver/v4"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
-	"github.com/operator-framework/operator-registry/alpha/property"
 	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
 
 	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
@@ -55,14 +69,87 @@ type ClusterExtensionReconciler struct {
 	client.Client
 	BundleProvider BundleProvider
 	Scheme         *runtime.Scheme
+	// DefaultCatalogSelector restricts resolution to catalogs whose labels
+	// match it, unless a ClusterExtension sets spec.catalogSelector. A nil
+	// selector considers every catalog on the cluster.
+	DefaultCatalogSelector labels.Selector
+	// BundleConfigMapNamespace is the namespace holding the ConfigMaps referenced
+	// by spec.configMapBundle. It must be the namespace rukpak unpacks ConfigMap
+	// sources from.
+	BundleConfigMapNamespace string
+	// CRDReader reads the CustomResourceDefinitions on the cluster for the required API
+	// check when no CRD rukpak installed and no installed bundle provides the API, as
+	// the manager's cache only holds the CRDs rukpak installed. The Client is used if
+	// it is nil.
+	CRDReader client.Reader
+	// ResolutionMetricsPackages lists the packages whose resolution outcomes are
+	// counted under their own name. All other packages are counted together.
+	ResolutionMetricsPackages sets.Set[string]
+	// BackoffPolicies sets how soon a failed reconcile is retried, for each class of
+	// failure. Classes that are not set use DefaultBackoffPolicies.
+	BackoffPolicies map[ocv1alpha1.FailureClass]BackoffPolicy
+	// MaxConcurrentInstalls caps how many BundleDeployments may be installing at
+	// once. ClusterExtensions that would start another install wait, in the order
+	// they started waiting, until one finishes. Zero means no limit.
+	MaxConcurrentInstalls int
+	// StalledInstallTimeout is how long a BundleDeployment that rukpak has not
+	// reported an Installed condition for counts against MaxConcurrentInstalls after
+	// it was created. Zero uses DefaultStalledInstallTimeout.
+	StalledInstallTimeout time.Duration
+	// ClusterVersions provides the cluster versions that bundles must be compatible
+	// with when spec.upgrade.clusterVersionPolicy is Enforce.
+	ClusterVersions ClusterVersionProvider
+	// KubernetesVersion provides the Kubernetes version that bundles declaring a
+	// minimum Kubernetes version must be compatible with. The minimum is not
+	// enforced if it is nil.
+	KubernetesVersion KubernetesVersionProvider
+	// NodePlatforms provides the platforms of the cluster's nodes, which bundles
+	// declaring their supported platforms must all support. Platforms are not
+	// checked if it is nil.
+	NodePlatforms NodePlatformProvider
+	// PreflightChecks are run against the resolved bundle before it is installed, after
+	// the built-in checks. SetupWithManager rejects checks that are not valid.
+	PreflightChecks []PreflightCheck
+	// RequirePinnedCatalogs excludes catalogs whose image is referenced by a tag rather
+	// than a digest from resolution.
+	RequirePinnedCatalogs bool
+	// CatalogRevisions identifies the contents of the catalogs, to cache the outcome of
+	// resolving each ClusterExtension until the catalogs, its spec or the installed
+	// bundles change. Resolution is not cached if it is nil.
+	CatalogRevisions CatalogRevisionProvider
+	// ResolutionTimeout bounds how long resolving a ClusterExtension may take before it
+	// fails with reason ResolutionTimedOut. Zero means no limit.
+	ResolutionTimeout time.Duration
+	// RevisionHistoryLimit is the number of ClusterExtensionRevisions kept for each
+	// ClusterExtension; the oldest are deleted. Zero keeps DefaultRevisionHistoryLimit.
+	RevisionHistoryLimit int
+	// RevisionLabels are set on every ClusterExtensionRevision when it is created,
+	// unless the ClusterExtension's spec.revisionLabels sets the same label.
+	RevisionLabels map[string]string
+
+	// Recorder records events for ClusterExtensions. Events are not recorded if it is nil.
+	Recorder record.EventRecorder
+
+	// failureClasses records the failure class of each request's most recent reconcile.
+	failureClasses sync.Map
+	// pendingInstalls holds the ClusterExtensions waiting for an install slot.
+	pendingInstalls installQueue
+	// resolutions holds the most recent successful resolution of each ClusterExtension.
+	resolutions sync.Map
+	// catalogPackages holds the packages of each catalog, as last read during
+	// resolution, for the Catalog watch.
+	catalogPackages catalogPackageIndex
 }
 
-//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions,verbs=get;list;watch
+//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions,verbs=get;list;watch;create;update
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions/status,verbs=update;patch
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=clusterextensionrevisions,verbs=get;list;watch;create;delete
 
 //+kubebuilder:rbac:groups=core.rukpak.io,resources=bundledeployments,verbs=get;list;watch;create;update;patch
 
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogs,verbs=list;watch
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogmetadata,verbs=list;watch
 
@@ -73,16 +160,47 @@ func (r *ClusterExtensionReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	var existingExt = &ocv1alpha1.ClusterExtension{}
 	if err := r.Get(ctx, req.NamespacedName, existingExt); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.pendingInstalls.remove(req.Name)
+			r.resolutions.Delete(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	reconciledExt := existingExt.DeepCopy()
 	res, reconcileErr := r.reconcile(ctx, reconciledExt)
+	// A ClusterExtension that stopped waiting for an install slot, e.g. because it
+	// was paused or now fails to resolve, must not hold back the ones behind it.
+	if !waitingForInstallSlot(reconciledExt, res) {
+		r.pendingInstalls.remove(req.Name)
+	}
+	r.setPreflightCheckConditions(reconciledExt)
+	setUpgradeAvailableStatus(reconciledExt)
+	reconciledExt.Status.Attention = attentionFor(reconciledExt.Status.Conditions)
+	reconciledExt.Status.FailureClass = ""
+	if reconcileErr != nil {
+		reconciledExt.Status.FailureClass = classifyFailure(reconcileErr, reconciledExt)
+		r.failureClasses.Store(req, reconciledExt.Status.FailureClass)
+	} else {
+		r.failureClasses.Delete(req)
+	}
+	reconciledExt.Status.Retry = r.retryStatus(existingExt.Status.Retry, reconciledExt, reconcileErr, time.Now())
+
+	// Timings alone do not warrant a status update: the update would trigger
+	// another reconcile, whose timings would differ again.
+	existingStatus, reconciledStatus := existingExt.Status, reconciledExt.Status
+	existingStatus.Timings, reconciledStatus.Timings = nil, nil
+	if equality.Semantic.DeepEqual(existingStatus, reconciledStatus) {
+		reconciledExt.Status.Timings = existingExt.Status.Timings
+	}
 
 	// Do checks before any Update()s, as Update() may modify the resource structure!
 	updateStatus := !equality.Semantic.DeepEqual(existingExt.Status, reconciledExt.Status)
 	updateFinalizers := !equality.Semantic.DeepEqual(existingExt.Finalizers, reconciledExt.Finalizers)
 	unexpectedFieldsChanged := checkForUnexpectedFieldChange(*existingExt, *reconciledExt)
+	// Status().Update() replaces reconciledExt with the server's copy, which
+	// does not have any finalizers added during this reconcile.
+	finalizers := reconciledExt.Finalizers
 
 	if updateStatus {
 		if updateErr := r.Status().Update(ctx, reconciledExt); updateErr != nil {
@@ -95,6 +213,7 @@ func (r *ClusterExtensionReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}
 
 	if updateFinalizers {
+		reconciledExt.Finalizers = finalizers
 		if updateErr := r.Update(ctx, reconciledExt); updateErr != nil {
 			return res, utilerrors.NewAggregate([]error{reconcileErr, updateErr})
 		}
@@ -110,59 +229,125 @@ func checkForUnexpectedFieldChange(a, b ocv1alpha1.ClusterExtension) bool {
 	return !equality.Semantic.DeepEqual(a, b)
 }
 
-// Helper function to do the actual reconcile
-//
-// Today we always return ctrl.Result{} and an error.
-// But in the future we might update this function
-// to return different results (e.g. requeue).
-//
-//nolint:unparam
+// Helper function to do the actual reconcile. The returned result requeues the
+// ClusterExtension while it waits for an install slot or for its installed objects
+// to become healthy.
 func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (ctrl.Result, error) {
+	if !ext.GetDeletionTimestamp().IsZero() {
+		return r.uninstall(ctx, ext)
+	}
+	controllerutil.AddFinalizer(ext, uninstallFinalizer)
+
+	// Don't do anything if Paused
+	ext.Status.Paused = ext.Spec.Paused
+	if ext.Spec.Paused {
+		log.FromContext(ctx).Info("resource is paused", "name", ext.GetName())
+		return ctrl.Result{}, nil
+	}
+
+	ext.Status.DryRun = nil
+	timings := &ocv1alpha1.ReconcileTimings{}
+	ext.Status.Timings = timings
+	reconcileTimer := startPhaseTimer()
+	defer func() { timings.Total = reconcileTimer() }()
+
 	// Lookup the bundle that corresponds to the ClusterExtension's desired package.
-	bundle, err := r.resolve(ctx, ext)
+	resolutionTimer := startPhaseTimer()
+	bundle, otherInstalled, err := r.resolveWithTimeout(ctx, ext)
+	timings.Resolution = resolutionTimer()
+	r.recordResolution(ext, bundle, err)
 	if err != nil {
 		ext.Status.InstalledBundle = nil
 		setInstalledStatusConditionUnknown(&ext.Status.Conditions, "installation has not been attempted as resolution failed", ext.GetGeneration())
 		ext.Status.ResolvedBundle = nil
-		setResolvedStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		ext.Status.ResolvedPackageName = ""
+		ext.Status.PreflightChecks = nil
+		ext.Status.Dependencies = nil
+		setResolvedStatusConditionFailedWithReason(&ext.Status.Conditions, resolutionFailureReason(err), err.Error(), ext.GetGeneration())
 
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as resolution failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "resolution failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 
 	// Now we can set the Resolved Condition, and the resolvedBundleSource field to the bundle.Image value.
 	ext.Status.ResolvedBundle = bundleMetadataFor(bundle)
-	setResolvedStatusConditionSuccess(&ext.Status.Conditions, fmt.Sprintf("resolved to %q", bundle.Image), ext.GetGeneration())
+	ext.Status.ResolvedPackageName = bundle.Package
+	setResolvedStatusConditionSuccess(&ext.Status.Conditions, fmt.Sprintf("resolved to %s", describeBundleSource(ext, bundle)), ext.GetGeneration())
 
 	// TODO: Question - Should we set the deprecation statuses after we have successfully resolved instead of after a successful installation?
 
 	mediaType, err := bundle.MediaType()
 	if err != nil {
+		ext.Status.PreflightChecks = nil
+		ext.Status.Dependencies = nil
 		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 
-	if err := r.validateBundle(bundle); err != nil {
+	preflightTimer := startPhaseTimer()
+	err = r.runPreflightChecks(ctx, ext, bundle, otherInstalled)
+	timings.Preflight = preflightTimer()
+	if err != nil {
 		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 
 	bundleProvisioner, err := mapBundleMediaTypeToBundleProvisioner(mediaType)
 	if err != nil {
 		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 	// Ensure a BundleDeployment exists with its bundle source from the bundle
 	// image we just looked up in the solution.
-	dep := r.GenerateExpectedBundleDeployment(*ext, bundle.Image, bundleProvisioner)
-	if err := r.ensureBundleDeployment(ctx, dep); err != nil {
+	dep := r.GenerateExpectedBundleDeployment(*ext, bundle, bundleProvisioner)
+	if ext.Spec.DryRun {
+		changes, err := r.dryRunChanges(ctx, dep)
+		if err != nil {
+			setInstalledStatusConditionUnknown(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+			setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
+			return ctrl.Result{}, err
+		}
+		dryRun := &ocv1alpha1.DryRunStatus{Bundle: *bundleMetadataFor(bundle), Changes: changes}
+		if err := r.dryRunObjectSize(ctx, ext, dryRun); err != nil {
+			setInstalledStatusConditionUnknown(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+			setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
+			return ctrl.Result{}, err
+		}
+		ext.Status.DryRun = dryRun
+		setInstalledStatusConditionDryRun(&ext.Status.Conditions, ext.GetGeneration())
+		SetDeprecationStatus(ext, bundle)
+		setInstalledObjectConditionsUnknown(&ext.Status.Conditions, "installation was skipped", ext.GetGeneration())
+		return ctrl.Result{}, nil
+	}
+	applyTimer := startPhaseTimer()
+	previousSourceType, err := r.bundleDeploymentSourceType(ctx, dep.GetName())
+	if err != nil {
+		ext.Status.InstalledBundle = nil
+		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
+		return ctrl.Result{}, err
+	}
+	if admitted, message, err := r.admitInstall(ctx, dep); err != nil {
+		ext.Status.InstalledBundle = nil
+		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
+		return ctrl.Result{}, err
+	} else if !admitted {
+		ext.Status.InstalledBundle = nil
+		setInstalledStatusConditionPending(&ext.Status.Conditions, message, ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation is pending", ext.GetGeneration())
+		return ctrl.Result{RequeueAfter: installPendingRequeueInterval}, nil
+	}
+	err = r.ensureBundleDeployment(ctx, dep)
+	timings.Apply = applyTimer()
+	if err != nil {
 		// originally Reason: ocv1alpha1.ReasonInstallationFailed
 		ext.Status.InstalledBundle = nil
 		setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 
@@ -172,41 +357,378 @@ func (r *ClusterExtensionReconciler) reconcile(ctx context.Context, ext *ocv1alp
 		// originally Reason: ocv1alpha1.ReasonInstallationStatusUnknown
 		ext.Status.InstalledBundle = nil
 		setInstalledStatusConditionUnknown(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
-		setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		setPostInstallConditionsUnknown(&ext.Status.Conditions, "installation has failed", ext.GetGeneration())
 		return ctrl.Result{}, err
 	}
 
 	// Let's set the proper Installed condition and InstalledBundle field based on the
 	// existing BundleDeployment object status.
+	var previousInstalled *metav1.Condition
+	if cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled); cond != nil {
+		previousInstalled = cond.DeepCopy()
+	}
 	mapBDStatusToInstalledCondition(existingTypedBundleDeployment, ext, bundle)
+	healthWait := applyInstallTimeout(ext, existingTypedBundleDeployment, previousInstalled, time.Now())
+	if sourceType := existingTypedBundleDeployment.Spec.Source.Type; previousSourceType != "" && previousSourceType != sourceType {
+		message := fmt.Sprintf("bundle source changed from %s to %s", previousSourceType, sourceType)
+		if r.Recorder != nil {
+			r.Recorder.Event(ext, corev1.EventTypeNormal, "SourceChanged", message)
+		}
+		// Any known status of the BundleDeployment still describes the bundle from the previous source.
+		if !apimeta.IsStatusConditionPresentAndEqual(ext.Status.Conditions, ocv1alpha1.TypeInstalled, metav1.ConditionUnknown) {
+			ext.Status.InstalledBundle = nil
+			setInstalledStatusConditionUnknown(&ext.Status.Conditions, message+"; waiting for the new bundle to be installed", ext.GetGeneration())
+		}
+	}
+
+	if apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1alpha1.TypeInstalled) {
+		recordInstallRevision(ext, existingTypedBundleDeployment)
+		if err := r.ensureRevision(ctx, ext, existingTypedBundleDeployment); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
 	SetDeprecationStatus(ext, bundle)
 
+	if !apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1alpha1.TypeInstalled) {
+		setInstalledObjectConditionsUnknown(&ext.Status.Conditions, "installation has not completed", ext.GetGeneration())
+		return ctrl.Result{RequeueAfter: healthWait}, nil
+	}
+	if err := r.setCRDsEstablishedStatus(ctx, ext); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setHealthyStatus(ctx, ext); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// set the status of the cluster extension based on the respective bundle deployment status conditions.
 	return ctrl.Result{}, nil
 }
 
-func (r *ClusterExtensionReconciler) resolve(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, error) {
+// resolve resolves the ClusterExtension and returns the bundle along with the bundles
+// installed by every other ClusterExtension, keyed by name, for the preflight checks.
+// The installed bundles are nil if the bundle was not resolved from the catalogs.
+func (r *ClusterExtensionReconciler) resolve(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, map[string]*catalogmetadata.Bundle, error) {
+	ext.Status.Resolution = nil
+	ext.Status.PendingUpgrade = nil
+	if ext.Spec.ConfigMapBundle != nil {
+		bundle, err := r.bundleFromConfigMap(ctx, ext)
+		return bundle, nil, err
+	}
+	if ext.Spec.BundleImage != nil {
+		bundle, err := bundleFromImage(ext)
+		return bundle, nil, err
+	}
+
+	// Reuse the previous outcome if none of its inputs changed, without fetching the catalogs.
+	cacheKey, cacheable, err := r.resolutionCacheKey(ctx, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cacheable {
+		cached, cacheErr := r.cachedResolutionFor(ctx, ext, cacheKey)
+		if cacheErr != nil {
+			return nil, nil, cacheErr
+		}
+		if cached != nil {
+			ext.Status.Resolution = cached.resolution.DeepCopy()
+			ext.Status.PendingUpgrade = cached.pendingUpgrade.DeepCopy()
+			return cached.bundle, cached.otherInstalled, nil
+		}
+	}
+
 	allBundles, err := r.BundleProvider.Bundles(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	r.catalogPackages.record(allBundles)
+	// Stop between stages once the resolution has been given up on.
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
 	}
 
-	installedBundle, err := r.installedBundle(ctx, allBundles, ext)
+	var selected *catalogmetadata.Bundle
+	var env resolutionEnvironment
+	switch {
+	case ext.Spec.RollbackTo != nil:
+		selected, err = r.rollbackBundle(ctx, ext, allBundles)
+	case ext.Spec.PinnedImage != "":
+		selected, err = r.pinnedImageBundle(ctx, ext, allBundles)
+	default:
+		var installedBundle *catalogmetadata.Bundle
+		if installedBundle, err = r.installedBundle(ctx, allBundles, ext); err != nil {
+			return nil, nil, err
+		}
+		selected, env, err = r.resolveFromCatalogs(ctx, ext, allBundles, installedBundle)
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	packageName := ext.Spec.PackageName
-	channelName := ext.Spec.Channel
+	otherInstalled, err := r.otherInstalledBundlesByExtension(ctx, allBundles, ext)
+	if err != nil {
+		return nil, nil, clusterError{err}
+	}
+	if cacheable && ctx.Err() == nil {
+		r.cacheResolution(ext, cacheKey, env, selected, otherInstalled)
+	}
+	return selected, otherInstalled, nil
+}
+
+// resolveFromCatalogs resolves the ClusterExtension from the catalogs' bundles given
+// the installed bundle, or nil for a fresh install, and sets status.resolution and
+// status.pendingUpgrade. ClusterExtensions and ResolutionRequests share it. It returns
+// the properties of the cluster that resolution consulted. Errors reading the cluster
+// are returned as a clusterError, and resolution failures are not.
+func (r *ClusterExtensionReconciler) resolveFromCatalogs(ctx context.Context, ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) (*catalogmetadata.Bundle, resolutionEnvironment, error) {
+	var env resolutionEnvironment
+	selector, err := catalogSelector(ext, r.DefaultCatalogSelector)
+	if err != nil {
+		return nil, env, err
+	}
+	inSnapshots, err := catalogSnapshotPredicate(ext, allBundles)
+	if err != nil {
+		return nil, env, err
+	}
+	catalogBundles := catalogfilter.Filter(allBundles, catalogfilter.And(catalogfilter.InCatalogsMatching(selector), inSnapshots))
+	var unpinnedBundles []*catalogmetadata.Bundle
+	// Catalogs listed in spec.catalogSnapshots are pinned to a digest by the spec.
+	if r.RequirePinnedCatalogs && len(ext.Spec.CatalogSnapshots) == 0 {
+		unpinnedBundles = catalogfilter.Filter(catalogBundles, catalogfilter.Not(catalogfilter.FromPinnedCatalog()))
+		catalogBundles = catalogfilter.Filter(catalogBundles, catalogfilter.FromPinnedCatalog())
+	}
+	catalogBundles, overrides := applyCatalogOverlays(catalogBundles)
+	candidates, err := Resolve(ext, catalogBundles, installedBundle)
+	// If no upgrade edge leads to the bundle the spec resolves to, upgrade towards it
+	// one bundle at a time.
+	var upgradePath []*catalogmetadata.Bundle
+	if err != nil {
+		path, next, planErr := planUpgradePath(ext, catalogBundles, installedBundle)
+		if planErr != nil {
+			return nil, env, planErr
+		}
+		if path != nil {
+			upgradePath, candidates, err = path, next, nil
+		}
+	}
+	if err != nil && len(unpinnedBundles) > 0 {
+		err = unpinnedCatalogError(ext, unpinnedBundles, installedBundle, err)
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, env, ctxErr
+	}
+	// Each constraint applied below reports the bundle it excluded as the pending
+	// upgrade in place of those excluded before it, as that bundle is the closest to
+	// being installed.
+	var pending *ocv1alpha1.PendingUpgrade
+	if err == nil && installedBundle != nil && ext.Spec.UpgradeConstraintPolicy != ocv1alpha1.UpgradeConstraintPolicyIgnore && upgradePath == nil {
+		pending = pendingUpgradeWithoutEdge(ext, catalogBundles, installedBundle, candidates[0])
+	}
+	// The constraints applied below record the bundles they exclude, to explain a
+	// failed resolution.
+	explanation := &resolutionExplanation{}
+	var heldBackBy []dependentConstraint
+	if err == nil {
+		// Keep the installed bundle compatible with the ClusterExtensions that depend on it.
+		constraints, constraintsErr := r.dependentConstraints(ctx, allBundles, ext, installedBundle)
+		if constraintsErr != nil {
+			return nil, env, clusterError{constraintsErr}
+		}
+		preferred := candidates[0]
+		before := candidates
+		candidates, heldBackBy = applyDependentConstraints(candidates, constraints)
+		if len(constraints) > 0 {
+			explanation.excluded("compatible with the ClusterExtensions that depend on the installed bundle", before, candidates,
+				ocv1alpha1.RejectionReasonDependentConstraint, func(b *catalogmetadata.Bundle) string {
+					var violated []string
+					for _, c := range constraints {
+						if !c.satisfiedBy(b) {
+							violated = append(violated, c.String())
+						}
+					}
+					return "violates the constraints: " + strings.Join(violated, "; ")
+				})
+		}
+		if len(candidates) == 0 {
+			err = dependentConstraintError(ext, heldBackBy)
+			heldBackBy = nil
+		} else if held := pendingUpgradeHeldBackByDependents(preferred, installedBundle, heldBackBy); held != nil {
+			pending = held
+		}
+	}
+	if err == nil && hasBundleConstraints(candidates) {
+		// Exclude bundles whose olm.constraint properties no other installed bundle satisfies.
+		installed, installedErr := r.otherInstalledBundles(ctx, allBundles, ext)
+		if installedErr != nil {
+			return nil, env, clusterError{installedErr}
+		}
+		before := candidates
+		candidates, err = applyBundleConstraints(ext, candidates, installed)
+		explanation.excluded("olm.constraint properties satisfied by the installed bundles", before, candidates,
+			ocv1alpha1.RejectionReasonBundleConstraint, func(b *catalogmetadata.Bundle) string {
+				return "requires " + unsatisfiedBundleConstraint(b, installed)
+			})
+	}
+	if err == nil && r.KubernetesVersion != nil && declaresMinKubeVersion(candidates) {
+		// Exclude bundles that require a newer Kubernetes version than the cluster's.
+		version, versionErr := r.KubernetesVersion.KubernetesVersion(ctx)
+		if versionErr != nil {
+			return nil, env, clusterError{versionErr}
+		}
+		env.kubernetesVersion = ptr.To(version.String())
+		before := candidates
+		candidates, err = applyKubernetesVersion(ext, candidates, version)
+		explanation.excluded(fmt.Sprintf("compatible with Kubernetes version %q", version.String()), before, candidates,
+			ocv1alpha1.RejectionReasonKubernetesVersion, func(b *catalogmetadata.Bundle) string {
+				minKubeVersion, minErr := b.MinKubeVersion()
+				if minErr != nil {
+					return "declares a minimum Kubernetes version that could not be read"
+				}
+				return fmt.Sprintf("requires Kubernetes version %q or later", minKubeVersion)
+			})
+	}
+	if err == nil && r.NodePlatforms != nil && declaresPlatforms(candidates) {
+		// Exclude bundles whose images do not support every node's platform.
+		platforms, platformsErr := r.NodePlatforms.NodePlatforms(ctx)
+		if platformsErr != nil {
+			return nil, env, clusterError{platformsErr}
+		}
+		env.nodePlatforms = ptr.To(describePlatforms(platforms))
+		before := candidates
+		candidates, err = applyNodePlatforms(ext, candidates, platforms)
+		explanation.excluded("supports every node platform", before, candidates,
+			ocv1alpha1.RejectionReasonPlatform, func(b *catalogmetadata.Bundle) string {
+				for _, p := range platforms {
+					if !catalogfilter.SupportingPlatforms(p)(b) {
+						return fmt.Sprintf("does not support node platform %s", p)
+					}
+				}
+				return "does not support the node platforms"
+			})
+	}
+	var clusterVersionHold *ocv1alpha1.ClusterVersionHold
+	if err == nil && clusterVersionPolicy(ext) == ocv1alpha1.ClusterVersionPolicyEnforce {
+		versions, versionsErr := r.clusterVersions(ctx)
+		if versionsErr != nil {
+			return nil, env, clusterError{versionsErr}
+		}
+		env.clusterVersions = ptr.To(versions.String())
+		var heldBack *catalogmetadata.Bundle
+		before := candidates
+		candidates, heldBack = applyClusterVersionPolicy(candidates, versions)
+		explanation.excluded("compatible with "+versions.String(), before, candidates,
+			ocv1alpha1.RejectionReasonClusterVersion, func(b *catalogmetadata.Bundle) string {
+				compatibleVersions, rangeErr := b.ClusterVersionRange()
+				if rangeErr != nil {
+					return "declares a cluster version range that could not be read"
+				}
+				return fmt.Sprintf("declares compatible cluster versions %q", compatibleVersions)
+			})
+		if len(candidates) == 0 {
+			err = clusterVersionError(ext, versions)
+		} else if clusterVersionHold, err = clusterVersionHoldStatus(heldBack, versions); err != nil {
+			return nil, env, err
+		} else if held := pendingUpgradeHeldBackByClusterVersion(heldBack, installedBundle, clusterVersionHold); held != nil {
+			pending = held
+		}
+	}
+	var selected *catalogmetadata.Bundle
+	if err == nil {
+		if preferInstalledImage(candidates, installedBundle) {
+			log.FromContext(ctx).Info("preferring the installed bundle image over an equally ranked candidate",
+				"image", candidates[0].Image, "catalog", candidates[0].CatalogName, "candidate", candidates[1].Image)
+		}
+		if ambiguous := ambiguousCatalogs(candidates, installedBundle); len(ambiguous) > 0 {
+			err = ambiguousResolutionError(ext, candidates[0], ambiguous)
+		} else {
+			selected = candidates[0]
+		}
+	}
+	if selected != nil {
+		var unapproved *catalogmetadata.Bundle
+		if selected, unapproved, err = applyUpgradeApproval(ext, candidates, installedBundle); err != nil {
+			return nil, env, err
+		}
+		if unapproved != nil {
+			pending = pendingUpgradeStatus(unapproved, ocv1alpha1.PendingUpgradeReasonApprovalRequired,
+				fmt.Sprintf("the upgrade to version %s has not been approved in spec.upgrade.approvedVersion", bundleMetadataFor(unapproved).Version))
+		}
+		ext.Status.PendingUpgrade = pending
+	}
+	// An outcome reached after the resolution was given up on is neither reported
+	// nor cached.
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, env, ctxErr
+	}
+	// Only report the overrides relevant to this ClusterExtension's package.
+	var packageOverrides []*catalogOverride
+	for _, o := range overrides {
+		if packagePredicate(ext)(o.Bundle) {
+			packageOverrides = append(packageOverrides, o)
+		}
+	}
+	ext.Status.Resolution = &ocv1alpha1.ResolutionStatus{
+		Selected:                 selectedBundleStatus(selected),
+		Catalogs:                 catalogResolutionStatuses(catalogBundles, packageOverrides, candidates, selected),
+		HeldBackBy:               heldBackStatus(heldBackBy),
+		HeldBackByClusterVersion: clusterVersionHold,
+		DeprecatedFallback:       selected != nil && (selected.IsDeprecated() || selected.InDeprecatedChannels()),
+		TiedCatalogs:             tiedCatalogs(candidates),
+	}
+	if ext.Spec.CatalogSelector == nil && r.DefaultCatalogSelector != nil && !r.DefaultCatalogSelector.Empty() {
+		ext.Status.Resolution.CatalogSelector = r.DefaultCatalogSelector.String()
+	}
+	if err != nil {
+		resolveExplanation := explainResolve(ext, catalogBundles, installedBundle)
+		resolveExplanation.extend(explanation)
+		ext.Status.Resolution.Explanation = resolveExplanation.status()
+		if r.Recorder != nil {
+			r.Recorder.Event(ext, corev1.EventTypeWarning, ocv1alpha1.ReasonResolutionFailed, resolveExplanation.summary(err))
+		}
+	}
+	if selected != nil && upgradePath != nil {
+		ext.Status.Resolution.UpgradePath = upgradePathStatus(upgradePath)
+		log.FromContext(ctx).Info("upgrading through intermediate bundles",
+			"installed", installedBundle.Name, "next", upgradePath[0].Name, "target", upgradePath[len(upgradePath)-1].Name)
+	}
+	if tied := ext.Status.Resolution.TiedCatalogs; selected != nil && len(tied) > 0 {
+		log.FromContext(ctx).Info("package is provided by multiple catalogs of equal priority",
+			"catalogs", tied, "selected", selected.Name, "catalog", selected.CatalogName)
+	}
+	if selected != nil && lagReleases(ext) > 0 {
+		releaseLag, lagErr := releaseLagStatus(ext, catalogBundles, selected)
+		if lagErr != nil {
+			return nil, env, lagErr
+		}
+		ext.Status.Resolution.ReleaseLag = releaseLag
+	}
+	if ext.Spec.Resolution != nil && ext.Spec.Resolution.ReportCandidates > 0 {
+		reported, candidatesErr := candidateStatuses(candidates, int(ext.Spec.Resolution.ReportCandidates))
+		if candidatesErr != nil {
+			return nil, env, candidatesErr
+		}
+		ext.Status.Resolution.Candidates = reported
+	}
+	return selected, env, err
+}
+
+// Resolve returns the bundles from allBundles that satisfy every constraint of the
+// ClusterExtension, most preferred first. installedBundle is the bundle currently
+// installed for the ClusterExtension, or nil for a fresh install. It returns an
+// error if no bundle satisfies the constraints.
+//
+// Resolve does not talk to a cluster, so it can be used to check how a catalog
+// resolves before it is published.
+func Resolve(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) ([]*catalogmetadata.Bundle, error) {
+	channels := specChannels(ext)
 	versionRange := ext.Spec.Version
 
 	predicates := []catalogfilter.Predicate[catalogmetadata.Bundle]{
-		catalogfilter.WithPackageName(packageName),
+		packagePredicate(ext),
 	}
 
-	if channelName != "" {
-		predicates = append(predicates, catalogfilter.InChannel(channelName))
+	if len(channels) > 0 {
+		predicates = append(predicates, channelPredicate(channels))
 	}
 
 	if versionRange != "" {
@@ -218,7 +740,7 @@ func (r *ClusterExtensionReconciler) resolve(ctx context.Context, ext *ocv1alpha
 	}
 
 	if ext.Spec.UpgradeConstraintPolicy != ocv1alpha1.UpgradeConstraintPolicyIgnore && installedBundle != nil {
-		upgradePredicate, err := SuccessorsPredicate(installedBundle)
+		upgradePredicate, err := upgradeSuccessorsPredicate(ext, allBundles, installedBundle)
 		if err != nil {
 			return nil, err
 		}
@@ -236,18 +758,132 @@ func (r *ClusterExtensionReconciler) resolve(ctx context.Context, ext *ocv1alpha
 		}
 		upgradeErrorPrefix = fmt.Sprintf("error upgrading from currently installed version %q: ", installedBundleVersion.String())
 	}
+	if len(resultSet) == 0 && ext.Spec.ResolvedBundleDigest != "" {
+		return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+	}
 	if len(resultSet) == 0 {
-		if versionRange != "" && channelName != "" {
-			return nil, fmt.Errorf("%sno package %q matching version %q found in channel %q", upgradeErrorPrefix, packageName, versionRange, channelName)
+		packageDescription := describePackage(ext)
+		if versionRange != "" && len(channels) > 0 {
+			return nil, fmt.Errorf("%sno %s matching version %q found in %s", upgradeErrorPrefix, packageDescription, versionRange, describeChannels(channels))
 		}
 		if versionRange != "" {
-			return nil, fmt.Errorf("%sno package %q matching version %q found", upgradeErrorPrefix, packageName, versionRange)
+			return nil, fmt.Errorf("%sno %s matching version %q found", upgradeErrorPrefix, packageDescription, versionRange)
+		}
+		if len(channels) > 0 {
+			return nil, fmt.Errorf("%sno %s found in %s", upgradeErrorPrefix, packageDescription, describeChannels(channels))
+		}
+		return nil, fmt.Errorf("%sno %s found", upgradeErrorPrefix, packageDescription)
+	}
+
+	if minimumVersion := ext.Spec.MinimumVersion; minimumVersion != "" {
+		floor, err := bsemver.ParseTolerant(minimumVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimum version %q: %w", minimumVersion, err)
+		}
+		resultSet = catalogfilter.Filter(resultSet, catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+			return v.GTE(floor)
+		}))
+		if len(resultSet) == 0 && ext.Spec.ResolvedBundleDigest != "" {
+			return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+		}
+		if len(resultSet) == 0 {
+			return nil, &resolutionError{
+				reason: ocv1alpha1.ReasonBelowMinimumFloor,
+				err:    fmt.Errorf("%sno %s at or above minimum version %q found", upgradeErrorPrefix, describePackage(ext), minimumVersion),
+			}
+		}
+	}
+
+	if !ext.Spec.AllowPrerelease {
+		var installedVersion *bsemver.Version
+		if installedBundle != nil {
+			var err error
+			if installedVersion, err = installedBundle.Version(); err != nil {
+				return nil, err
+			}
+		}
+		// An installed pre-release remains acceptable, so that it is not replaced by an
+		// older release when allowPrerelease is unset.
+		resultSet = catalogfilter.Filter(resultSet, catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+			return len(v.Pre) == 0 || (installedVersion != nil && v.EQ(*installedVersion))
+		}))
+		if len(resultSet) == 0 && ext.Spec.ResolvedBundleDigest != "" {
+			return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+		}
+		if len(resultSet) == 0 {
+			return nil, fmt.Errorf("%sno %s found that is not a pre-release, set allowPrerelease to resolve pre-release versions",
+				upgradeErrorPrefix, describePackage(ext))
+		}
+	}
+
+	for _, expression := range ext.Spec.SelectorExpressions {
+		matching, err := catalogfilter.MatchingSelectorExpression(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector expression %q: %w", expression, err)
+		}
+		resultSet = catalogfilter.Filter(resultSet, matching)
+		if len(resultSet) == 0 && ext.Spec.ResolvedBundleDigest != "" {
+			return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+		}
+		if len(resultSet) == 0 {
+			return nil, fmt.Errorf("%sno %s matching selector expression %q found", upgradeErrorPrefix, describePackage(ext), expression)
+		}
+	}
+
+	if len(ext.Spec.VersionBlocklist) > 0 {
+		notBlocked, err := notBlockedPredicate(ext)
+		if err != nil {
+			return nil, err
+		}
+		resultSet = catalogfilter.Filter(resultSet, notBlocked)
+		if len(resultSet) == 0 && ext.Spec.ResolvedBundleDigest != "" {
+			return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+		}
+		if len(resultSet) == 0 {
+			return nil, fmt.Errorf("%sno %s found outside versionBlocklist", upgradeErrorPrefix, describePackage(ext))
 		}
-		if channelName != "" {
-			return nil, fmt.Errorf("%sno package %q found in channel %q", upgradeErrorPrefix, packageName, channelName)
+	}
+
+	if digest := ext.Spec.ResolvedBundleDigest; digest != "" {
+		pinned := catalogfilter.Filter(resultSet, catalogfilter.WithBundleImageDigest(digest))
+		if len(pinned) == 0 {
+			return nil, pinnedBundleError(ext, allBundles, upgradeErrorPrefix)
+		}
+		resultSet = pinned
+	}
+
+	if lag := lagReleases(ext); lag > 0 {
+		releases, err := channelReleases(ext, allBundles)
+		if err != nil {
+			return nil, err
+		}
+		target := laggedReleaseTarget(releases, lag)
+		var installedVersion *bsemver.Version
+		if installedBundle != nil {
+			if installedVersion, err = installedBundle.Version(); err != nil {
+				return nil, err
+			}
+		}
+		// The installed version remains acceptable when it is newer than the target,
+		// so that raising lagReleases never causes a downgrade.
+		resultSet = catalogfilter.Filter(resultSet, catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+			return v.LTE(target) || (installedVersion != nil && v.EQ(*installedVersion))
+		}))
+		if len(resultSet) == 0 {
+			return nil, fmt.Errorf("%sno %s at or below version %q found, the target for lagReleases %d behind channel head %q",
+				upgradeErrorPrefix, describePackage(ext), target.String(), lag, releases[0].String())
 		}
-		return nil, fmt.Errorf("%sno package %q found", upgradeErrorPrefix, packageName)
 	}
+
+	// A catalog's priority, rather than the versions it provides, decides which of
+	// the catalogs providing the package wins.
+	resultSet = fromHighestPriorityCatalogs(resultSet)
+
+	// Order equally ranked bundles of different catalogs by catalog name, so that
+	// the selection does not depend on the order the catalogs were read in.
+	sort.SliceStable(resultSet, func(i, j int) bool {
+		return resultSet[i].CatalogName < resultSet[j].CatalogName
+	})
 	sort.SliceStable(resultSet, func(i, j int) bool {
 		return catalogsort.ByVersion(resultSet[i], resultSet[j])
 	})
@@ -255,7 +891,271 @@ func (r *ClusterExtensionReconciler) resolve(ctx context.Context, ext *ocv1alpha
 		return catalogsort.ByDeprecated(resultSet[i], resultSet[j])
 	})
 
-	return resultSet[0], nil
+	return resultSet, nil
+}
+
+// fromHighestPriorityCatalogs returns the bundles from the catalogs with the highest
+// priority among the catalogs of the bundles.
+func fromHighestPriorityCatalogs(bundles []*catalogmetadata.Bundle) []*catalogmetadata.Bundle {
+	if len(bundles) == 0 {
+		return bundles
+	}
+	highest := bundles[0].CatalogPriority()
+	for _, b := range bundles[1:] {
+		if p := b.CatalogPriority(); p > highest {
+			highest = p
+		}
+	}
+	return catalogfilter.Filter(bundles, func(b *catalogmetadata.Bundle) bool {
+		return b.CatalogPriority() == highest
+	})
+}
+
+// tiedCatalogs returns the names of the catalogs the candidates came from, sorted,
+// if there is more than one. The candidates all come from catalogs of the same
+// priority, so the selection among those catalogs was decided by version alone.
+func tiedCatalogs(candidates []*catalogmetadata.Bundle) []string {
+	names := sets.New[string]()
+	for _, b := range candidates {
+		names.Insert(b.CatalogName)
+	}
+	if names.Len() < 2 {
+		return nil
+	}
+	return sets.List(names)
+}
+
+// preferInstalledImage moves the candidate with the installed bundle's image to the
+// front of candidates if it ranks equally with the current first candidate, e.g. the
+// same version published with different digests by catalogs of equal priority. Its
+// image is already unpacked, so preferring it avoids a pull and a reinstall. It only
+// breaks ties and reports whether it changed the selection.
+func preferInstalledImage(candidates []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) bool {
+	if installedBundle == nil || len(candidates) < 2 || candidates[0].Image == installedBundle.Image {
+		return false
+	}
+	tied := func(a, b *catalogmetadata.Bundle) bool {
+		return !catalogsort.ByVersion(a, b) && !catalogsort.ByVersion(b, a) &&
+			!catalogsort.ByDeprecated(a, b) && !catalogsort.ByDeprecated(b, a)
+	}
+	for i := 1; i < len(candidates) && tied(candidates[0], candidates[i]); i++ {
+		if candidates[i].Image == installedBundle.Image {
+			preferred := candidates[i]
+			copy(candidates[1:i+1], candidates[:i])
+			candidates[0] = preferred
+			return true
+		}
+	}
+	return false
+}
+
+// ambiguousCatalogs returns the sorted names of the catalogs that provide a candidate
+// ranking equally with the first candidate but with a different image, including the
+// first candidate's catalog, unless the first candidate is the installed bundle. Such
+// candidates only come from catalogs of equal priority, and nothing but the order of
+// the catalogs would decide between them.
+func ambiguousCatalogs(candidates []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) []string {
+	if len(candidates) < 2 || (installedBundle != nil && candidates[0].Image == installedBundle.Image) {
+		return nil
+	}
+	first := candidates[0]
+	catalogs := sets.New[string]()
+	for _, b := range candidates[1:] {
+		if catalogsort.ByVersion(first, b) || catalogsort.ByDeprecated(first, b) {
+			break
+		}
+		if b.CatalogName != first.CatalogName && b.Image != first.Image {
+			catalogs.Insert(b.CatalogName)
+		}
+	}
+	if catalogs.Len() == 0 {
+		return nil
+	}
+	return sets.List(catalogs.Insert(first.CatalogName))
+}
+
+// ambiguousResolutionError reports that catalogs of equal priority provide different
+// bundles for the version that would be resolved.
+func ambiguousResolutionError(ext *ocv1alpha1.ClusterExtension, first *catalogmetadata.Bundle, catalogs []string) error {
+	quoted := make([]string, 0, len(catalogs))
+	for _, c := range catalogs {
+		quoted = append(quoted, fmt.Sprintf("%q", c))
+	}
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonResolutionAmbiguous,
+		err: fmt.Errorf("catalogs %s of equal priority provide different content for version %s of %s",
+			strings.Join(quoted, ", "), bundleMetadataFor(first).Version, describePackage(ext)),
+	}
+}
+
+// pinnedBundleError explains why the bundle pinned by spec.resolvedBundleDigest
+// is not among the bundles that satisfy the ClusterExtension's constraints.
+func pinnedBundleError(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, upgradeErrorPrefix string) error {
+	digest := ext.Spec.ResolvedBundleDigest
+	if len(catalogfilter.Filter(allBundles, catalogfilter.And(packagePredicate(ext), catalogfilter.WithBundleImageDigest(digest)))) == 0 {
+		return fmt.Errorf("no bundle with image digest %q found for %s", digest, describePackage(ext))
+	}
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonPinnedBundleMismatch,
+		err:    fmt.Errorf("%sbundle with image digest %q for %s does not satisfy the ClusterExtension's constraints", upgradeErrorPrefix, digest, describePackage(ext)),
+	}
+}
+
+// catalogSelector returns the selector for the catalogs to resolve from:
+// spec.catalogSelector if it is set, and the default selector otherwise. A nil
+// default selector considers every catalog.
+func catalogSelector(ext *ocv1alpha1.ClusterExtension, defaultSelector labels.Selector) (labels.Selector, error) {
+	if ext.Spec.CatalogSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ext.Spec.CatalogSelector)
+		if err != nil {
+			return nil, &resolutionError{
+				reason: ocv1alpha1.ReasonInvalidSpec,
+				err:    fmt.Errorf("invalid catalog selector: %w", err),
+			}
+		}
+		return selector, nil
+	}
+	if defaultSelector == nil {
+		return labels.Everything(), nil
+	}
+	return defaultSelector, nil
+}
+
+// catalogOverride records that a bundle was replaced by a bundle of the
+// same name from a higher priority catalog.
+type catalogOverride struct {
+	*catalogmetadata.Bundle
+	overriddenBy *catalogmetadata.Bundle
+}
+
+// applyCatalogOverlays returns the bundles left once every bundle that has the
+// same package and name as a bundle from a higher priority catalog is removed,
+// and the bundles that were removed. Bundles from catalogs of equal priority
+// all remain and compete as separate candidates.
+func applyCatalogOverlays(bundles []*catalogmetadata.Bundle) ([]*catalogmetadata.Bundle, []*catalogOverride) {
+	type bundleKey struct{ pkg, name string }
+	winners := map[bundleKey]*catalogmetadata.Bundle{}
+	for _, b := range bundles {
+		key := bundleKey{b.Package, b.Name}
+		if w, ok := winners[key]; !ok || b.CatalogPriority() > w.CatalogPriority() {
+			winners[key] = b
+		}
+	}
+
+	var kept []*catalogmetadata.Bundle
+	var overrides []*catalogOverride
+	for _, b := range bundles {
+		w := winners[bundleKey{b.Package, b.Name}]
+		if b.CatalogPriority() < w.CatalogPriority() {
+			overrides = append(overrides, &catalogOverride{Bundle: b, overriddenBy: w})
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept, overrides
+}
+
+// catalogResolutionStatuses reports, for every catalog considered during resolution,
+// how many candidates it provided, which bundles it overrode, and whether the
+// selected bundle came from it.
+func catalogResolutionStatuses(catalogBundles []*catalogmetadata.Bundle, overrides []*catalogOverride, candidates []*catalogmetadata.Bundle, selected *catalogmetadata.Bundle) []ocv1alpha1.CatalogResolutionStatus {
+	statuses := map[string]*ocv1alpha1.CatalogResolutionStatus{}
+	addCatalog := func(b *catalogmetadata.Bundle) *ocv1alpha1.CatalogResolutionStatus {
+		status, ok := statuses[b.CatalogName]
+		if !ok {
+			status = &ocv1alpha1.CatalogResolutionStatus{Name: b.CatalogName, Priority: int32(b.CatalogPriority()), ResolvedRef: b.CatalogResolvedRef}
+			statuses[b.CatalogName] = status
+		}
+		return status
+	}
+	for _, b := range catalogBundles {
+		addCatalog(b)
+	}
+	for _, o := range overrides {
+		addCatalog(o.Bundle)
+		status := addCatalog(o.overriddenBy)
+		status.OverriddenBundles = append(status.OverriddenBundles, ocv1alpha1.OverriddenBundle{Name: o.Name, Catalog: o.CatalogName})
+	}
+	for _, b := range candidates {
+		if status, ok := statuses[b.CatalogName]; ok {
+			status.Candidates++
+		}
+	}
+	if selected != nil {
+		if status, ok := statuses[selected.CatalogName]; ok {
+			status.Selected = true
+		}
+	}
+
+	result := make([]ocv1alpha1.CatalogResolutionStatus, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// candidateStatuses describes the first limit candidates for status.resolution.candidates.
+func candidateStatuses(candidates []*catalogmetadata.Bundle, limit int) ([]ocv1alpha1.ResolutionCandidate, error) {
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	var result []ocv1alpha1.ResolutionCandidate
+	for _, b := range candidates {
+		version, err := b.Version()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ocv1alpha1.ResolutionCandidate{
+			Name:       b.Name,
+			Version:    version.String(),
+			Catalog:    b.CatalogName,
+			Deprecated: b.IsDeprecated(),
+		})
+	}
+	return result, nil
+}
+
+// selectedBundleStatus describes the selected bundle, or returns nil if no bundle was selected.
+func selectedBundleStatus(selected *catalogmetadata.Bundle) *ocv1alpha1.SelectedBundle {
+	if selected == nil {
+		return nil
+	}
+	tag, digest := imageTagAndDigest(selected.Image)
+	return &ocv1alpha1.SelectedBundle{
+		Bundle:   *bundleMetadataFor(selected),
+		Package:  selected.Package,
+		Channels: bundleChannelNames(selected),
+		Catalog:  selected.CatalogName,
+		Image:    selected.Image,
+		Tag:      tag,
+		Digest:   digest,
+	}
+}
+
+// bundleChannelNames returns the sorted names of the channels the bundle is published in.
+func bundleChannelNames(bundle *catalogmetadata.Bundle) []string {
+	var channels []string
+	for _, channel := range bundle.InChannels {
+		channels = append(channels, channel.Name)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// imageTagAndDigest returns the tag and the digest of an image reference, each of
+// which is empty if the reference does not have one.
+func imageTagAndDigest(ref string) (string, string) {
+	var tag, digest string
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		ref, digest = ref[:i], ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		tag = ref[i+1:]
+	}
+	return tag, digest
 }
 
 func (r *ClusterExtensionReconciler) installedBundle(ctx context.Context, allBundles []*catalogmetadata.Bundle, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, error) {
@@ -273,11 +1173,11 @@ func (r *ClusterExtensionReconciler) installedBundle(ctx context.Context, allBun
 	bundleImage := bd.Spec.Source.Image.Ref
 	// find corresponding bundle for the installed content
 	resultSet := catalogfilter.Filter(allBundles, catalogfilter.And(
-		catalogfilter.WithPackageName(ext.Spec.PackageName),
+		packagePredicate(ext),
 		catalogfilter.WithBundleImage(bundleImage),
 	))
 	if len(resultSet) == 0 {
-		return nil, fmt.Errorf("bundle with image %q for package %q not found in available catalogs but is currently installed via BundleDeployment %q", bundleImage, ext.Spec.PackageName, bd.Name)
+		return nil, fmt.Errorf("bundle with image %q for %s not found in available catalogs but is currently installed via BundleDeployment %q", bundleImage, describePackage(ext), bd.Name)
 	}
 
 	sort.SliceStable(resultSet, func(i, j int) bool {
@@ -287,23 +1187,177 @@ func (r *ClusterExtensionReconciler) installedBundle(ctx context.Context, allBun
 	return resultSet[0], nil
 }
 
-func (r *ClusterExtensionReconciler) validateBundle(bundle *catalogmetadata.Bundle) error {
-	unsupportedProps := sets.New(
-		property.TypePackageRequired,
-		property.TypeGVKRequired,
-		property.TypeConstraint,
-	)
-	for i := range bundle.Properties {
-		if unsupportedProps.Has(bundle.Properties[i].Type) {
-			return fmt.Errorf(
-				"bundle %q has a dependency declared via property %q which is currently not supported",
-				bundle.Name,
-				bundle.Properties[i].Type,
-			)
+// resolutionError is a resolution failure that is reported on the
+// Resolved condition with a reason more specific than ResolutionFailed.
+type resolutionError struct {
+	reason string
+	err    error
+}
+
+func (e *resolutionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolutionError) Unwrap() error {
+	return e.err
+}
+
+// clusterError is an error reading the catalogs or the cluster, rather than a failure
+// to resolve the constraints.
+type clusterError struct {
+	err error
+}
+
+func (e clusterError) Error() string {
+	return e.err.Error()
+}
+
+func (e clusterError) Unwrap() error {
+	return e.err
+}
+
+// resolutionFailureReason returns the Resolved condition reason for a resolution error.
+func resolutionFailureReason(err error) string {
+	var resErr *resolutionError
+	if errors.As(err, &resErr) {
+		return resErr.reason
+	}
+	return ocv1alpha1.ReasonResolutionFailed
+}
+
+// packagePredicate returns a predicate matching the bundles of the package (or,
+// when spec.providedAPI is set, the packages) that the ClusterExtension selects.
+func packagePredicate(ext *ocv1alpha1.ClusterExtension) catalogfilter.Predicate[catalogmetadata.Bundle] {
+	if api := ext.Spec.ProvidedAPI; api != nil {
+		return catalogfilter.ProvidingAPI(api.Group, api.Kind)
+	}
+	return catalogfilter.WithPackageName(ext.Spec.PackageName)
+}
+
+// describeBundleSource returns a human readable description of where the
+// resolved bundle's content comes from.
+func describeBundleSource(ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) string {
+	if ref := ext.Spec.ConfigMapBundle; ref != nil {
+		return fmt.Sprintf("ConfigMap %q", ref.Name)
+	}
+	return fmt.Sprintf("%q", bundle.Image)
+}
+
+// specChannels returns the channels the ClusterExtension selects bundles from, or nil
+// if it considers every channel.
+func specChannels(ext *ocv1alpha1.ClusterExtension) []string {
+	if ext.Spec.Channel != "" {
+		return []string{ext.Spec.Channel}
+	}
+	channels := make([]string, 0, len(ext.Spec.Channels))
+	for _, channel := range ext.Spec.Channels {
+		channels = append(channels, string(channel))
+	}
+	return channels
+}
+
+// channelPredicate returns a predicate that selects bundles in any of the channels.
+func channelPredicate(channels []string) catalogfilter.Predicate[catalogmetadata.Bundle] {
+	predicates := make([]catalogfilter.Predicate[catalogmetadata.Bundle], 0, len(channels))
+	for _, channel := range channels {
+		predicates = append(predicates, catalogfilter.InChannel(channel))
+	}
+	return catalogfilter.Or(predicates...)
+}
+
+// describeChannels returns a human readable description of the channels, for
+// use in error messages.
+func describeChannels(channels []string) string {
+	if len(channels) == 1 {
+		return fmt.Sprintf("channel %q", channels[0])
+	}
+	quoted := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		quoted = append(quoted, fmt.Sprintf("%q", channel))
+	}
+	return "channels " + strings.Join(quoted, ", ")
+}
+
+// describePackage returns a human readable description of the package
+// selected by the ClusterExtension, for use in error messages.
+func describePackage(ext *ocv1alpha1.ClusterExtension) string {
+	if api := ext.Spec.ProvidedAPI; api != nil {
+		return fmt.Sprintf("package providing API %q", api.Kind+"."+api.Group)
+	}
+	return fmt.Sprintf("package %q", ext.Spec.PackageName)
+}
+
+// preflightChecks returns the built-in preflight checks followed by the registered
+// ones. The built-in checks consult otherInstalled, the bundles installed by the
+// other ClusterExtensions as resolution looked them up, if it is not nil.
+func (r *ClusterExtensionReconciler) preflightChecks(otherInstalled map[string]*catalogmetadata.Bundle) []PreflightCheck {
+	builtin := []PreflightCheck{
+		{Name: "RequiredDependencies", Run: func(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) error {
+			return r.checkRequiredDependencies(ctx, ext, bundle, otherInstalled)
+		}},
+		{Name: "CRDOwnership", Run: func(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) error {
+			return r.checkCRDOwnership(ctx, ext, bundle, otherInstalled)
+		}},
+	}
+	return append(builtin, r.PreflightChecks...)
+}
+
+// runPreflightChecks runs every preflight check against the bundle and records the
+// results in the ClusterExtension's status. It returns the first failure unless the
+// ClusterExtension's preflight mode is Warn.
+func (r *ClusterExtensionReconciler) runPreflightChecks(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, otherInstalled map[string]*catalogmetadata.Bundle) error {
+	l := log.FromContext(ctx)
+	warnOnly := ext.Spec.Preflight != nil && ext.Spec.Preflight.Mode == ocv1alpha1.PreflightModeWarn
+
+	var firstErr error
+	ext.Status.PreflightChecks = nil
+	for _, check := range r.preflightChecks(otherInstalled) {
+		result := ocv1alpha1.PreflightCheckStatus{Name: check.Name, ConditionType: check.ConditionType, Passed: true}
+		if err := check.Run(ctx, ext, bundle); err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+			if firstErr == nil {
+				firstErr = err
+			}
+			if warnOnly {
+				l.Info("ignoring failed preflight check", "check", check.Name, "bundle", bundle.Name, "reason", err.Error())
+			}
 		}
+		ext.Status.PreflightChecks = append(ext.Status.PreflightChecks, result)
 	}
 
-	return nil
+	if warnOnly {
+		return nil
+	}
+	return firstErr
+}
+
+// checkRequiredDependencies returns an error if the bundle declares a package or API
+// dependency that is not installed.
+func (r *ClusterExtensionReconciler) checkRequiredDependencies(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, otherInstalled map[string]*catalogmetadata.Bundle) error {
+	if err := r.checkRequiredPackages(ctx, ext, bundle); err != nil {
+		return err
+	}
+	return r.checkRequiredGVKs(ctx, ext, bundle, otherInstalled)
+}
+
+// installedBundleMetadataFor describes the bundle installed by the BundleDeployment,
+// keeping the install time recorded in previous if the same image is still installed.
+func installedBundleMetadataFor(previous *ocv1alpha1.InstalledBundleMetadata, bd *rukpakv1alpha2.BundleDeployment, bundle *catalogmetadata.Bundle) *ocv1alpha1.InstalledBundleMetadata {
+	installed := &ocv1alpha1.InstalledBundleMetadata{BundleMetadata: *bundleMetadataFor(bundle)}
+	// rukpak reports the digest it resolved the image reference to in the resolved source.
+	if resolved := bd.Status.ResolvedSource; resolved != nil && resolved.Image != nil {
+		_, installed.Digest = imageTagAndDigest(resolved.Image.Ref)
+	} else if bd.Spec.Source.Image != nil {
+		_, installed.Digest = imageTagAndDigest(bd.Spec.Source.Image.Ref)
+	}
+	if previous != nil && previous.LastInstalled != nil && previous.BundleMetadata == installed.BundleMetadata && previous.Digest == installed.Digest {
+		installed.LastInstalled = previous.LastInstalled
+	} else {
+		now := metav1.Now()
+		installed.LastInstalled = &now
+	}
+	return installed
 }
 
 func mapBDStatusToInstalledCondition(existingTypedBundleDeployment *rukpakv1alpha2.BundleDeployment, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) {
@@ -314,6 +1368,14 @@ func mapBDStatusToInstalledCondition(existingTypedBundleDeployment *rukpakv1alph
 		return
 	}
 
+	// rukpak has not yet reconciled the latest BundleDeployment spec, so its
+	// status may describe a previous bundle.
+	if observed := existingTypedBundleDeployment.Status.ObservedGeneration; observed != 0 && observed != existingTypedBundleDeployment.Generation {
+		ext.Status.InstalledBundle = nil
+		setInstalledStatusConditionUnknown(&ext.Status.Conditions, "bundledeployment status is out of date", ext.GetGeneration())
+		return
+	}
+
 	if bundleDeploymentReady.Status != metav1.ConditionTrue {
 		ext.Status.InstalledBundle = nil
 		setInstalledStatusConditionFailed(
@@ -324,7 +1386,7 @@ func mapBDStatusToInstalledCondition(existingTypedBundleDeployment *rukpakv1alph
 		return
 	}
 
-	installedBundle := bundleMetadataFor(bundle)
+	installedBundle := installedBundleMetadataFor(ext.Status.InstalledBundle, existingTypedBundleDeployment, bundle)
 	bundleDeploymentSource := existingTypedBundleDeployment.Spec.Source
 	switch bundleDeploymentSource.Type {
 	case rukpakv1alpha2.SourceTypeImage:
@@ -334,6 +1396,17 @@ func mapBDStatusToInstalledCondition(existingTypedBundleDeployment *rukpakv1alph
 			fmt.Sprintf("installed from %q", bundleDeploymentSource.Image.Ref),
 			ext.GetGeneration(),
 		)
+	case rukpakv1alpha2.SourceTypeConfigMaps:
+		ext.Status.InstalledBundle = installedBundle
+		names := make([]string, 0, len(bundleDeploymentSource.ConfigMaps))
+		for _, cm := range bundleDeploymentSource.ConfigMaps {
+			names = append(names, cm.ConfigMap.Name)
+		}
+		setInstalledStatusConditionSuccess(
+			&ext.Status.Conditions,
+			fmt.Sprintf("installed from ConfigMaps %q", strings.Join(names, ",")),
+			ext.GetGeneration(),
+		)
 	case rukpakv1alpha2.SourceTypeGit:
 		ext.Status.InstalledBundle = installedBundle
 		resource := bundleDeploymentSource.Git.Repository + "@" + bundleDeploymentSource.Git.Ref.Commit
@@ -372,16 +1445,8 @@ func SetDeprecationStatus(ext *ocv1alpha1.ClusterExtension, bundle *catalogmetad
 		})
 	}
 
-	// There are two early return scenarios here:
-	// 1) The bundle is not deprecated (i.e bundle deprecations)
-	// AND there are no other deprecations associated with the bundle
-	// 2) The bundle is not deprecated, there are deprecations associated
-	// with the bundle (i.e at least one channel the bundle is present in is deprecated OR whole package is deprecated),
-	// and the ClusterExtension does not specify a channel. This is because the channel deprecations
-	// are a loose deprecation coupling on the bundle. A ClusterExtension installation is only
-	// considered deprecated by a channel deprecation when a deprecated channel is specified via
-	// the spec.channel field.
-	if (!bundle.IsDeprecated() && !bundle.HasDeprecation()) || (!bundle.IsDeprecated() && ext.Spec.Channel == "") {
+	// The bundle is not deprecated and there are no other deprecations associated with it.
+	if !bundle.IsDeprecated() && !bundle.HasDeprecation() {
 		return
 	}
 
@@ -398,7 +1463,14 @@ func SetDeprecationStatus(ext *ocv1alpha1.ClusterExtension, bundle *catalogmetad
 				ObservedGeneration: ext.Generation,
 			})
 		case declcfg.SchemaChannel:
-			if ext.Spec.Channel != deprecation.Reference.Name {
+			// Channel deprecations are a loose deprecation coupling on the bundle. A
+			// ClusterExtension installation is only considered deprecated by a channel
+			// deprecation when the deprecated channel is specified via the spec.channel
+			// or spec.channels field, or, when no channel is specified, when every
+			// channel the bundle is published in is deprecated, leaving no channel to
+			// upgrade along.
+			if channels := specChannels(ext); !slices.Contains(channels, deprecation.Reference.Name) &&
+				(len(channels) > 0 || !bundle.InDeprecatedChannels()) {
 				continue
 			}
 
@@ -433,23 +1505,36 @@ func SetDeprecationStatus(ext *ocv1alpha1.ClusterExtension, bundle *catalogmetad
 	}
 }
 
-func (r *ClusterExtensionReconciler) GenerateExpectedBundleDeployment(o ocv1alpha1.ClusterExtension, bundlePath string, bundleProvisioner string) *unstructured.Unstructured {
+func (r *ClusterExtensionReconciler) GenerateExpectedBundleDeployment(o ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, bundleProvisioner string) *unstructured.Unstructured {
 	// We use unstructured here to avoid problems of serializing default values when sending patches to the apiserver.
 	// If you use a typed object, any default values from that struct get serialized into the JSON patch, which could
 	// cause unrelated fields to be patched back to the default value even though that isn't the intention. Using an
 	// unstructured ensures that the patch contains only what is specified. Using unstructured like this is basically
 	// identical to "kubectl apply -f"
 
+	source := map[string]interface{}{
+		"type": string(rukpakv1alpha2.SourceTypeImage),
+		"image": map[string]interface{}{
+			"ref": bundle.Image,
+		},
+	}
+	if ref := o.Spec.ConfigMapBundle; ref != nil {
+		source = map[string]interface{}{
+			"type": string(rukpakv1alpha2.SourceTypeConfigMaps),
+			"configMaps": []interface{}{
+				map[string]interface{}{
+					"configMap": map[string]interface{}{
+						"name": ref.Name,
+					},
+				},
+			},
+		}
+	}
+
 	spec := map[string]interface{}{
 		// TODO: Don't assume plain provisioner
 		"provisionerClassName": bundleProvisioner,
-		"source": map[string]interface{}{
-			// TODO: Don't assume image type
-			"type": string(rukpakv1alpha2.SourceTypeImage),
-			"image": map[string]interface{}{
-				"ref": bundlePath,
-			},
-		},
+		"source":               source,
 	}
 
 	if len(o.Spec.WatchNamespaces) > 0 {
@@ -461,6 +1546,13 @@ func (r *ClusterExtensionReconciler) GenerateExpectedBundleDeployment(o ocv1alph
 		"kind":       rukpakv1alpha2.BundleDeploymentKind,
 		"metadata": map[string]interface{}{
 			"name": o.GetName(),
+			// Record the origin of the installed content so it can be
+			// traced back to the catalog and bundle it was resolved from.
+			"annotations": map[string]interface{}{
+				catalogNameKey:   bundle.CatalogName,
+				bundleNameKey:    bundle.Name,
+				bundleVersionKey: bundleMetadataFor(bundle).Version,
+			},
 		},
 		"spec": spec,
 	}}
@@ -479,11 +1571,29 @@ func (r *ClusterExtensionReconciler) GenerateExpectedBundleDeployment(o ocv1alph
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ClusterExtensionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ValidatePreflightChecks(r.PreflightChecks); err != nil {
+		return err
+	}
 	err := ctrl.NewControllerManagedBy(mgr).
-		For(&ocv1alpha1.ClusterExtension{}).
+		For(&ocv1alpha1.ClusterExtension{}, builder.WithPredicates(ignoreRetryStatusUpdates())).
 		Watches(&catalogd.Catalog{},
-			handler.EnqueueRequestsFromMapFunc(clusterExtensionRequestsForCatalog(mgr.GetClient(), mgr.GetLogger()))).
+			handler.EnqueueRequestsFromMapFunc(r.clusterExtensionRequestsForCatalog(mgr.GetLogger())),
+			builder.WithPredicates(catalogContentChanged())).
+		Watches(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(clusterExtensionRequestsForConfigMap(mgr.GetClient(), r.BundleConfigMapNamespace, mgr.GetLogger()))).
+		Watches(&apiextensionsv1.CustomResourceDefinition{},
+			handler.EnqueueRequestsFromMapFunc(clusterExtensionRequestsForBundleObject)).
+		Watches(&appsv1.Deployment{},
+			handler.EnqueueRequestsFromMapFunc(clusterExtensionRequestsForBundleObject)).
+		Watches(newAPIService(),
+			handler.EnqueueRequestsFromMapFunc(clusterExtensionRequestsForBundleObject)).
+		Watches(&ocv1alpha1.ClusterExtension{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &ocv1alpha1.ClusterExtension{})).
 		Owns(&rukpakv1alpha2.BundleDeployment{}).
+		Owns(&ocv1alpha1.ClusterExtensionRevision{}).
+		WithOptions(controller.Options{
+			RateLimiter: newFailureClassRateLimiter(&r.failureClasses, r.BackoffPolicies),
+		}).
 		Complete(r)
 
 	if err != nil {
@@ -492,6 +1602,27 @@ func (r *ClusterExtensionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
+// CacheOptions returns the manager cache options for the objects the
+// ClusterExtension controller watches but does not own. Deployments,
+// CustomResourceDefinitions and APIServices are only cached if rukpak installed
+// them for a BundleDeployment, and ConfigMaps are only cached in the namespace
+// holding the ConfigMaps referenced by spec.configMapBundle.
+func CacheOptions(bundleConfigMapNamespace string) cache.Options {
+	ownedByBundleDeployment := cache.ByObject{
+		Label: labels.SelectorFromSet(labels.Set{rukpakOwnerKindKey: rukpakv1alpha2.BundleDeploymentKind}),
+	}
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&appsv1.Deployment{}:                        ownedByBundleDeployment,
+			&apiextensionsv1.CustomResourceDefinition{}: ownedByBundleDeployment,
+			newAPIService():                             ownedByBundleDeployment,
+			&corev1.ConfigMap{}: {
+				Namespaces: map[string]cache.Config{bundleConfigMapNamespace: {}},
+			},
+		},
+	}
+}
+
 func (r *ClusterExtensionReconciler) ensureBundleDeployment(ctx context.Context, desiredBundleDeployment *unstructured.Unstructured) error {
 	// TODO: what if there happens to be an unrelated BD with the same name as the ClusterExtension?
 	//   we should probably also check to see if there's an owner reference and/or a label set
@@ -513,6 +1644,16 @@ func (r *ClusterExtensionReconciler) ensureBundleDeployment(ctx context.Context,
 	return r.Client.Patch(ctx, desiredBundleDeployment, client.Apply, client.ForceOwnership, client.FieldOwner("operator-controller"))
 }
 
+// bundleDeploymentSourceType returns the source type of the existing BundleDeployment
+// with the given name, or "" if there is none.
+func (r *ClusterExtensionReconciler) bundleDeploymentSourceType(ctx context.Context, name string) (rukpakv1alpha2.SourceType, error) {
+	existingBundleDeployment := &rukpakv1alpha2.BundleDeployment{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name}, existingBundleDeployment); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return existingBundleDeployment.Spec.Source.Type, nil
+}
+
 func (r *ClusterExtensionReconciler) existingBundleDeploymentUnstructured(ctx context.Context, name string) (*unstructured.Unstructured, error) {
 	existingBundleDeployment := &rukpakv1alpha2.BundleDeployment{}
 	err := r.Client.Get(ctx, types.NamespacedName{Name: name}, existingBundleDeployment)
@@ -545,18 +1686,16 @@ func mapBundleMediaTypeToBundleProvisioner(mediaType string) (string, error) {
 	}
 }
 
-// Generate reconcile requests for all cluster extensions affected by a catalog change
-func clusterExtensionRequestsForCatalog(c client.Reader, logger logr.Logger) handler.MapFunc {
-	return func(ctx context.Context, _ client.Object) []reconcile.Request {
-		// no way of associating an extension to a catalog so create reconcile requests for everything
-		clusterExtensions := ocv1alpha1.ClusterExtensionList{}
-		err := c.List(ctx, &clusterExtensions)
+// Generate reconcile requests for the cluster extensions affected by a catalog change
+func (r *ClusterExtensionReconciler) clusterExtensionRequestsForCatalog(logger logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		clusterExtensions, err := r.ExtensionsAffectedByCatalog(ctx, obj.GetName())
 		if err != nil {
 			logger.Error(err, "unable to enqueue cluster extensions for catalog reconcile")
 			return nil
 		}
 		var requests []reconcile.Request
-		for _, ext := range clusterExtensions.Items {
+		for _, ext := range clusterExtensions {
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Namespace: ext.GetNamespace(),
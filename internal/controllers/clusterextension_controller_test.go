@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -13,6 +14,7 @@ import (
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -30,6 +32,7 @@ import (
 	"github.com/operator-framework/operator-controller/internal/conditionsets"
 	"github.com/operator-framework/operator-controller/internal/controllers"
 	"github.com/operator-framework/operator-controller/pkg/features"
+	testutil "github.com/operator-framework/operator-controller/test/util"
 )
 
 // Describe: ClusterExtension Controller Test
@@ -289,6 +292,11 @@ func TestClusterExtensionBundleDeploymentUpToDate(t *testing.T) {
 	bd := &rukpakv1alpha2.BundleDeployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: extKey.Name,
+			Annotations: map[string]string{
+				"olm.operatorframework.io/catalogName":   "fake-catalog",
+				"olm.operatorframework.io/bundleName":    "operatorhub/prometheus/beta/2.0.0",
+				"olm.operatorframework.io/bundleVersion": "2.0.0",
+			},
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion:         ocv1alpha1.GroupVersion.String(),
@@ -440,7 +448,8 @@ func TestClusterExtensionBundleDeploymentUpToDate(t *testing.T) {
 
 	t.Log("By checking the status fields")
 	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, ext.Status.ResolvedBundle)
-	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, ext.Status.InstalledBundle)
+	require.NotNil(t, ext.Status.InstalledBundle)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, ext.Status.InstalledBundle.BundleMetadata)
 
 	t.Log("By checking the expected conditions")
 	cond = apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
@@ -724,6 +733,143 @@ func TestClusterExtensionChannelExistsNoVersion(t *testing.T) {
 	require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
 }
 
+func TestClusterExtensionPaused(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "1.0.0", Channel: "beta"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) *rukpakv1alpha2.BundleDeployment {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		mutate(&clusterExtension.Spec)
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		return bd
+	}
+
+	t.Log("It installs the bundle while the extension is not paused")
+	bd := reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.False(t, clusterExtension.Status.Paused)
+
+	t.Log("It leaves the resolution and the BundleDeployment unchanged while the extension is paused")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.Paused = true
+		spec.Version = "1.0.1"
+	})
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, clusterExtension.Status.ResolvedBundle)
+	require.True(t, clusterExtension.Status.Paused)
+
+	t.Log("It reconciles the spec once the extension is unpaused")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Paused = false })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.1", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.1", Version: "1.0.1"}, clusterExtension.Status.ResolvedBundle)
+	require.False(t, clusterExtension.Status.Paused)
+	verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+}
+
+func TestClusterExtensionMultipleChannels(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	stable := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.0.0"},
+			{Name: "widgets.v1.1.0", Replaces: "widgets.v1.0.0"},
+		},
+	}}
+	fast := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "fast",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.1.0"},
+			{Name: "widgets.v2.0.0", Replaces: "widgets.v1.1.0"},
+		},
+	}}
+	bundle := func(version string, channels ...*catalogmetadata.Channel) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.InChannels = channels
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0", &stable),
+		bundle("1.1.0", &stable, &fast),
+		bundle("2.0.0", &fast),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	for _, tt := range []struct {
+		name       string
+		channels   []ocv1alpha1.ChannelName
+		version    string
+		wantBundle *ocv1alpha1.BundleMetadata
+		wantErr    string
+	}{
+		{
+			name:       "a single channel",
+			channels:   []ocv1alpha1.ChannelName{"stable"},
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"},
+		},
+		{
+			name:       "the union of several channels",
+			channels:   []ocv1alpha1.ChannelName{"stable", "fast"},
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"},
+		},
+		{
+			name:       "a version only in one of the channels",
+			channels:   []ocv1alpha1.ChannelName{"stable", "fast"},
+			version:    "1.0.0",
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"},
+		},
+		{
+			name:     "a version in none of the channels",
+			channels: []ocv1alpha1.ChannelName{"stable", "fast"},
+			version:  "3.0.0",
+			wantErr:  `no package "widgets" matching version "3.0.0" found in channels "stable", "fast"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					PackageName: "widgets",
+					Channels:    tt.channels,
+					Version:     tt.version,
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			require.Equal(t, tt.wantBundle, clusterExtension.Status.ResolvedBundle)
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
 func TestClusterExtensionVersionNoChannel(t *testing.T) {
 	cl, reconciler := newClientAndReconciler(t)
 	ctx := context.Background()
@@ -981,6 +1127,639 @@ func TestClusterExtensionBadBundleMediaType(t *testing.T) {
 	require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
 }
 
+func TestClusterExtensionProvidedAPI(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	t.Run("resolves the package providing the API", func(t *testing.T) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		clusterExtension := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec: ocv1alpha1.ClusterExtensionSpec{
+				ProvidedAPI: &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "Widget"},
+			},
+		}
+		require.NoError(t, cl.Create(ctx, clusterExtension))
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/widgets/1.1.0", Version: "1.1.0"}, clusterExtension.Status.ResolvedBundle)
+		require.Equal(t, "widgets", clusterExtension.Status.ResolvedPackageName)
+		require.Equal(t, &ocv1alpha1.ResolutionStatus{
+			Selected: &ocv1alpha1.SelectedBundle{
+				Bundle:   ocv1alpha1.BundleMetadata{Name: "operatorhub/widgets/1.1.0", Version: "1.1.0"},
+				Package:  "widgets",
+				Channels: []string{"stable"},
+				Catalog:  "fake-catalog",
+				Image:    "quay.io/operatorhub/widgets@fake1.1.0",
+				Digest:   "fake1.1.0",
+			},
+			Catalogs: []ocv1alpha1.CatalogResolutionStatus{
+				{Name: "certified-catalog", Candidates: 1},
+				{Name: "fake-catalog", Candidates: 1, Selected: true},
+			},
+			TiedCatalogs: []string{"certified-catalog", "fake-catalog"},
+		}, clusterExtension.Status.Resolution)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionTrue, cond.Status)
+		require.Equal(t, ocv1alpha1.ReasonSuccess, cond.Reason)
+		require.Equal(t, `resolved to "quay.io/operatorhub/widgets@fake1.1.0"`, cond.Message)
+
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	})
+
+	t.Run("fails when no package provides the API", func(t *testing.T) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		clusterExtension := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec: ocv1alpha1.ClusterExtensionSpec{
+				ProvidedAPI: &ocv1alpha1.ProvidedAPI{Group: "example.com", Kind: "Gadget"},
+			},
+		}
+		require.NoError(t, cl.Create(ctx, clusterExtension))
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.EqualError(t, err, `no package providing API "Gadget.example.com" found`)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Empty(t, clusterExtension.Status.ResolvedBundle)
+		require.Empty(t, clusterExtension.Status.ResolvedPackageName)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, ocv1alpha1.ReasonResolutionFailed, cond.Reason)
+
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	})
+}
+
+func TestClusterExtensionDefaultCatalogSelector(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.DefaultCatalogSelector = labels.SelectorFromSet(labels.Set{"tier": "certified"})
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	t.Run("resolves only from catalogs matching the selector", func(t *testing.T) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		clusterExtension := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"},
+		}
+		require.NoError(t, cl.Create(ctx, clusterExtension))
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/widgets/1.0.0", Version: "1.0.0"}, clusterExtension.Status.ResolvedBundle)
+		require.Equal(t, &ocv1alpha1.ResolutionStatus{
+			Selected: &ocv1alpha1.SelectedBundle{
+				Bundle:   ocv1alpha1.BundleMetadata{Name: "operatorhub/widgets/1.0.0", Version: "1.0.0"},
+				Package:  "widgets",
+				Channels: []string{"stable"},
+				Catalog:  "certified-catalog",
+				Image:    "quay.io/operatorhub/widgets@fake1.0.0",
+				Digest:   "fake1.0.0",
+			},
+			Catalogs: []ocv1alpha1.CatalogResolutionStatus{
+				{Name: "certified-catalog", Candidates: 1, Selected: true},
+			},
+			CatalogSelector: "tier=certified",
+		}, clusterExtension.Status.Resolution)
+
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	})
+
+	t.Run("fails when the package is only in catalogs not matching the selector", func(t *testing.T) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		clusterExtension := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+		}
+		require.NoError(t, cl.Create(ctx, clusterExtension))
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.EqualError(t, err, `no package "prometheus" found`)
+
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Equal(t, &ocv1alpha1.ResolutionStatus{
+			Catalogs: []ocv1alpha1.CatalogResolutionStatus{
+				{Name: "certified-catalog"},
+			},
+			CatalogSelector: "tier=certified",
+			Explanation: &ocv1alpha1.ResolutionExplanation{
+				Constraints: []string{`package "prometheus"`, "not a pre-release"},
+			},
+		}, clusterExtension.Status.Resolution)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, ocv1alpha1.ReasonResolutionFailed, cond.Reason)
+
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	})
+}
+
+func TestClusterExtensionCatalogSelector(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.DefaultCatalogSelector = labels.SelectorFromSet(labels.Set{"tier": "certified"})
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	for _, tt := range []struct {
+		name       string
+		pkg        string
+		selector   *metav1.LabelSelector
+		wantBundle *ocv1alpha1.BundleMetadata
+		wantErr    string
+		wantReason string
+	}{
+		{
+			name:       "overrides the default selector",
+			pkg:        "prometheus",
+			selector:   &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist}}},
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"},
+		},
+		{
+			name:       "considers every catalog when empty",
+			pkg:        "prometheus",
+			selector:   &metav1.LabelSelector{},
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"},
+		},
+		{
+			name:       "excludes catalogs not matching the selector",
+			pkg:        "widgets",
+			selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "community"}},
+			wantErr:    `no package "widgets" found`,
+			wantReason: ocv1alpha1.ReasonResolutionFailed,
+		},
+		{
+			name:       "reports an invalid selector",
+			pkg:        "widgets",
+			selector:   &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Matches"}}},
+			wantErr:    `invalid catalog selector: "Matches" is not a valid label selector operator`,
+			wantReason: ocv1alpha1.ReasonInvalidSpec,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: tt.pkg, CatalogSelector: tt.selector},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			require.Equal(t, tt.wantBundle, clusterExtension.Status.ResolvedBundle)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+				require.NotNil(t, cond)
+				require.Equal(t, metav1.ConditionFalse, cond.Status)
+				require.Equal(t, tt.wantReason, cond.Reason)
+			} else {
+				require.NoError(t, err)
+				require.Empty(t, clusterExtension.Status.Resolution.CatalogSelector)
+			}
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionMinimumVersion(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	for _, tt := range []struct {
+		name           string
+		version        string
+		minimumVersion string
+		wantBundle     *ocv1alpha1.BundleMetadata
+		wantErr        string
+	}{
+		{
+			name:           "resolves the latest version at or above the floor",
+			minimumVersion: "1.2.0",
+			wantBundle:     &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"},
+		},
+		{
+			name:           "floor narrows the version range",
+			version:        "<2.0.0",
+			minimumVersion: "1.0.1",
+			wantBundle:     &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.2.0", Version: "1.2.0"},
+		},
+		{
+			name:           "fails when the version range is entirely below the floor",
+			version:        "<1.2.0",
+			minimumVersion: "1.2.0",
+			wantErr:        `no package "prometheus" at or above minimum version "1.2.0" found`,
+		},
+		{
+			name:           "fails when no bundle meets the floor",
+			minimumVersion: "3.0.0",
+			wantErr:        `no package "prometheus" at or above minimum version "3.0.0" found`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					PackageName:    "prometheus",
+					Version:        tt.version,
+					MinimumVersion: tt.minimumVersion,
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.Equal(t, ctrl.Result{}, res)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+			require.NotNil(t, cond)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantBundle, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, metav1.ConditionTrue, cond.Status)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+				require.Empty(t, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, metav1.ConditionFalse, cond.Status)
+				require.Equal(t, ocv1alpha1.ReasonBelowMinimumFloor, cond.Reason)
+				require.Equal(t, tt.wantErr, cond.Message)
+			}
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionLagReleases(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	for _, tt := range []struct {
+		name           string
+		version        string
+		lagReleases    int32
+		wantBundle     *ocv1alpha1.BundleMetadata
+		wantReleaseLag *ocv1alpha1.ReleaseLagStatus
+		wantErr        string
+	}{
+		{
+			name:       "zero targets the channel head",
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"},
+		},
+		{
+			name:           "stays behind the channel head",
+			lagReleases:    2,
+			wantBundle:     &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.1", Version: "1.0.1"},
+			wantReleaseLag: &ocv1alpha1.ReleaseLagStatus{HeadVersion: "2.0.0", TargetVersion: "1.0.1", ReleasesBehindHead: 2},
+		},
+		{
+			name:           "targets the oldest release when the channel is too short",
+			lagReleases:    10,
+			wantBundle:     &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"},
+			wantReleaseLag: &ocv1alpha1.ReleaseLagStatus{HeadVersion: "2.0.0", TargetVersion: "1.0.0", ReleasesBehindHead: 3},
+		},
+		{
+			name:           "reports the resolved bundle's distance when the version range is lower",
+			version:        "<1.0.1",
+			lagReleases:    1,
+			wantBundle:     &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"},
+			wantReleaseLag: &ocv1alpha1.ReleaseLagStatus{HeadVersion: "2.0.0", TargetVersion: "1.2.0", ReleasesBehindHead: 3},
+		},
+		{
+			name:        "fails when the version range is entirely above the target",
+			version:     ">=2.0.0",
+			lagReleases: 1,
+			wantErr:     `no package "prometheus" at or below version "1.2.0" found, the target for lagReleases 1 behind channel head "2.0.0"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					PackageName: "prometheus",
+					Channel:     "beta",
+					Version:     tt.version,
+					Upgrade:     &ocv1alpha1.UpgradeConfig{LagReleases: tt.lagReleases},
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.Equal(t, ctrl.Result{}, res)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+			require.NotNil(t, cond)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantBundle, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, tt.wantReleaseLag, clusterExtension.Status.Resolution.ReleaseLag)
+				require.Equal(t, metav1.ConditionTrue, cond.Status)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+				require.Empty(t, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, metav1.ConditionFalse, cond.Status)
+				require.Equal(t, tt.wantErr, cond.Message)
+			}
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionReportCandidates(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	candidate := func(version string) ocv1alpha1.ResolutionCandidate {
+		return ocv1alpha1.ResolutionCandidate{Name: "operatorhub/prometheus/beta/" + version, Version: version, Catalog: "fake-catalog"}
+	}
+	for _, tt := range []struct {
+		name           string
+		version        string
+		resolution     *ocv1alpha1.ResolutionConfig
+		wantCandidates []ocv1alpha1.ResolutionCandidate
+	}{
+		{
+			name: "not reported by default",
+		},
+		{
+			name:           "reports the most preferred candidates up to the limit",
+			resolution:     &ocv1alpha1.ResolutionConfig{ReportCandidates: 3},
+			wantCandidates: []ocv1alpha1.ResolutionCandidate{candidate("2.0.0"), candidate("1.2.0"), candidate("1.0.1")},
+		},
+		{
+			name:           "reports every candidate when there are fewer than the limit",
+			version:        "<1.2.0",
+			resolution:     &ocv1alpha1.ResolutionConfig{ReportCandidates: 10},
+			wantCandidates: []ocv1alpha1.ResolutionCandidate{candidate("1.0.1"), candidate("1.0.0")},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					PackageName: "prometheus",
+					Channel:     "beta",
+					Version:     tt.version,
+					Resolution:  tt.resolution,
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.NoError(t, err)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			require.Equal(t, tt.wantCandidates, clusterExtension.Status.Resolution.Candidates)
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionResolvedBundleDigest(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	digestFor := func(version string) string {
+		return "sha256:" + strings.Repeat(strings.ReplaceAll(version, ".", ""), 64)[:64]
+	}
+	channel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "pinned",
+		Entries: []declcfg.ChannelEntry{{Name: "pinned.v1.0.0"}, {Name: "pinned.v1.1.0", Replaces: "pinned.v1.0.0"}, {Name: "pinned.v2.0.0", Replaces: "pinned.v1.1.0"}},
+	}}
+	var bundles []*catalogmetadata.Bundle
+	for _, version := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		b := testutil.NewBundle("pinned", version)
+		b.Image = "quay.io/example/pinned@" + digestFor(version)
+		b.InChannels = []*catalogmetadata.Channel{&channel}
+		bundles = append(bundles, b)
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient(bundles)
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	for _, tt := range []struct {
+		name       string
+		version    string
+		digest     string
+		wantBundle *ocv1alpha1.BundleMetadata
+		wantReason string
+		wantErr    string
+	}{
+		{
+			name:       "installs the pinned bundle instead of the latest",
+			digest:     digestFor("1.1.0"),
+			wantBundle: &ocv1alpha1.BundleMetadata{Name: "pinned.v1.1.0", Version: "1.1.0"},
+		},
+		{
+			name:       "fails when the pinned bundle does not satisfy the constraints",
+			version:    "<2.0.0",
+			digest:     digestFor("2.0.0"),
+			wantReason: ocv1alpha1.ReasonPinnedBundleMismatch,
+			wantErr:    fmt.Sprintf(`bundle with image digest %q for package "pinned" does not satisfy the ClusterExtension's constraints`, digestFor("2.0.0")),
+		},
+		{
+			name:       "fails when the pinned bundle is not in any catalog",
+			digest:     "sha256:" + strings.Repeat("f", 64),
+			wantReason: ocv1alpha1.ReasonResolutionFailed,
+			wantErr:    fmt.Sprintf(`no bundle with image digest %q found for package "pinned"`, "sha256:"+strings.Repeat("f", 64)),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					PackageName:          "pinned",
+					Version:              tt.version,
+					ResolvedBundleDigest: tt.digest,
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.Equal(t, ctrl.Result{}, res)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+			require.NotNil(t, cond)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantBundle, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, metav1.ConditionTrue, cond.Status)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+				require.Empty(t, clusterExtension.Status.ResolvedBundle)
+				require.Equal(t, metav1.ConditionFalse, cond.Status)
+				require.Equal(t, tt.wantReason, cond.Reason)
+				require.Equal(t, tt.wantErr, cond.Message)
+			}
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionPrefersInstalledImage(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "mirrored",
+		Entries: []declcfg.ChannelEntry{{Name: "mirrored.v1.0.0"}},
+	}}
+	bundle := func(catalog string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("mirrored", "1.0.0")
+		b.Image = fmt.Sprintf("quay.io/%s/mirrored@fake1.0.0", catalog)
+		b.CatalogName = catalog
+		b.InChannels = []*catalogmetadata.Channel{&channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{bundle("mirror-a"), bundle("mirror-b")})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcileAndGetMessage := func(extKey types.NamespacedName) string {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+		clusterExtension := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+		require.NotNil(t, cond)
+		return cond.Message
+	}
+
+	t.Log("It fails resolution as ambiguous on a fresh install, as nothing decides between the equally ranked candidates")
+	freshKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	require.NoError(t, cl.Create(ctx, &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: freshKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "mirrored"},
+	}))
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: freshKey})
+	require.EqualError(t, err, `catalogs "mirror-a", "mirror-b" of equal priority provide different content for version 1.0.0 of package "mirrored"`)
+	fresh := &ocv1alpha1.ClusterExtension{}
+	require.NoError(t, cl.Get(ctx, freshKey, fresh))
+	verifyInvariants(ctx, t, reconciler.Client, fresh)
+	cond := apimeta.FindStatusCondition(fresh.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonResolutionAmbiguous, cond.Reason)
+
+	t.Log("It keeps the installed image when an equally ranked candidate comes first")
+	installedKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	require.NoError(t, cl.Create(ctx, &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: installedKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "mirrored"},
+	}))
+	require.NoError(t, cl.Create(ctx, &rukpakv1alpha2.BundleDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: installedKey.Name},
+		Spec: rukpakv1alpha2.BundleDeploymentSpec{
+			ProvisionerClassName: "core-rukpak-io-registry",
+			Source: rukpakv1alpha2.BundleSource{
+				Type:  rukpakv1alpha2.SourceTypeImage,
+				Image: &rukpakv1alpha2.ImageSource{Ref: "quay.io/mirror-b/mirrored@fake1.0.0"},
+			},
+		},
+	}))
+	require.Equal(t, `resolved to "quay.io/mirror-b/mirrored@fake1.0.0"`, reconcileAndGetMessage(installedKey))
+}
+
+func TestClusterExtensionCatalogOverlays(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "overlaid",
+		Entries: []declcfg.ChannelEntry{{Name: "overlaid.v1.0.0"}, {Name: "overlaid.v1.1.0", Replaces: "overlaid.v1.0.0"}},
+	}}
+	bundle := func(version, catalog string, catalogLabels map[string]string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("overlaid", version)
+		b.Image = fmt.Sprintf("quay.io/%s/overlaid@fake%s", catalog, version)
+		b.CatalogName = catalog
+		b.CatalogLabels = catalogLabels
+		b.InChannels = []*catalogmetadata.Channel{&channel}
+		return b
+	}
+	overlayLabels := map[string]string{catalogmetadata.LabelCatalogPriority: "10"}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0", "base", nil),
+		bundle("1.1.0", "base", nil),
+		bundle("1.0.0", "overlay", overlayLabels),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "overlaid", Version: "1.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, `resolved to "quay.io/overlay/overlaid@fake1.0.0"`, cond.Message)
+	require.Equal(t, &ocv1alpha1.ResolutionStatus{
+		Selected: &ocv1alpha1.SelectedBundle{
+			Bundle:   ocv1alpha1.BundleMetadata{Name: "overlaid.v1.0.0", Version: "1.0.0"},
+			Package:  "overlaid",
+			Channels: []string{"stable"},
+			Catalog:  "overlay",
+			Image:    "quay.io/overlay/overlaid@fake1.0.0",
+			Digest:   "fake1.0.0",
+		},
+		Catalogs: []ocv1alpha1.CatalogResolutionStatus{
+			{Name: "base"},
+			{
+				Name:              "overlay",
+				Priority:          10,
+				Candidates:        1,
+				Selected:          true,
+				OverriddenBundles: []ocv1alpha1.OverriddenBundle{{Name: "overlaid.v1.0.0", Catalog: "base"}},
+			},
+		},
+	}, clusterExtension.Status.Resolution)
+
+	verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+}
+
 func verifyInvariants(ctx context.Context, t *testing.T, c client.Client, ext *ocv1alpha1.ClusterExtension) {
 	key := client.ObjectKeyFromObject(ext)
 	require.NoError(t, c.Get(ctx, key, ext))
@@ -1003,11 +1782,82 @@ func verifyConditionsInvariants(t *testing.T, ext *ocv1alpha1.ClusterExtension)
 	}
 }
 
+func TestClusterExtensionSelectedBundle(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	bundle := func(pkg, image string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, "1.0.0")
+		b.Image = image
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "registry.example.com:5000/widgets-bundle:v1.0.0@"+digest),
+		bundle("gadgets", "registry.example.com:5000/gadgets-bundle:v1.0.0"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	for _, tt := range []struct {
+		pkg  string
+		want *ocv1alpha1.SelectedBundle
+	}{
+		{"widgets", &ocv1alpha1.SelectedBundle{
+			Bundle:  ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"},
+			Package: "widgets",
+			Catalog: "fake-catalog",
+			Image:   "registry.example.com:5000/widgets-bundle:v1.0.0@" + digest,
+			Tag:     "v1.0.0",
+			Digest:  digest,
+		}},
+		{"gadgets", &ocv1alpha1.SelectedBundle{
+			Bundle:  ocv1alpha1.BundleMetadata{Name: "gadgets.v1.0.0", Version: "1.0.0"},
+			Package: "gadgets",
+			Catalog: "fake-catalog",
+			Image:   "registry.example.com:5000/gadgets-bundle:v1.0.0",
+			Tag:     "v1.0.0",
+		}},
+	} {
+		t.Run(tt.pkg, func(t *testing.T) {
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: tt.pkg},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.NoError(t, err)
+
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			require.NotNil(t, clusterExtension.Status.Resolution)
+			require.Equal(t, tt.want, clusterExtension.Status.Resolution.Selected)
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
 func TestGeneratedBundleDeployment(t *testing.T) {
+	testBundle := &catalogmetadata.Bundle{
+		Bundle: declcfg.Bundle{
+			Name:  "test-bundle.v1.2.3",
+			Image: "testpath",
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"test-bundle","version":"1.2.3"}`)},
+			},
+		},
+		CatalogName: "test-catalog",
+	}
+
 	test := []struct {
 		name                     string
 		clusterExtension         ocv1alpha1.ClusterExtension
-		bundlePath               string
+		bundle                   *catalogmetadata.Bundle
 		bundleProvisioner        string
 		expectedBundleDeployment *unstructured.Unstructured
 	}{
@@ -1022,7 +1872,7 @@ func TestGeneratedBundleDeployment(t *testing.T) {
 					WatchNamespaces: []string{"alpha", "beta", "gamma"},
 				},
 			},
-			bundlePath:               "testpath",
+			bundle:                   testBundle,
 			bundleProvisioner:        "foo",
 			expectedBundleDeployment: &unstructured.Unstructured{},
 		},
@@ -1034,7 +1884,7 @@ func TestGeneratedBundleDeployment(t *testing.T) {
 					UID:  types.UID("test"),
 				},
 			},
-			bundlePath:               "testpath",
+			bundle:                   testBundle,
 			bundleProvisioner:        "foo",
 			expectedBundleDeployment: &unstructured.Unstructured{},
 		},
@@ -1042,14 +1892,19 @@ func TestGeneratedBundleDeployment(t *testing.T) {
 
 	for _, tt := range test {
 		fakeReconciler := &controllers.ClusterExtensionReconciler{}
-		objUnstructured := fakeReconciler.GenerateExpectedBundleDeployment(tt.clusterExtension, tt.bundlePath, tt.bundleProvisioner)
+		objUnstructured := fakeReconciler.GenerateExpectedBundleDeployment(tt.clusterExtension, tt.bundle, tt.bundleProvisioner)
 		resultBundleDeployment := &rukpakv1alpha2.BundleDeployment{}
 		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(objUnstructured.Object, resultBundleDeployment))
 		// Verify the fields that have are being taken from cluster extension.
 		require.Equal(t, tt.clusterExtension.GetName(), resultBundleDeployment.GetName())
-		require.Equal(t, tt.bundlePath, resultBundleDeployment.Spec.Source.Image.Ref)
+		require.Equal(t, tt.bundle.Image, resultBundleDeployment.Spec.Source.Image.Ref)
 		require.Equal(t, tt.bundleProvisioner, resultBundleDeployment.Spec.ProvisionerClassName)
 		require.Equal(t, tt.clusterExtension.Spec.WatchNamespaces, resultBundleDeployment.Spec.WatchNamespaces)
+		require.Equal(t, map[string]string{
+			"olm.operatorframework.io/catalogName":   "test-catalog",
+			"olm.operatorframework.io/bundleName":    "test-bundle.v1.2.3",
+			"olm.operatorframework.io/bundleVersion": "1.2.3",
+		}, resultBundleDeployment.GetAnnotations())
 	}
 }
 
@@ -1059,10 +1914,7 @@ func TestClusterExtensionUpgrade(t *testing.T) {
 
 	t.Run("semver upgrade constraints enforcement of upgrades within major version", func(t *testing.T) {
 		defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, true)()
-		defer func() {
-			require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-			require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-		}()
+		deleteAllOnCleanup(t, cl)
 
 		pkgName := "prometheus"
 		pkgVer := "1.0.0"
@@ -1151,10 +2003,7 @@ func TestClusterExtensionUpgrade(t *testing.T) {
 
 	t.Run("legacy semantics upgrade constraints enforcement", func(t *testing.T) {
 		defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, false)()
-		defer func() {
-			require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-			require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-		}()
+		deleteAllOnCleanup(t, cl)
 
 		pkgName := "prometheus"
 		pkgVer := "1.0.0"
@@ -1257,10 +2106,7 @@ func TestClusterExtensionUpgrade(t *testing.T) {
 		} {
 			t.Run(tt.name, func(t *testing.T) {
 				defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, tt.flagState)()
-				defer func() {
-					require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-					require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-				}()
+				deleteAllOnCleanup(t, cl)
 
 				extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
 				clusterExtension := &ocv1alpha1.ClusterExtension{
@@ -1345,10 +2191,7 @@ func TestClusterExtensionDowngrade(t *testing.T) {
 		} {
 			t.Run(tt.name, func(t *testing.T) {
 				defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, tt.flagState)()
-				defer func() {
-					require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-					require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-				}()
+				deleteAllOnCleanup(t, cl)
 
 				extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
 				clusterExtension := &ocv1alpha1.ClusterExtension{
@@ -1426,10 +2269,7 @@ func TestClusterExtensionDowngrade(t *testing.T) {
 		} {
 			t.Run(tt.name, func(t *testing.T) {
 				defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, tt.flagState)()
-				defer func() {
-					require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-					require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-				}()
+				deleteAllOnCleanup(t, cl)
 
 				extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
 				clusterExtension := &ocv1alpha1.ClusterExtension{
@@ -1928,6 +2768,175 @@ func TestSetDeprecationStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "deprecated package, no channel specified, PackageDeprecated and Deprecated status set to true",
+			clusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			expectedClusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               ocv1alpha1.TypeDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypePackageDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeChannelDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeBundleDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+					},
+				},
+			},
+			bundle: &catalogmetadata.Bundle{
+				Deprecations: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{
+							Schema: declcfg.SchemaPackage,
+						},
+						Message: "bad package!",
+					},
+				},
+			},
+		},
+		{
+			name: "non-deprecated bundle, every channel of the bundle deprecated, no channel specified, ChannelDeprecated and Deprecated status set to true",
+			clusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			expectedClusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               ocv1alpha1.TypeDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypePackageDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeChannelDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionTrue,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeBundleDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+					},
+				},
+			},
+			bundle: &catalogmetadata.Bundle{
+				InChannels: []*catalogmetadata.Channel{{Channel: declcfg.Channel{Name: "badchannel"}}},
+				Deprecations: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{
+							Schema: declcfg.SchemaChannel,
+							Name:   "badchannel",
+						},
+						Message: "bad channel!",
+					},
+				},
+			},
+		},
+		{
+			name: "non-deprecated bundle, one of the bundle's channels deprecated, no channel specified, all deprecation statuses set to False",
+			clusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{},
+				},
+			},
+			expectedClusterExtension: &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 1,
+				},
+				Status: ocv1alpha1.ClusterExtensionStatus{
+					Conditions: []metav1.Condition{
+						{
+							Type:               ocv1alpha1.TypeDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypePackageDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeChannelDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+						{
+							Type:               ocv1alpha1.TypeBundleDeprecated,
+							Reason:             ocv1alpha1.ReasonDeprecated,
+							Status:             metav1.ConditionFalse,
+							ObservedGeneration: 1,
+						},
+					},
+				},
+			},
+			bundle: &catalogmetadata.Bundle{
+				InChannels: []*catalogmetadata.Channel{
+					{Channel: declcfg.Channel{Name: "badchannel"}},
+					{Channel: declcfg.Channel{Name: "goodchannel"}},
+				},
+				Deprecations: []declcfg.DeprecationEntry{
+					{
+						Reference: declcfg.PackageScopedReference{
+							Schema: declcfg.SchemaChannel,
+							Name:   "badchannel",
+						},
+						Message: "bad channel!",
+					},
+				},
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			controllers.SetDeprecationStatus(tc.clusterExtension, tc.bundle)
@@ -1978,6 +2987,21 @@ var (
 			Package: "badmedia",
 		},
 	}
+	widgetsStableChannel = catalogmetadata.Channel{
+		Channel: declcfg.Channel{
+			Name:    "stable",
+			Package: "widgets",
+			Entries: []declcfg.ChannelEntry{
+				{
+					Name: "operatorhub/widgets/1.0.0",
+				},
+				{
+					Name:     "operatorhub/widgets/1.1.0",
+					Replaces: "operatorhub/widgets/1.0.0",
+				},
+			},
+		},
+	}
 )
 
 var testBundleList = []*catalogmetadata.Bundle{
@@ -2074,4 +3098,31 @@ var testBundleList = []*catalogmetadata.Bundle{
 		CatalogName: "fake-catalog",
 		InChannels:  []*catalogmetadata.Channel{&badmediaBetaChannel},
 	},
+	{
+		Bundle: declcfg.Bundle{
+			Name:    "operatorhub/widgets/1.0.0",
+			Package: "widgets",
+			Image:   "quay.io/operatorhub/widgets@fake1.0.0",
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"widgets","version":"1.0.0"}`)},
+				{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"example.com","kind":"Widget","version":"v1"}`)},
+			},
+		},
+		CatalogName:   "certified-catalog",
+		CatalogLabels: map[string]string{"tier": "certified"},
+		InChannels:    []*catalogmetadata.Channel{&widgetsStableChannel},
+	},
+	{
+		Bundle: declcfg.Bundle{
+			Name:    "operatorhub/widgets/1.1.0",
+			Package: "widgets",
+			Image:   "quay.io/operatorhub/widgets@fake1.1.0",
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"widgets","version":"1.1.0"}`)},
+				{Type: property.TypeGVK, Value: json.RawMessage(`{"group":"example.com","kind":"Widget","version":"v1"}`)},
+			},
+		},
+		CatalogName: "fake-catalog",
+		InChannels:  []*catalogmetadata.Channel{&widgetsStableChannel},
+	},
 }
@@ -2,7 +2,6 @@ package controllers_test
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -14,7 +13,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/rand"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/operator-framework/operator-registry/alpha/declcfg"
 	"github.com/operator-framework/operator-registry/alpha/property"
 	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
 
@@ -24,7 +22,15 @@ import (
 	testutil "github.com/operator-framework/operator-controller/test/util"
 )
 
-func TestClusterExtensionRegistryV1DisallowDependencies(t *testing.T) {
+// registryV1Bundle returns version 1.0.0 of the package, named the way catalogd names
+// registry+v1 bundles.
+func registryV1Bundle(pkg string, props ...property.Property) *catalogmetadata.Bundle {
+	b := testutil.NewBundle(pkg, "1.0.0", props...)
+	b.Name = "fake-catalog/" + pkg + "/alpha/1.0.0"
+	return b
+}
+
+func TestClusterExtensionRegistryV1RequiredDependencies(t *testing.T) {
 	ctx := context.Background()
 	cl := newClient(t)
 
@@ -34,73 +40,22 @@ func TestClusterExtensionRegistryV1DisallowDependencies(t *testing.T) {
 		wantErr string
 	}{
 		{
-			name: "package with no dependencies",
-			bundle: &catalogmetadata.Bundle{
-				Bundle: declcfg.Bundle{
-					Name:    "fake-catalog/no-dependencies-package/alpha/1.0.0",
-					Package: "no-dependencies-package",
-					Image:   "quay.io/fake-catalog/no-dependencies-package@sha256:3e281e587de3d03011440685fc4fb782672beab044c1ebadc42788ce05a21c35",
-					Properties: []property.Property{
-						{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"no-dependencies-package","version":"1.0.0"}`)},
-					},
-				},
-				CatalogName: "fake-catalog",
-			},
-		},
-		{
-			name: "package with olm.package.required property",
-			bundle: &catalogmetadata.Bundle{
-				Bundle: declcfg.Bundle{
-					Name:    "fake-catalog/package-required-test/alpha/1.0.0",
-					Package: "package-required-test",
-					Image:   "quay.io/fake-catalog/package-required-test@sha256:3e281e587de3d03011440685fc4fb782672beab044c1ebadc42788ce05a21c35",
-					Properties: []property.Property{
-						{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"package-required-test","version":"1.0.0"}`)},
-						{Type: property.TypePackageRequired, Value: json.RawMessage("content-is-not-relevant")},
-					},
-				},
-				CatalogName: "fake-catalog",
-			},
-			wantErr: `bundle "fake-catalog/package-required-test/alpha/1.0.0" has a dependency declared via property "olm.package.required" which is currently not supported`,
+			name:   "package with no dependencies",
+			bundle: registryV1Bundle("no-dependencies-package"),
 		},
 		{
-			name: "package with olm.gvk.required property",
-			bundle: &catalogmetadata.Bundle{
-				Bundle: declcfg.Bundle{
-					Name:    "fake-catalog/gvk-required-test/alpha/1.0.0",
-					Package: "gvk-required-test",
-					Image:   "quay.io/fake-catalog/gvk-required-test@sha256:3e281e587de3d03011440685fc4fb782672beab044c1ebadc42788ce05a21c35",
-					Properties: []property.Property{
-						{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"gvk-required-test","version":"1.0.0"}`)},
-						{Type: property.TypeGVKRequired, Value: json.RawMessage(`content-is-not-relevant`)},
-					},
-				},
-				CatalogName: "fake-catalog",
-			},
-			wantErr: `bundle "fake-catalog/gvk-required-test/alpha/1.0.0" has a dependency declared via property "olm.gvk.required" which is currently not supported`,
+			name:    "package with olm.package.required property",
+			bundle:  registryV1Bundle("package-required-test", property.MustBuildPackageRequired("some-package", ">=1.0.0")),
+			wantErr: `bundle "fake-catalog/package-required-test/alpha/1.0.0" requires package "some-package" in range ">=1.0.0", which is not installed by any ClusterExtension`,
 		},
 		{
-			name: "package with olm.constraint property",
-			bundle: &catalogmetadata.Bundle{
-				Bundle: declcfg.Bundle{
-					Name:    "fake-catalog/constraint-test/alpha/1.0.0",
-					Package: "constraint-test",
-					Image:   "quay.io/fake-catalog/constraint-test@sha256:3e281e587de3d03011440685fc4fb782672beab044c1ebadc42788ce05a21c35",
-					Properties: []property.Property{
-						{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"constraint-test","version":"1.0.0"}`)},
-						{Type: property.TypeConstraint, Value: json.RawMessage(`content-is-not-relevant`)},
-					},
-				},
-				CatalogName: "fake-catalog",
-			},
-			wantErr: `bundle "fake-catalog/constraint-test/alpha/1.0.0" has a dependency declared via property "olm.constraint" which is currently not supported`,
+			name:    "package with olm.gvk.required property",
+			bundle:  registryV1Bundle("gvk-required-test", property.MustBuildGVKRequired("example.com", "v1", "Widget")),
+			wantErr: `bundle "fake-catalog/gvk-required-test/alpha/1.0.0" requires API example.com/v1 Widget, which is not provided by any installed ClusterExtension or CustomResourceDefinition`,
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			defer func() {
-				require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ClusterExtension{}))
-				require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
-			}()
+			deleteAllOnCleanup(t, cl)
 
 			fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{tt.bundle})
 			reconciler := &controllers.ClusterExtensionReconciler{
@@ -117,13 +72,15 @@ func TestClusterExtensionRegistryV1DisallowDependencies(t *testing.T) {
 
 			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
 			require.Equal(t, ctrl.Result{}, res)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
 			if tt.wantErr == "" {
 				assert.NoError(t, err)
+				assert.Equal(t, []ocv1alpha1.PreflightCheckStatus{{Name: "RequiredDependencies", Passed: true}, {Name: "CRDOwnership", Passed: true}}, clusterExtension.Status.PreflightChecks)
 			} else {
 				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, []ocv1alpha1.PreflightCheckStatus{{Name: "RequiredDependencies", Message: tt.wantErr}, {Name: "CRDOwnership", Passed: true}}, clusterExtension.Status.PreflightChecks)
 
 				// In case of an error we want it to be included in the installed condition
-				require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
 				cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeInstalled)
 				require.NotNil(t, cond)
 				require.Equal(t, metav1.ConditionFalse, cond.Status)
@@ -133,3 +90,44 @@ func TestClusterExtensionRegistryV1DisallowDependencies(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterExtensionPreflightWarnMode(t *testing.T) {
+	ctx := context.Background()
+	cl := newClient(t)
+	deleteAllOnCleanup(t, cl)
+
+	bundle := registryV1Bundle("package-required-test", property.MustBuildPackageRequired("some-package", ">=1.0.0"))
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{bundle})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName: bundle.Package,
+			Preflight:   &ocv1alpha1.PreflightConfig{Mode: ocv1alpha1.PreflightModeWarn},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.Equal(t, ctrl.Result{}, res)
+	require.NoError(t, err)
+
+	require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+	assert.Equal(t, []ocv1alpha1.PreflightCheckStatus{{
+		Name:    "RequiredDependencies",
+		Message: `bundle "fake-catalog/package-required-test/alpha/1.0.0" requires package "some-package" in range ">=1.0.0", which is not installed by any ClusterExtension`,
+	}, {
+		Name:   "CRDOwnership",
+		Passed: true,
+	}}, clusterExtension.Status.PreflightChecks)
+
+	// The failed check does not block the install.
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: extKey.Name}, bd))
+	assert.Equal(t, bundle.Image, bd.Spec.Source.Image.Ref)
+}
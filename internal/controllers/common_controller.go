@@ -26,6 +26,13 @@ import (
 	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
 )
 
+// Annotations recording where the installed content came from.
+var (
+	bundleVersionKey = "olm.operatorframework.io/bundleVersion"
+	bundleNameKey    = "olm.operatorframework.io/bundleName"
+	catalogNameKey   = "olm.operatorframework.io/catalogName"
+)
+
 // BundleProvider provides the way to retrieve a list of Bundles from a source,
 // generally from a catalog client of some kind.
 type BundleProvider interface {
@@ -54,12 +61,41 @@ func setInstalledStatusConditionUnknown(conditions *[]metav1.Condition, message
 	})
 }
 
+// setInstalledStatusConditionPending sets the installed status condition to unknown
+// while the installation waits for a concurrent install slot.
+func setInstalledStatusConditionPending(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeInstalled,
+		Status:             metav1.ConditionUnknown,
+		Reason:             ocv1alpha1.ReasonInstallPending,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setInstalledStatusConditionDryRun sets the installed status condition to unknown
+// while spec.dryRun keeps the resolved bundle from being installed.
+func setInstalledStatusConditionDryRun(conditions *[]metav1.Condition, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeInstalled,
+		Status:             metav1.ConditionUnknown,
+		Reason:             ocv1alpha1.ReasonDryRun,
+		Message:            "installation was skipped as spec.dryRun is set",
+		ObservedGeneration: generation,
+	})
+}
+
 // setResolvedStatusConditionFailed sets the resolved status condition to failed.
 func setResolvedStatusConditionFailed(conditions *[]metav1.Condition, message string, generation int64) {
+	setResolvedStatusConditionFailedWithReason(conditions, ocv1alpha1.ReasonResolutionFailed, message, generation)
+}
+
+// setResolvedStatusConditionFailedWithReason sets the resolved status condition to failed with a specific reason.
+func setResolvedStatusConditionFailedWithReason(conditions *[]metav1.Condition, reason, message string, generation int64) {
 	apimeta.SetStatusCondition(conditions, metav1.Condition{
 		Type:               ocv1alpha1.TypeResolved,
 		Status:             metav1.ConditionFalse,
-		Reason:             ocv1alpha1.ReasonResolutionFailed,
+		Reason:             reason,
 		Message:            message,
 		ObservedGeneration: generation,
 	})
@@ -107,6 +143,100 @@ func setDeprecationStatusesUnknown(conditions *[]metav1.Condition, message strin
 	}
 }
 
+// setPostInstallConditionsUnknown sets the deprecation status conditions and the
+// conditions observed on the installed objects to unknown, as they have not been
+// checked for the given cause, e.g. "installation has failed".
+func setPostInstallConditionsUnknown(conditions *[]metav1.Condition, cause string, generation int64) {
+	setDeprecationStatusesUnknown(conditions, "deprecation checks have not been attempted as "+cause, generation)
+	setInstalledObjectConditionsUnknown(conditions, cause, generation)
+}
+
+// setInstalledObjectConditionsUnknown sets the CRDs established and healthy status
+// conditions, which are observed on the installed objects, to unknown, as they have
+// not been checked for the given cause.
+func setInstalledObjectConditionsUnknown(conditions *[]metav1.Condition, cause string, generation int64) {
+	setCRDsEstablishedStatusConditionUnknown(conditions, "CRDs have not been checked as "+cause, generation)
+	setHealthyStatusConditionUnknown(conditions, "health has not been checked as "+cause, generation)
+}
+
+// setCRDsEstablishedStatusConditionUnknown sets the CRDs established status condition to unknown.
+func setCRDsEstablishedStatusConditionUnknown(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeCRDsEstablished,
+		Status:             metav1.ConditionUnknown,
+		Reason:             ocv1alpha1.ReasonInstallationStatusUnknown,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setCRDsEstablishedStatusConditionSuccess sets the CRDs established status condition to success.
+func setCRDsEstablishedStatusConditionSuccess(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeCRDsEstablished,
+		Status:             metav1.ConditionTrue,
+		Reason:             ocv1alpha1.ReasonSuccess,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setCRDsEstablishedStatusConditionFailed sets the CRDs established status condition to failed.
+func setCRDsEstablishedStatusConditionFailed(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeCRDsEstablished,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1alpha1.ReasonCRDsNotEstablished,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setHealthyStatusConditionUnknown sets the healthy status condition to unknown.
+func setHealthyStatusConditionUnknown(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeHealthy,
+		Status:             metav1.ConditionUnknown,
+		Reason:             ocv1alpha1.ReasonInstallationStatusUnknown,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setHealthyStatusConditionSuccess sets the healthy status condition to success.
+func setHealthyStatusConditionSuccess(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeHealthy,
+		Status:             metav1.ConditionTrue,
+		Reason:             ocv1alpha1.ReasonSuccess,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setHealthyStatusConditionNoWorkloads sets the healthy status condition to true for a
+// bundle that installed no Deployments or APIServices.
+func setHealthyStatusConditionNoWorkloads(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeHealthy,
+		Status:             metav1.ConditionTrue,
+		Reason:             ocv1alpha1.ReasonNoWorkloads,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
+// setHealthyStatusConditionFailed sets the healthy status condition to failed.
+func setHealthyStatusConditionFailed(conditions *[]metav1.Condition, message string, generation int64) {
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ocv1alpha1.TypeHealthy,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1alpha1.ReasonUnhealthy,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
+
 // setProgressingStatusConditionSuccess sets the progressing status condition to false for a successful install or upgrade.
 func setProgressingStatusConditionSuccess(conditions *[]metav1.Condition, message string, generation int64) {
 	apimeta.SetStatusCondition(conditions, metav1.Condition{
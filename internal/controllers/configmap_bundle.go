@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// packageNameKey is the annotation naming the package a ConfigMap bundle belongs to.
+// The bundle version is read from the bundleVersionKey annotation.
+var packageNameKey = "olm.operatorframework.io/package"
+
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+
+// bundleFromConfigMap reads the ConfigMap referenced by the ClusterExtension and returns
+// a bundle describing its contents. The ConfigMap must carry the package name and bundle
+// version annotations, and every one of its data entries must contain Kubernetes manifests.
+func (r *ClusterExtensionReconciler) bundleFromConfigMap(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, error) {
+	ref := ext.Spec.ConfigMapBundle
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.BundleConfigMapNamespace, Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("error getting bundle ConfigMap %q: %w", ref.Name, err)
+	}
+
+	packageName := cm.Annotations[packageNameKey]
+	if packageName == "" {
+		return nil, fmt.Errorf("bundle ConfigMap %q is missing the %q annotation", cm.Name, packageNameKey)
+	}
+	version := cm.Annotations[bundleVersionKey]
+	if version == "" {
+		return nil, fmt.Errorf("bundle ConfigMap %q is missing the %q annotation", cm.Name, bundleVersionKey)
+	}
+	if _, err := bsemver.Parse(version); err != nil {
+		return nil, fmt.Errorf("bundle ConfigMap %q has invalid version %q: %w", cm.Name, version, err)
+	}
+
+	if _, err := configMapManifests(cm); err != nil {
+		return nil, fmt.Errorf("bundle ConfigMap %q has invalid content: %w", cm.Name, err)
+	}
+
+	pkgProperty, err := json.Marshal(property.Package{PackageName: packageName, Version: version})
+	if err != nil {
+		return nil, err
+	}
+	mediaTypeProperty, err := json.Marshal(catalogmetadata.MediaTypePlain)
+	if err != nil {
+		return nil, err
+	}
+	return &catalogmetadata.Bundle{
+		Bundle: declcfg.Bundle{
+			Name:    cm.Name,
+			Package: packageName,
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: pkgProperty},
+				{Type: catalogmetadata.PropertyBundleMediaType, Value: mediaTypeProperty},
+			},
+		},
+	}, nil
+}
+
+// configMapManifests parses the objects in the data entries of the ConfigMap, in key
+// order, and checks that every entry contains one or more Kubernetes objects.
+func configMapManifests(cm *corev1.ConfigMap) ([]unstructured.Unstructured, error) {
+	if len(cm.Data) == 0 {
+		return nil, errors.New("no manifests found")
+	}
+
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var objs []unstructured.Unstructured
+	for _, key := range keys {
+		dec := yaml.NewYAMLOrJSONDecoder(bytes.NewBufferString(cm.Data[key]), 4096)
+		for {
+			obj := unstructured.Unstructured{}
+			if err := dec.Decode(&obj.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("error parsing %q: %s", key, err)
+			}
+			if obj.Object == nil {
+				continue
+			}
+			if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+				return nil, fmt.Errorf("object in %q is missing apiVersion or kind", key)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// clusterExtensionRequestsForConfigMap enqueues the ClusterExtensions
+// that install their bundle from the given ConfigMap.
+func clusterExtensionRequestsForConfigMap(c client.Reader, namespace string, logger logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		if obj.GetNamespace() != namespace {
+			return nil
+		}
+		clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+		err := c.List(ctx, &clusterExtensions)
+		if err != nil {
+			logger.Error(err, "unable to enqueue cluster extensions for configmap reconcile")
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, ext := range clusterExtensions.Items {
+			if ext.Spec.ConfigMapBundle == nil || ext.Spec.ConfigMapBundle.Name != obj.GetName() {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: ext.GetNamespace(),
+					Name:      ext.GetName(),
+				},
+			})
+		}
+		return requests
+	}
+}
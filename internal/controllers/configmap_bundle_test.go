@@ -0,0 +1,237 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+const configMapBundleManifests = `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: my-operator
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-operator-config
+`
+
+func TestClusterExtensionConfigMapBundle(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.BundleConfigMapNamespace = "default"
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+	defer func() {
+		require.NoError(t, cl.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default")))
+	}()
+
+	for _, tt := range []struct {
+		name        string
+		annotations map[string]string
+		data        map[string]string
+		wantErr     string
+	}{
+		{
+			name: "valid bundle",
+			annotations: map[string]string{
+				"olm.operatorframework.io/package":       "my-operator",
+				"olm.operatorframework.io/bundleVersion": "1.0.0",
+			},
+			data: map[string]string{"manifests.yaml": configMapBundleManifests},
+		},
+		{
+			name: "missing package annotation",
+			annotations: map[string]string{
+				"olm.operatorframework.io/bundleVersion": "1.0.0",
+			},
+			data:    map[string]string{"manifests.yaml": configMapBundleManifests},
+			wantErr: `bundle ConfigMap %q is missing the "olm.operatorframework.io/package" annotation`,
+		},
+		{
+			name: "invalid version",
+			annotations: map[string]string{
+				"olm.operatorframework.io/package":       "my-operator",
+				"olm.operatorframework.io/bundleVersion": "latest",
+			},
+			data:    map[string]string{"manifests.yaml": configMapBundleManifests},
+			wantErr: `bundle ConfigMap %q has invalid version "latest": No Major.Minor.Patch elements found`,
+		},
+		{
+			name: "manifest without kind",
+			annotations: map[string]string{
+				"olm.operatorframework.io/package":       "my-operator",
+				"olm.operatorframework.io/bundleVersion": "1.0.0",
+			},
+			data:    map[string]string{"manifests.yaml": "apiVersion: v1\nmetadata:\n  name: foo\n"},
+			wantErr: `bundle ConfigMap %q has invalid content: object in "manifests.yaml" is missing apiVersion or kind`,
+		},
+		{
+			name: "no manifests",
+			annotations: map[string]string{
+				"olm.operatorframework.io/package":       "my-operator",
+				"olm.operatorframework.io/bundleVersion": "1.0.0",
+			},
+			wantErr: `bundle ConfigMap %q has invalid content: no manifests found`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmName := fmt.Sprintf("bundle-%s", rand.String(8))
+			require.NoError(t, cl.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        cmName,
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+				Data: tt.data,
+			}))
+
+			extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+			clusterExtension := &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec: ocv1alpha1.ClusterExtensionSpec{
+					ConfigMapBundle: &ocv1alpha1.ConfigMapBundle{Name: cmName},
+				},
+			}
+			require.NoError(t, cl.Create(ctx, clusterExtension))
+
+			res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+			require.Equal(t, ctrl.Result{}, res)
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+			require.NotNil(t, cond)
+
+			if tt.wantErr != "" {
+				wantErr := fmt.Sprintf(tt.wantErr, cmName)
+				require.EqualError(t, err, wantErr)
+				assert.Equal(t, metav1.ConditionFalse, cond.Status)
+				assert.Equal(t, wantErr, cond.Message)
+				assert.Empty(t, clusterExtension.Status.ResolvedBundle)
+				verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, metav1.ConditionTrue, cond.Status)
+			assert.Equal(t, fmt.Sprintf("resolved to ConfigMap %q", cmName), cond.Message)
+			assert.Equal(t, &ocv1alpha1.BundleMetadata{Name: cmName, Version: "1.0.0"}, clusterExtension.Status.ResolvedBundle)
+			assert.Equal(t, "my-operator", clusterExtension.Status.ResolvedPackageName)
+
+			bd := &rukpakv1alpha2.BundleDeployment{}
+			require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: extKey.Name}, bd))
+			assert.Equal(t, "core-rukpak-io-plain", bd.Spec.ProvisionerClassName)
+			assert.Equal(t, rukpakv1alpha2.SourceTypeConfigMaps, bd.Spec.Source.Type)
+			require.Len(t, bd.Spec.Source.ConfigMaps, 1)
+			assert.Equal(t, cmName, bd.Spec.Source.ConfigMaps[0].ConfigMap.Name)
+
+			verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		})
+	}
+}
+
+func TestClusterExtensionSourceChange(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.BundleConfigMapNamespace = "default"
+	recorder := record.NewFakeRecorder(10)
+	reconciler.Recorder = recorder
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+	defer func() {
+		require.NoError(t, cl.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default")))
+	}()
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconcileAndGetInstalled := func() *metav1.Condition {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeInstalled)
+		require.NotNil(t, cond)
+		return cond
+	}
+	// observedGeneration returns the status.observedGeneration to report for a BundleDeployment of the given generation.
+	setBundleDeploymentInstalled := func(observedGeneration func(generation int64) int64) {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: extKey.Name}, bd))
+		bd.Status.ObservedGeneration = observedGeneration(bd.Generation)
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Message: "installed",
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+	}
+
+	unset := func(int64) int64 { return 0 }
+	stale := func(generation int64) int64 { return generation - 1 }
+	current := func(generation int64) int64 { return generation }
+
+	t.Log("By installing the bundle from a catalog")
+	reconcileAndGetInstalled()
+	setBundleDeploymentInstalled(unset)
+	cond := reconcileAndGetInstalled()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Empty(t, recorder.Events)
+
+	t.Log("By switching the ClusterExtension to a ConfigMap bundle")
+	cmName := fmt.Sprintf("bundle-%s", rand.String(8))
+	require.NoError(t, cl.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: "default",
+			Annotations: map[string]string{
+				"olm.operatorframework.io/package":       "prometheus",
+				"olm.operatorframework.io/bundleVersion": "2.1.0",
+			},
+		},
+		Data: map[string]string{"manifests.yaml": configMapBundleManifests},
+	}))
+	clusterExtension.Spec = ocv1alpha1.ClusterExtensionSpec{ConfigMapBundle: &ocv1alpha1.ConfigMapBundle{Name: cmName}}
+	require.NoError(t, cl.Update(ctx, clusterExtension))
+
+	t.Log("It does not report the previous source's installation")
+	cond = reconcileAndGetInstalled()
+	assert.Equal(t, metav1.ConditionUnknown, cond.Status)
+	assert.Equal(t, "bundle source changed from image to configMaps; waiting for the new bundle to be installed", cond.Message)
+	assert.Nil(t, clusterExtension.Status.InstalledBundle)
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Normal SourceChanged bundle source changed from image to configMaps", <-recorder.Events)
+
+	t.Log("It waits for rukpak to observe the new BundleDeployment spec")
+	setBundleDeploymentInstalled(stale)
+	cond = reconcileAndGetInstalled()
+	assert.Equal(t, metav1.ConditionUnknown, cond.Status)
+	assert.Equal(t, "bundledeployment status is out of date", cond.Message)
+
+	setBundleDeploymentInstalled(current)
+	cond = reconcileAndGetInstalled()
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.Equal(t, fmt.Sprintf("installed from ConfigMaps %q", cmName), cond.Message)
+	assert.NotNil(t, clusterExtension.Status.InstalledBundle)
+	assert.Equal(t, ocv1alpha1.BundleMetadata{Name: cmName, Version: "2.1.0"}, clusterExtension.Status.InstalledBundle.BundleMetadata)
+	require.Empty(t, recorder.Events)
+}
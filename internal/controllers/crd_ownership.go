@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// checkCRDOwnership returns an error naming every CustomResourceDefinition the bundle
+// provides that another ClusterExtension already provides: either a CRD on the
+// cluster that rukpak installed for another ClusterExtension's BundleDeployment, or
+// an API in the bundle another ClusterExtension has installed. otherInstalled holds
+// the installed bundles if resolution looked them up. Like ProvidingAPI, it treats a
+// bundle whose olm.gvk properties cannot be read as providing no APIs.
+func (r *ClusterExtensionReconciler) checkCRDOwnership(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, otherInstalled map[string]*catalogmetadata.Bundle) error {
+	providedGVKs, err := bundle.ProvidedGVKs()
+	if err != nil || len(providedGVKs) == 0 {
+		return nil
+	}
+
+	// Only CRDs rukpak installed can be owned by another ClusterExtension.
+	crds := apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, &crds, client.MatchingLabels{rukpakOwnerKindKey: rukpakv1alpha2.BundleDeploymentKind}); err != nil {
+		return err
+	}
+	installedBundles, err := r.otherInstalledBundlesFor(ctx, ext, otherInstalled)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	checked := sets.New[string]()
+	for _, gvk := range providedGVKs {
+		groupKind := gvk.Kind + "." + gvk.Group
+		if checked.Has(groupKind) {
+			continue
+		}
+		checked.Insert(groupKind)
+
+		if crd, owner := crdOwner(crds.Items, gvk); owner != "" && owner != ext.Name {
+			conflicts = append(conflicts, fmt.Sprintf("CRD %q is owned by ClusterExtension %q", crd, owner))
+			continue
+		}
+		for _, name := range sets.List(sets.KeySet(installedBundles)) {
+			if catalogfilter.ProvidingAPI(gvk.Group, gvk.Kind)(installedBundles[name]) {
+				conflicts = append(conflicts, fmt.Sprintf("API %s is provided by ClusterExtension %q with bundle %q", groupKind, name, installedBundles[name].Name))
+				break
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("bundle %q provides CRDs that another ClusterExtension already provides: %s", bundle.Name, strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// crdOwner returns the name of the CustomResourceDefinition for the GVK's group and
+// kind and the name of the BundleDeployment rukpak installed it for, which is the
+// name of the owning ClusterExtension. The owner is empty if no BundleDeployment
+// owns the CRD.
+func crdOwner(crds []apiextensionsv1.CustomResourceDefinition, gvk property.GVK) (string, string) {
+	for i := range crds {
+		crd := &crds[i]
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		if crd.Labels[rukpakOwnerKindKey] != rukpakv1alpha2.BundleDeploymentKind {
+			return crd.Name, ""
+		}
+		return crd.Name, crd.Labels[rukpakOwnerNameKey]
+	}
+	return "", ""
+}
+
+// crdReader returns the reader for listing every CustomResourceDefinition on the
+// cluster.
+func (r *ClusterExtensionReconciler) crdReader() client.Reader {
+	if r.CRDReader != nil {
+		return r.CRDReader
+	}
+	return r.Client
+}
@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionCRDOwnership(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, group, kind string) *catalogmetadata.Bundle {
+		return testutil.NewBundle(pkg, "1.0.0", property.MustBuildGVK(group, "v1", kind))
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "widgets.example.com", "Widget"),
+		bundle("widgets-fork", "widgets.example.com", "Widget"),
+		bundle("gadgets", "gadgets.example.com", "Gadget"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name, pkg string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err != nil {
+			ext = &ocv1alpha1.ClusterExtension{
+				ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+				Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg},
+			}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	requireInstallFailed := func(ext *ocv1alpha1.ClusterExtension, message string) {
+		require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "CRDOwnership", Message: message})
+		cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+	}
+
+	t.Log("It rejects a bundle providing an API that another ClusterExtension's installed bundle provides")
+	_, err := reconcile("widgets", "widgets")
+	require.NoError(t, err)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + "widgets"}, bd))
+	bd.Status.ObservedGeneration = bd.GetGeneration()
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+		Message: "installed",
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+	_, err = reconcile("widgets", "widgets")
+	require.NoError(t, err)
+
+	ext, err := reconcile("widgets-fork", "widgets-fork")
+	message := fmt.Sprintf(`bundle "widgets-fork.v1.0.0" provides CRDs that another ClusterExtension already provides: API Widget.widgets.example.com is provided by ClusterExtension %q with bundle "widgets.v1.0.0"`, prefix+"widgets")
+	require.EqualError(t, err, message)
+	requireInstallFailed(ext, message)
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: prefix + "widgets-fork"}, &rukpakv1alpha2.BundleDeployment{})))
+
+	t.Log("It rejects a bundle providing a CRD that rukpak installed for another ClusterExtension")
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "gadgets.gadgets.example.com",
+			Labels: map[string]string{
+				"core.rukpak.io/owner-kind": rukpakv1alpha2.BundleDeploymentKind,
+				"core.rukpak.io/owner-name": prefix + "gadgets-owner",
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "gadgets.example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "gadgets", Singular: "gadget", Kind: "Gadget", ListKind: "GadgetList"},
+			Scope: apiextensionsv1.ClusterScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name: "v1", Served: true, Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"}},
+			}},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, crd))
+	defer func() { require.NoError(t, cl.Delete(ctx, crd)) }()
+
+	ext, err = reconcile("gadgets", "gadgets")
+	message = fmt.Sprintf(`bundle "gadgets.v1.0.0" provides CRDs that another ClusterExtension already provides: CRD "gadgets.gadgets.example.com" is owned by ClusterExtension %q`, prefix+"gadgets-owner")
+	require.EqualError(t, err, message)
+	requireInstallFailed(ext, message)
+
+	t.Log("It accepts a bundle providing CRDs the ClusterExtension itself owns")
+	ext, err = reconcile("gadgets-owner", "gadgets")
+	require.NoError(t, err)
+	require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "CRDOwnership", Passed: true})
+}
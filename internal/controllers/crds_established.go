@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// rukpak labels every object it applies for a BundleDeployment with the
+// kind and name of its owner.
+var (
+	rukpakOwnerKindKey = "core.rukpak.io/owner-kind"
+	rukpakOwnerNameKey = "core.rukpak.io/owner-name"
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+// setCRDsEstablishedStatus sets the CRDsEstablished condition based on whether every
+// CustomResourceDefinition rukpak installed for the ClusterExtension's BundleDeployment
+// has been established by the API server.
+func (r *ClusterExtensionReconciler) setCRDsEstablishedStatus(ctx context.Context, ext *ocv1alpha1.ClusterExtension) error {
+	crds, err := r.bundleCRDs(ctx, ext.GetName())
+	if err != nil {
+		err = fmt.Errorf("error listing CRDs installed by the bundle: %w", err)
+		setCRDsEstablishedStatusConditionUnknown(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		return err
+	}
+
+	if len(crds.Items) == 0 {
+		setCRDsEstablishedStatusConditionSuccess(&ext.Status.Conditions, "bundle has no CRDs", ext.GetGeneration())
+		return nil
+	}
+
+	var notEstablished []string
+	for _, crd := range crds.Items {
+		if !crdEstablished(&crd) {
+			notEstablished = append(notEstablished, crd.GetName())
+		}
+	}
+	if len(notEstablished) > 0 {
+		sort.Strings(notEstablished)
+		setCRDsEstablishedStatusConditionFailed(
+			&ext.Status.Conditions,
+			fmt.Sprintf("CRDs not yet established: %s", strings.Join(notEstablished, ", ")),
+			ext.GetGeneration(),
+		)
+		return nil
+	}
+	setCRDsEstablishedStatusConditionSuccess(&ext.Status.Conditions, fmt.Sprintf("%d CRDs established", len(crds.Items)), ext.GetGeneration())
+	return nil
+}
+
+// bundleCRDs lists the CustomResourceDefinitions rukpak installed for the named BundleDeployment.
+func (r *ClusterExtensionReconciler) bundleCRDs(ctx context.Context, bundleDeploymentName string) (*apiextensionsv1.CustomResourceDefinitionList, error) {
+	crds := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, crds, client.MatchingLabels{
+		rukpakOwnerKindKey: rukpakv1alpha2.BundleDeploymentKind,
+		rukpakOwnerNameKey: bundleDeploymentName,
+	}); err != nil {
+		return nil, err
+	}
+	return crds, nil
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established {
+			return cond.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// clusterExtensionRequestsForBundleObject enqueues the ClusterExtension whose
+// BundleDeployment installed the given object, e.g. a CustomResourceDefinition.
+func clusterExtensionRequestsForBundleObject(_ context.Context, obj client.Object) []reconcile.Request {
+	objLabels := obj.GetLabels()
+	if objLabels[rukpakOwnerKindKey] != rukpakv1alpha2.BundleDeploymentKind || objLabels[rukpakOwnerNameKey] == "" {
+		return nil
+	}
+	// BundleDeployments are named after the ClusterExtension that owns them.
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: objLabels[rukpakOwnerNameKey]}}}
+}
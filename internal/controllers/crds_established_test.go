@@ -0,0 +1,111 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionCRDsEstablished(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	crdGroup := fmt.Sprintf("%s.example.com", rand.String(8))
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "widgets." + crdGroup,
+			Labels: map[string]string{
+				"core.rukpak.io/owner-kind": rukpakv1alpha2.BundleDeploymentKind,
+				"core.rukpak.io/owner-name": extKey.Name,
+			},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: crdGroup,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Singular: "widget", Kind: "Widget", ListKind: "WidgetList"},
+			Scope: apiextensionsv1.ClusterScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    "v1",
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"},
+				},
+			}},
+		},
+	}
+	deleteAllOnCleanup(t, cl)
+	defer func() {
+		require.NoError(t, cl.Delete(ctx, crd))
+	}()
+
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconcileAndGetCondition := func() *metav1.Condition {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeCRDsEstablished)
+		require.NotNil(t, cond)
+		return cond
+	}
+
+	t.Log("It does not check CRDs before the bundle is installed")
+	cond := reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+	require.Equal(t, "CRDs have not been checked as installation has not completed", cond.Message)
+
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: extKey.Name}, bd))
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Message: "installed",
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+
+	t.Log("It reports success when the bundle installed no CRDs")
+	cond = reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonSuccess, cond.Reason)
+	require.Equal(t, "bundle has no CRDs", cond.Message)
+
+	t.Log("It reports success once the bundle's CRDs are established")
+	require.NoError(t, cl.Create(ctx, crd))
+	require.Eventually(t, func() bool {
+		got := &apiextensionsv1.CustomResourceDefinition{}
+		if err := cl.Get(ctx, types.NamespacedName{Name: crd.Name}, got); err != nil {
+			return false
+		}
+		for _, c := range got.Status.Conditions {
+			if c.Type == apiextensionsv1.Established && c.Status == apiextensionsv1.ConditionTrue {
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 100*time.Millisecond)
+	cond = reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonSuccess, cond.Reason)
+	require.Equal(t, "1 CRDs established", cond.Message)
+}
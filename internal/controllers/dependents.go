@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// dependentConstraint is a requirement that another installed ClusterExtension's
+// bundle places on the bundle of the ClusterExtension being resolved.
+type dependentConstraint struct {
+	dependent   string
+	description string
+	satisfiedBy catalogfilter.Predicate[catalogmetadata.Bundle]
+}
+
+func (c dependentConstraint) String() string {
+	return fmt.Sprintf("ClusterExtension %q requires %s", c.dependent, c.description)
+}
+
+// dependentConstraints returns the requirements that the bundles installed by other
+// ClusterExtensions place on the installed bundle of ext: required package version
+// ranges for its package and required APIs that it currently provides. Dependents
+// whose installed bundle can no longer be found in a catalog are not considered.
+func (r *ClusterExtensionReconciler) dependentConstraints(ctx context.Context, allBundles []*catalogmetadata.Bundle, ext *ocv1alpha1.ClusterExtension, installedBundle *catalogmetadata.Bundle) ([]dependentConstraint, error) {
+	if installedBundle == nil {
+		return nil, nil
+	}
+	clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, &clusterExtensions); err != nil {
+		return nil, err
+	}
+
+	var constraints []dependentConstraint
+	for i := range clusterExtensions.Items {
+		dependent := &clusterExtensions.Items[i]
+		if dependent.Name == ext.Name || dependent.Spec.ConfigMapBundle != nil || dependent.Spec.BundleImage != nil {
+			continue
+		}
+		dependentBundle, err := r.installedBundle(ctx, allBundles, dependent)
+		if err != nil || dependentBundle == nil {
+			continue
+		}
+
+		requiredPackages, err := dependentBundle.RequiredPackages()
+		if err != nil {
+			return nil, err
+		}
+		for _, required := range requiredPackages {
+			if required.PackageName != installedBundle.Package {
+				continue
+			}
+			constraints = append(constraints, dependentConstraint{
+				dependent:   dependent.Name,
+				description: fmt.Sprintf("package %q in range %q", required.PackageName, required.VersionRange),
+				satisfiedBy: catalogfilter.InBlangSemverRange(required.SemverRange),
+			})
+		}
+
+		requiredGVKs, err := dependentBundle.RequiredGVKs()
+		if err != nil {
+			return nil, err
+		}
+		for _, required := range requiredGVKs {
+			gvk := property.GVK{Group: required.Group, Kind: required.Kind, Version: required.Version}
+			if !catalogfilter.ProvidingGVK(gvk)(installedBundle) {
+				continue
+			}
+			constraints = append(constraints, dependentConstraint{
+				dependent:   dependent.Name,
+				description: fmt.Sprintf("API %s/%s %s", gvk.Group, gvk.Version, gvk.Kind),
+				satisfiedBy: catalogfilter.ProvidingGVK(gvk),
+			})
+		}
+	}
+	return constraints, nil
+}
+
+// applyDependentConstraints returns the candidates that satisfy every constraint,
+// in their original order, and the constraints violated by the most preferred candidate
+// if it was excluded.
+func applyDependentConstraints(candidates []*catalogmetadata.Bundle, constraints []dependentConstraint) ([]*catalogmetadata.Bundle, []dependentConstraint) {
+	if len(constraints) == 0 || len(candidates) == 0 {
+		return candidates, nil
+	}
+	var violated []dependentConstraint
+	for _, c := range constraints {
+		if !c.satisfiedBy(candidates[0]) {
+			violated = append(violated, c)
+		}
+	}
+	compatible := catalogfilter.Filter(candidates, func(b *catalogmetadata.Bundle) bool {
+		for _, c := range constraints {
+			if !c.satisfiedBy(b) {
+				return false
+			}
+		}
+		return true
+	})
+	return compatible, violated
+}
+
+// dependentConstraintError reports that no candidate satisfies the violated constraints.
+func dependentConstraintError(ext *ocv1alpha1.ClusterExtension, violated []dependentConstraint) error {
+	descriptions := make([]string, 0, len(violated))
+	for _, c := range violated {
+		descriptions = append(descriptions, c.String())
+	}
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonDependentConstraintViolation,
+		err:    fmt.Errorf("no bundle of %s satisfies the ClusterExtensions that depend on it: %s", describePackage(ext), strings.Join(descriptions, "; ")),
+	}
+}
+
+// heldBackStatus describes the violated constraints for status.resolution.heldBackBy.
+func heldBackStatus(violated []dependentConstraint) []ocv1alpha1.DependentConstraint {
+	var result []ocv1alpha1.DependentConstraint
+	for _, c := range violated {
+		result = append(result, ocv1alpha1.DependentConstraint{ClusterExtension: c.dependent, Constraint: c.description})
+	}
+	return result
+}
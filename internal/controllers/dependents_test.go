@@ -0,0 +1,109 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionDependentConstraints(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, version string, channel *catalogmetadata.Channel, properties ...property.Property) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, version, properties...)
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		return b
+	}
+	widgetsChannel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.0.0"},
+			{Name: "widgets.v1.1.0", Replaces: "widgets.v1.0.0"},
+			{Name: "widgets.v2.0.0", Replaces: "widgets.v1.1.0", Skips: []string{"widgets.v1.0.0"}},
+		},
+	}}
+	dependentsChannel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "gadgets",
+		Entries: []declcfg.ChannelEntry{{Name: "gadgets.v1.0.0"}, {Name: "sprockets.v1.0.0"}},
+	}}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "1.0.0", &widgetsChannel, property.MustBuildGVK("example.com", "v1", "Widget")),
+		bundle("widgets", "1.1.0", &widgetsChannel, property.MustBuildGVK("example.com", "v1", "Widget"), property.MustBuildGVK("example.com", "v2", "Widget")),
+		bundle("widgets", "2.0.0", &widgetsChannel, property.MustBuildGVK("example.com", "v2", "Widget")),
+		bundle("gadgets", "1.0.0", &dependentsChannel, property.MustBuildGVKRequired("example.com", "v1", "Widget")),
+		bundle("sprockets", "1.0.0", &dependentsChannel, property.MustBuildPackageRequired("widgets", "<2.0.0")),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name string, spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err == nil {
+			ext.Spec = spec
+			require.NoError(t, cl.Update(ctx, ext))
+		} else {
+			ext = &ocv1alpha1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name}, Spec: spec}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	// The dependents' bundles declare dependencies on widgets, which is never reported as
+	// installed here, so they only pass preflight checks in Warn mode.
+	warn := &ocv1alpha1.PreflightConfig{Mode: ocv1alpha1.PreflightModeWarn}
+
+	t.Log("By installing widgets and two extensions that depend on it")
+	_, err := reconcile("widgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.0.0"})
+	require.NoError(t, err)
+	_, err = reconcile("gadgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "gadgets", Preflight: warn})
+	require.NoError(t, err)
+	_, err = reconcile("sprockets", ocv1alpha1.ClusterExtensionSpec{PackageName: "sprockets", Preflight: warn})
+	require.NoError(t, err)
+
+	t.Log("It upgrades widgets only as far as its dependents allow")
+	ext, err := reconcile("widgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, []ocv1alpha1.DependentConstraint{
+		{ClusterExtension: prefix + "gadgets", Constraint: "API example.com/v1 Widget"},
+		{ClusterExtension: prefix + "sprockets", Constraint: `package "widgets" in range "<2.0.0"`},
+	}, ext.Status.Resolution.HeldBackBy)
+
+	t.Log("It fails resolution when no allowed bundle satisfies the dependents")
+	ext, err = reconcile("widgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "2.0.0"})
+	wantErr := fmt.Sprintf(`no bundle of package "widgets" satisfies the ClusterExtensions that depend on it: `+
+		`ClusterExtension %q requires API example.com/v1 Widget; ClusterExtension %q requires package "widgets" in range "<2.0.0"`,
+		prefix+"gadgets", prefix+"sprockets")
+	require.EqualError(t, err, wantErr)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonDependentConstraintViolation, cond.Reason)
+	require.Equal(t, wantErr, cond.Message)
+	require.Empty(t, ext.Status.Resolution.HeldBackBy)
+}
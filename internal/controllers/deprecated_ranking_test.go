@@ -0,0 +1,78 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionPrefersNonDeprecatedChannels(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	stable := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{{Name: "widgets.v1.0.0"}},
+	}}
+	legacy := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "legacy",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{{Name: "widgets.v2.0.0"}},
+	}}
+	bundle := func(version string, channel *catalogmetadata.Channel) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		b.Deprecations = []declcfg.DeprecationEntry{{
+			Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaChannel, Name: "legacy"},
+			Message:   "the legacy channel is no longer maintained",
+		}}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0", stable),
+		bundle("2.0.0", legacy),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(spec ocv1alpha1.ClusterExtensionSpec) *ocv1alpha1.ClusterExtension {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext
+	}
+
+	t.Log("It prefers a bundle in a non-deprecated channel over a newer bundle only in deprecated channels")
+	ext := reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"})
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.False(t, ext.Status.Resolution.DeprecatedFallback)
+
+	t.Log("It falls back to deprecated content when nothing else matches, and flags it")
+	ext = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Channel: "legacy"})
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"}, ext.Status.ResolvedBundle)
+	require.True(t, ext.Status.Resolution.DeprecatedFallback)
+}
@@ -0,0 +1,102 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionAllowDowngrade(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.0.0"},
+			{Name: "widgets.v1.1.0", Replaces: "widgets.v1.0.0"},
+			{Name: "widgets.v1.2.0", Replaces: "widgets.v1.1.0"},
+			{Name: "widgets.v2.0.0"},
+		},
+	}}
+	bundle := func(version string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0"),
+		bundle("1.1.0"),
+		bundle("1.2.0"),
+		bundle("2.0.0"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	// change installs version 1.1.0 of the package, then changes the version with the
+	// given policy and reconciles again.
+	change := func(policy ocv1alpha1.UpgradeConstraintPolicy, version string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.1.0"},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		ext.Spec.UpgradeConstraintPolicy = policy
+		ext.Spec.Version = version
+		require.NoError(t, cl.Update(ctx, ext))
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It does not downgrade with Enforce")
+	ext, err := change(ocv1alpha1.UpgradeConstraintPolicyEnforce, "1.0.0")
+	require.EqualError(t, err, `error upgrading from currently installed version "1.1.0": no package "widgets" matching version "1.0.0" found`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+
+	t.Log("It downgrades to a lowered version with AllowDowngrade")
+	ext, err = change(ocv1alpha1.UpgradeConstraintPolicyAllowDowngrade, "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: ext.Name}, bd))
+	require.Equal(t, "quay.io/example/widgets@fake1.0.0", bd.Spec.Source.Image.Ref)
+
+	t.Log("It still only upgrades along the upgrade graph with AllowDowngrade")
+	ext, err = change(ocv1alpha1.UpgradeConstraintPolicyAllowDowngrade, "")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.2.0", Version: "1.2.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It explains a failed resolution with the downgrades it considered")
+	ext, err = change(ocv1alpha1.UpgradeConstraintPolicyAllowDowngrade, "2.0.0")
+	require.Error(t, err)
+	require.NotNil(t, ext.Status.Resolution.Explanation)
+	require.Contains(t, ext.Status.Resolution.Explanation.Constraints, `upgrade or downgrade of installed bundle "widgets.v1.1.0"`)
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// dryRunChanges compares the desired BundleDeployment with the existing one and
+// describes each field that applying it would change. Only the fields set in the
+// desired BundleDeployment are compared, as applying it leaves the others alone.
+func (r *ClusterExtensionReconciler) dryRunChanges(ctx context.Context, desired *unstructured.Unstructured) ([]string, error) {
+	current := map[string]string{}
+	existing, err := r.existingBundleDeploymentUnstructured(ctx, desired.GetName())
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	if existing != nil {
+		if current, err = normalizedFields(existing.Object); err != nil {
+			return nil, err
+		}
+	}
+	want, err := normalizedFields(desired.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	for path, value := range want {
+		if currentValue, ok := current[path]; !ok || currentValue != value {
+			if !ok {
+				currentValue = "null"
+			}
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", path, currentValue, value))
+		}
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// dryRunObjectSize counts the objects a configMapBundle install would create and
+// estimates their size from their JSON encodings. Other bundles are rendered by rukpak,
+// so it leaves their count unset.
+func (r *ClusterExtensionReconciler) dryRunObjectSize(ctx context.Context, ext *ocv1alpha1.ClusterExtension, status *ocv1alpha1.DryRunStatus) error {
+	if ext.Spec.ConfigMapBundle == nil {
+		return nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: r.BundleConfigMapNamespace, Name: ext.Spec.ConfigMapBundle.Name}, cm); err != nil {
+		return fmt.Errorf("error getting bundle ConfigMap %q: %w", ext.Spec.ConfigMapBundle.Name, err)
+	}
+	objs, err := configMapManifests(cm)
+	if err != nil {
+		return fmt.Errorf("bundle ConfigMap %q has invalid content: %w", cm.Name, err)
+	}
+	var size int64
+	for i := range objs {
+		data, err := json.Marshal(objs[i].Object)
+		if err != nil {
+			return err
+		}
+		size += int64(len(data))
+	}
+	status.ObjectCount = ptr.To(int32(len(objs)))
+	status.EstimatedBytes = ptr.To(size)
+	return nil
+}
+
+// normalizedFields flattens the metadata labels, annotations and owner references and
+// the spec of an object into dotted paths mapped to the JSON encoding of their values.
+func normalizedFields(obj map[string]interface{}) (map[string]string, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var generic map[string]interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{}
+	var flatten func(path string, value interface{}) error
+	flatten = func(path string, value interface{}) error {
+		if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+			for k, v := range m {
+				if err := flatten(path+"."+k, v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fields[path] = string(encoded)
+		return nil
+	}
+	metadata, _ := generic["metadata"].(map[string]interface{})
+	for _, key := range []string{"labels", "annotations", "ownerReferences"} {
+		if value, ok := metadata[key]; ok {
+			if err := flatten("metadata."+key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if spec, ok := generic["spec"]; ok {
+		if err := flatten("spec", spec); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
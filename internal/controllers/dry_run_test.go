@@ -0,0 +1,133 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionDryRun(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Channel: "beta", Version: "1.0.0", DryRun: true},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) {
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		mutate(&ext.Spec)
+		require.NoError(t, cl.Update(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+	}
+
+	t.Log("It reports the BundleDeployment that would be created without creating it")
+	reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, extKey, &rukpakv1alpha2.BundleDeployment{})))
+	require.NotNil(t, ext.Status.DryRun)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, ext.Status.DryRun.Bundle)
+	require.Contains(t, ext.Status.DryRun.Changes, `spec.source.image.ref: null -> "quay.io/operatorhubio/prometheus@fake1.0.0"`)
+	require.Nil(t, ext.Status.DryRun.ObjectCount)
+	require.Nil(t, ext.Status.DryRun.EstimatedBytes)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonDryRun, cond.Reason)
+
+	t.Log("It installs the bundle once dryRun is unset")
+	reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.DryRun = false })
+	require.Nil(t, ext.Status.DryRun)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, extKey, bd))
+
+	t.Log("It reports only the fields an upgrade would change")
+	reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.DryRun = true
+		spec.Version = "1.0.1"
+	})
+	require.Equal(t, []string{
+		`metadata.annotations.olm.operatorframework.io/bundleName: "operatorhub/prometheus/beta/1.0.0" -> "operatorhub/prometheus/beta/1.0.1"`,
+		`metadata.annotations.olm.operatorframework.io/bundleVersion: "1.0.0" -> "1.0.1"`,
+		`spec.source.image.ref: "quay.io/operatorhubio/prometheus@fake1.0.0" -> "quay.io/operatorhubio/prometheus@fake1.0.1"`,
+	}, ext.Status.DryRun.Changes)
+	require.NoError(t, cl.Get(ctx, extKey, bd))
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+
+	t.Log("It reports no changes when the BundleDeployment is up to date")
+	reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "1.0.0" })
+	require.Empty(t, ext.Status.DryRun.Changes)
+}
+
+func TestClusterExtensionDryRunConfigMapBundleObjectSize(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.BundleConfigMapNamespace = "default"
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+	defer func() {
+		require.NoError(t, cl.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("default")))
+	}()
+
+	cmName := fmt.Sprintf("bundle-%s", rand.String(8))
+	require.NoError(t, cl.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: "default",
+			Annotations: map[string]string{
+				"olm.operatorframework.io/package":       "my-operator",
+				"olm.operatorframework.io/bundleVersion": "1.0.0",
+			},
+		},
+		Data: map[string]string{"manifests.yaml": configMapBundleManifests},
+	}))
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			ConfigMapBundle: &ocv1alpha1.ConfigMapBundle{Name: cmName},
+			DryRun:          true,
+		},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(ctx, extKey, ext))
+	verifyInvariants(ctx, t, reconciler.Client, ext)
+
+	var size int64
+	for _, obj := range []map[string]interface{}{
+		{"apiVersion": "v1", "kind": "ServiceAccount", "metadata": map[string]interface{}{"name": "my-operator"}},
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "my-operator-config"}},
+	} {
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		size += int64(len(data))
+	}
+	require.NotNil(t, ext.Status.DryRun)
+	require.Equal(t, ptr.To(int32(2)), ext.Status.DryRun.ObjectCount)
+	require.Equal(t, ptr.To(size), ext.Status.DryRun.EstimatedBytes)
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, extKey, &rukpakv1alpha2.BundleDeployment{})))
+}
@@ -0,0 +1,7 @@
+package controllers
+
+// Unexported functions that the controllers_test package tests.
+var (
+	CatalogContentChanged              = catalogContentChanged
+	ClusterExtensionRequestsForCatalog = (*ClusterExtensionReconciler).clusterExtensionRequestsForCatalog
+)
@@ -58,10 +58,6 @@ type ExtensionReconciler struct {
 	BundleProvider BundleProvider
 }
 
-var (
-	bundleVersionKey = "olm.operatorframework.io/bundleVersion"
-)
-
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=extensions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=extensions/status,verbs=update;patch
 //+kubebuilder:rbac:groups=olm.operatorframework.io,resources=extensions/finalizers,verbs=update
@@ -189,6 +185,16 @@ func (r *ExtensionReconciler) reconcile(ctx context.Context, ext *ocv1alpha1.Ext
 		return ctrl.Result{}, nil
 	}
 
+	if err := namespacedFootprint(bundle); err != nil {
+		if c := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled); c == nil {
+			ext.Status.InstalledBundle = nil
+			setInstalledStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+			setDeprecationStatusesUnknown(&ext.Status.Conditions, "deprecation checks have not been attempted as installation has failed", ext.GetGeneration())
+		}
+		setProgressingStatusConditionFailed(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		return ctrl.Result{}, nil
+	}
+
 	app, err := r.GenerateExpectedApp(*ext, bundle)
 	if err != nil {
 		if c := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled); c == nil {
@@ -456,7 +462,12 @@ func (r *ExtensionReconciler) GenerateExpectedApp(o ocv1alpha1.Extension, bundle
 		},
 		"deploy": []interface{}{
 			map[string]interface{}{
-				"kapp": map[string]interface{}{},
+				// Keep every namespaced object in the Extension's namespace. Cluster-scoped
+				// objects are left to the RBAC of the service account, which a tenant
+				// admin can only grant within the namespace.
+				"kapp": map[string]interface{}{
+					"intoNs": o.GetNamespace(),
+				},
 			},
 		},
 	}
@@ -489,6 +500,24 @@ func (r *ExtensionReconciler) GenerateExpectedApp(o ocv1alpha1.Extension, bundle
 	return app, nil
 }
 
+// namespacedFootprint returns an error if the bundle is known to need cluster-scoped
+// permissions, which an Extension cannot grant. Bundles that provide APIs ship the
+// CustomResourceDefinitions for them, and those are cluster-scoped.
+func namespacedFootprint(bundle *catalogmetadata.Bundle) error {
+	gvks, err := bundle.ProvidedGVKs()
+	if err != nil {
+		return err
+	}
+	if len(gvks) == 0 {
+		return nil
+	}
+	apis := make([]string, 0, len(gvks))
+	for _, gvk := range gvks {
+		apis = append(apis, fmt.Sprintf("%s/%s, Kind=%s", gvk.Group, gvk.Version, gvk.Kind))
+	}
+	return fmt.Errorf("bundle %q provides APIs %s whose CustomResourceDefinitions are cluster-scoped; use a ClusterExtension to install it", bundle.Name, strings.Join(apis, ", "))
+}
+
 func (r *ExtensionReconciler) getInstalledVersion(ctx context.Context, namespacedName types.NamespacedName) (*bsemver.Version, error) {
 	existingApp, err := r.existingAppUnstructured(ctx, namespacedName.Name, namespacedName.Namespace)
 	if err != nil {
@@ -2,12 +2,14 @@ package controllers_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	carvelv1alpha1 "github.com/vmware-tanzu/carvel-kapp-controller/pkg/apis/kappctrl/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -15,9 +17,14 @@ import (
 	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/operator-framework/operator-registry/alpha/property"
+
 	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
 	"github.com/operator-framework/operator-controller/internal/conditionsets"
+	"github.com/operator-framework/operator-controller/internal/controllers"
 	"github.com/operator-framework/operator-controller/pkg/features"
+	testutil "github.com/operator-framework/operator-controller/test/util"
 )
 
 const (
@@ -220,6 +227,59 @@ func TestExtensionResolve(t *testing.T) {
 	}
 }
 
+func TestExtensionNamespacedFootprint(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.EnableExtensionAPI, true)()
+	ctx := context.Background()
+
+	bundle := func(pkg string, gvks ...string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, "1.0.0",
+			property.Property{Type: catalogmetadata.PropertyBundleMediaType, Value: json.RawMessage(`"plain+v0"`)})
+		for _, gvk := range gvks {
+			b.Properties = append(b.Properties, property.Property{Type: property.TypeGVK, Value: json.RawMessage(gvk)})
+		}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets"),
+		bundle("gadgets", `{"group":"example.com","kind":"Gadget","version":"v1"}`),
+	})
+	c := newClient(t)
+	reconciler := &controllers.ExtensionReconciler{Client: c, BundleProvider: &fakeCatalogClient}
+
+	reconcile := func(pkg string) *ocv1alpha1.Extension {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("extension-test-%s", rand.String(8)), Namespace: "default"}
+		ext := &ocv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name, Namespace: extKey.Namespace},
+			Spec: ocv1alpha1.ExtensionSpec{
+				ServiceAccountName: testServiceAccount,
+				Source:             ocv1alpha1.ExtensionSource{SourceType: ocv1alpha1.SourceTypePackage, Package: &ocv1alpha1.ExtensionSourcePackage{Name: pkg}},
+			},
+		}
+		require.NoError(t, c.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, c.Get(ctx, extKey, ext))
+		return ext
+	}
+
+	t.Log("It keeps the objects of the bundle in the Extension's namespace")
+	ext := reconcile("widgets")
+	app := &carvelv1alpha1.App{}
+	require.NoError(t, c.Get(ctx, types.NamespacedName{Name: ext.GetName(), Namespace: ext.GetNamespace()}, app))
+	require.Len(t, app.Spec.Deploy, 1)
+	require.NotNil(t, app.Spec.Deploy[0].Kapp)
+	require.Equal(t, "default", app.Spec.Deploy[0].Kapp.IntoNs)
+
+	t.Log("It refuses bundles that provide APIs")
+	ext = reconcile("gadgets")
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeProgressing)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, `bundle "gadgets.v1.0.0" provides APIs example.com/v1, Kind=Gadget whose CustomResourceDefinitions are cluster-scoped; use a ClusterExtension to install it`, cond.Message)
+	require.Nil(t, ext.Status.InstalledBundle)
+	require.True(t, apierrors.IsNotFound(c.Get(ctx, types.NamespacedName{Name: ext.GetName(), Namespace: ext.GetNamespace()}, &carvelv1alpha1.App{})))
+}
+
 func verifyExtensionInvariants(t *testing.T, ext *ocv1alpha1.Extension) {
 	verifyExtensionConditionsInvariants(t, ext)
 }
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+var apiServiceGVK = schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"}
+
+// newAPIService returns an empty APIService. APIServices are handled as unstructured
+// objects to avoid a dependency on the aggregator's API types.
+func newAPIService() *unstructured.Unstructured {
+	apiService := &unstructured.Unstructured{}
+	apiService.SetGroupVersionKind(apiServiceGVK)
+	return apiService
+}
+
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apiregistration.k8s.io,resources=apiservices,verbs=get;list;watch
+
+// setHealthyStatus sets the Healthy condition based on the current state of the
+// objects rukpak installed for the ClusterExtension's BundleDeployment: every
+// Deployment must be Available, every CustomResourceDefinition Established and
+// every APIService Available. A bundle without Deployments or APIServices, such as one
+// that only ships CRDs, has no workloads to wait for and is reported as NoWorkloads.
+func (r *ClusterExtensionReconciler) setHealthyStatus(ctx context.Context, ext *ocv1alpha1.ClusterExtension) error {
+	unhealthy, checked, workloads, err := r.unhealthyBundleObjects(ctx, ext.GetName())
+	if err != nil {
+		err = fmt.Errorf("error checking the health of the objects installed by the bundle: %w", err)
+		setHealthyStatusConditionUnknown(&ext.Status.Conditions, err.Error(), ext.GetGeneration())
+		return err
+	}
+	if len(unhealthy) > 0 {
+		sort.Strings(unhealthy)
+		setHealthyStatusConditionFailed(&ext.Status.Conditions, fmt.Sprintf("unhealthy objects: %s", strings.Join(unhealthy, ", ")), ext.GetGeneration())
+		return nil
+	}
+	if workloads == 0 {
+		setHealthyStatusConditionNoWorkloads(&ext.Status.Conditions, fmt.Sprintf("no workloads to check, %d objects healthy", checked), ext.GetGeneration())
+		return nil
+	}
+	setHealthyStatusConditionSuccess(&ext.Status.Conditions, fmt.Sprintf("%d objects healthy", checked), ext.GetGeneration())
+	return nil
+}
+
+// unhealthyBundleObjects describes the health-checked objects rukpak installed for the
+// named BundleDeployment that are not healthy, and returns how many objects were checked
+// and how many of them were Deployments or APIServices.
+func (r *ClusterExtensionReconciler) unhealthyBundleObjects(ctx context.Context, bundleDeploymentName string) ([]string, int, int, error) {
+	ownedBy := client.MatchingLabels{
+		rukpakOwnerKindKey: rukpakv1alpha2.BundleDeploymentKind,
+		rukpakOwnerNameKey: bundleDeploymentName,
+	}
+	var unhealthy []string
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, ownedBy); err != nil {
+		return nil, 0, 0, err
+	}
+	for _, deployment := range deployments.Items {
+		if !deploymentAvailable(&deployment) {
+			unhealthy = append(unhealthy, fmt.Sprintf("Deployment %s/%s is not available", deployment.GetNamespace(), deployment.GetName()))
+		}
+	}
+
+	crds, err := r.bundleCRDs(ctx, bundleDeploymentName)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	for _, crd := range crds.Items {
+		if !crdEstablished(&crd) {
+			unhealthy = append(unhealthy, fmt.Sprintf("CustomResourceDefinition %s is not established", crd.GetName()))
+		}
+	}
+
+	apiServices := &unstructured.UnstructuredList{}
+	apiServices.SetGroupVersionKind(apiServiceGVK.GroupVersion().WithKind(apiServiceGVK.Kind + "List"))
+	if err := r.Client.List(ctx, apiServices, ownedBy); err != nil {
+		return nil, 0, 0, err
+	}
+	for _, apiService := range apiServices.Items {
+		if !apiServiceAvailable(&apiService) {
+			unhealthy = append(unhealthy, fmt.Sprintf("APIService %s is not available", apiService.GetName()))
+		}
+	}
+
+	workloads := len(deployments.Items) + len(apiServices.Items)
+	return unhealthy, workloads + len(crds.Items), workloads, nil
+}
+
+func deploymentAvailable(deployment *appsv1.Deployment) bool {
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func apiServiceAvailable(apiService *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if ok && cond["type"] == "Available" {
+			return cond["status"] == string(corev1.ConditionTrue)
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionHealthy(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	labels := map[string]string{"app": extKey.Name}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      extKey.Name,
+			Namespace: "default",
+			Labels: map[string]string{
+				"core.rukpak.io/owner-kind": rukpakv1alpha2.BundleDeploymentKind,
+				"core.rukpak.io/owner-name": extKey.Name,
+			},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: "quay.io/example/manager:latest"}}},
+			},
+		},
+	}
+	deleteAllOnCleanup(t, cl)
+	defer func() {
+		require.NoError(t, cl.Delete(ctx, deployment))
+	}()
+
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	reconcileAndGetCondition := func() *metav1.Condition {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.Equal(t, ctrl.Result{}, res)
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeHealthy)
+		require.NotNil(t, cond)
+		return cond
+	}
+
+	t.Log("It does not check health before the bundle is installed")
+	cond := reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+	require.Equal(t, "health has not been checked as installation has not completed", cond.Message)
+
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: extKey.Name}, bd))
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Message: "installed",
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+
+	t.Log("It reports NoWorkloads when the bundle installed no Deployments or APIServices")
+	cond = reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonNoWorkloads, cond.Reason)
+	require.Equal(t, "no workloads to check, 0 objects healthy", cond.Message)
+
+	t.Log("It reports the failure while a Deployment is not available")
+	require.NoError(t, cl.Create(ctx, deployment))
+	cond = reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonUnhealthy, cond.Reason)
+	require.Equal(t, fmt.Sprintf("unhealthy objects: Deployment default/%s is not available", extKey.Name), cond.Message)
+	require.Equal(t, ocv1alpha1.AttentionSeverityCritical, clusterExtension.Status.Attention.Severity)
+
+	t.Log("It reports success once the Deployment is available")
+	deployment.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}}
+	require.NoError(t, cl.Status().Update(ctx, deployment))
+	cond = reconcileAndGetCondition()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonSuccess, cond.Reason)
+	require.Equal(t, "1 objects healthy", cond.Message)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// installPendingRequeueInterval is how often a ClusterExtension waiting for an
+// install slot checks whether one has become available.
+const installPendingRequeueInterval = 10 * time.Second
+
+// DefaultStalledInstallTimeout is how long a BundleDeployment that rukpak has not
+// reported an Installed condition for holds an install slot when
+// StalledInstallTimeout is not set.
+const DefaultStalledInstallTimeout = 15 * time.Minute
+
+var pendingInstalls = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "operator_controller_pending_installs",
+		Help: "Number of ClusterExtensions waiting for a concurrent install slot.",
+	},
+)
+
+func init() {
+	metrics.Registry.MustRegister(pendingInstalls)
+}
+
+// installQueue orders the ClusterExtensions waiting for an install slot by the
+// time they started waiting, so that slots are handed out first come, first served.
+type installQueue struct {
+	mu    sync.Mutex
+	names []string
+}
+
+// position returns the position of the named ClusterExtension in the queue,
+// adding it to the back of the queue if it is not already waiting.
+func (q *installQueue) position(name string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, n := range q.names {
+		if n == name {
+			return i
+		}
+	}
+	q.names = append(q.names, name)
+	pendingInstalls.Set(float64(len(q.names)))
+	return len(q.names) - 1
+}
+
+// remove removes the named ClusterExtension from the queue, if it is waiting.
+func (q *installQueue) remove(name string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, n := range q.names {
+		if n == name {
+			q.names = append(q.names[:i], q.names[i+1:]...)
+			pendingInstalls.Set(float64(len(q.names)))
+			return
+		}
+	}
+}
+
+// admitInstall reports whether the desired BundleDeployment may be applied under
+// MaxConcurrentInstalls. Applying it only starts an install when it changes the
+// spec of the existing BundleDeployment, so unchanged BundleDeployments and ones
+// that are already installing are always admitted. If the install is not admitted,
+// the returned message describes why it is waiting.
+func (r *ClusterExtensionReconciler) admitInstall(ctx context.Context, desiredBundleDeployment *unstructured.Unstructured) (bool, string, error) {
+	name := desiredBundleDeployment.GetName()
+	if r.MaxConcurrentInstalls <= 0 {
+		return true, "", nil
+	}
+
+	existingBundleDeployment, err := r.existingBundleDeploymentUnstructured(ctx, name)
+	if client.IgnoreNotFound(err) != nil {
+		return false, "", err
+	}
+	if existingBundleDeployment != nil {
+		existingTypedBundleDeployment := &rukpakv1alpha2.BundleDeployment{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(existingBundleDeployment.UnstructuredContent(), existingTypedBundleDeployment); err != nil {
+			return false, "", err
+		}
+		if equality.Semantic.DeepDerivative(desiredBundleDeployment.Object["spec"], existingBundleDeployment.Object["spec"]) ||
+			r.bundleDeploymentInstalling(existingTypedBundleDeployment, time.Now()) {
+			r.pendingInstalls.remove(name)
+			return true, "", nil
+		}
+	}
+
+	bundleDeployments := rukpakv1alpha2.BundleDeploymentList{}
+	if err := r.Client.List(ctx, &bundleDeployments); err != nil {
+		return false, "", err
+	}
+	installing := 0
+	for i := range bundleDeployments.Items {
+		bd := &bundleDeployments.Items[i]
+		if owner := metav1.GetControllerOf(bd); owner == nil || owner.Kind != "ClusterExtension" || owner.APIVersion != ocv1alpha1.GroupVersion.String() {
+			continue
+		}
+		if bd.Name != name && r.bundleDeploymentInstalling(bd, time.Now()) {
+			installing++
+		}
+	}
+
+	// Only the ClusterExtensions at the front of the queue may take the free slots.
+	position := r.pendingInstalls.position(name)
+	if installing+position < r.MaxConcurrentInstalls {
+		r.pendingInstalls.remove(name)
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("waiting for an install slot: %d of %d concurrent installs in progress, %d ahead in the queue",
+		installing, r.MaxConcurrentInstalls, position), nil
+}
+
+// bundleDeploymentInstalling reports whether rukpak has yet to finish installing
+// the current spec of the BundleDeployment. A BundleDeployment rukpak has not
+// reported an Installed condition for, e.g. one whose bundle cannot be unpacked, is
+// only installing until StalledInstallTimeout after it was created, so that it does
+// not hold an install slot forever.
+func (r *ClusterExtensionReconciler) bundleDeploymentInstalling(bd *rukpakv1alpha2.BundleDeployment, now time.Time) bool {
+	installed := apimeta.FindStatusCondition(bd.Status.Conditions, rukpakv1alpha2.TypeInstalled)
+	if installed == nil {
+		timeout := r.StalledInstallTimeout
+		if timeout <= 0 {
+			timeout = DefaultStalledInstallTimeout
+		}
+		return now.Before(bd.CreationTimestamp.Add(timeout))
+	}
+	observed := bd.Status.ObservedGeneration
+	return observed != 0 && observed != bd.Generation
+}
+
+// waitingForInstallSlot reports whether the reconcile of the ClusterExtension that
+// returned res ended waiting for an install slot. Only such reconciles keep the
+// ClusterExtension's place in the install queue.
+func waitingForInstallSlot(ext *ocv1alpha1.ClusterExtension, res ctrl.Result) bool {
+	installed := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+	return res.RequeueAfter == installPendingRequeueInterval && installed != nil &&
+		installed.Reason == ocv1alpha1.ReasonInstallPending && installed.ObservedGeneration == ext.GetGeneration()
+}
@@ -0,0 +1,140 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionMaxConcurrentInstalls(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.MaxConcurrentInstalls = 1
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	for _, name := range []string{"first", "second", "third"} {
+		require.NoError(t, cl.Create(ctx, &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: prefix + name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+		}))
+	}
+
+	reconcileExt := func(name string) (ctrl.Result, *metav1.Condition) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		ext := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+		require.NotNil(t, cond)
+		return res, cond
+	}
+	bundleDeploymentExists := func(name string) bool {
+		err := cl.Get(ctx, types.NamespacedName{Name: prefix + name}, &rukpakv1alpha2.BundleDeployment{})
+		require.NoError(t, client.IgnoreNotFound(err))
+		return err == nil
+	}
+
+	t.Log("It starts the first install")
+	_, cond := reconcileExt("first")
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+	require.True(t, bundleDeploymentExists("first"))
+
+	t.Log("It holds back installs beyond the limit, in the order they were requested")
+	res, cond := reconcileExt("second")
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonInstallPending, cond.Reason)
+	require.Equal(t, "waiting for an install slot: 1 of 1 concurrent installs in progress, 0 ahead in the queue", cond.Message)
+	require.NotZero(t, res.RequeueAfter)
+	require.False(t, bundleDeploymentExists("second"))
+
+	_, cond = reconcileExt("third")
+	require.Equal(t, ocv1alpha1.ReasonInstallPending, cond.Reason)
+	require.Equal(t, "waiting for an install slot: 1 of 1 concurrent installs in progress, 1 ahead in the queue", cond.Message)
+
+	t.Log("It keeps reconciling an install that is already in progress")
+	_, cond = reconcileExt("first")
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+
+	t.Log("It gives a freed slot to the extension that has waited longest")
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + "first"}, bd))
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+		Message: "installed",
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+
+	_, cond = reconcileExt("third")
+	require.Equal(t, ocv1alpha1.ReasonInstallPending, cond.Reason)
+	require.Equal(t, "waiting for an install slot: 0 of 1 concurrent installs in progress, 1 ahead in the queue", cond.Message)
+
+	_, cond = reconcileExt("second")
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+	require.True(t, bundleDeploymentExists("second"))
+
+	_, cond = reconcileExt("third")
+	require.Equal(t, ocv1alpha1.ReasonInstallPending, cond.Reason)
+	require.Equal(t, "waiting for an install slot: 1 of 1 concurrent installs in progress, 0 ahead in the queue", cond.Message)
+}
+
+func TestClusterExtensionInstallQueueRelease(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.MaxConcurrentInstalls = 1
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	for _, name := range []string{"first", "second", "third", "fourth"} {
+		require.NoError(t, cl.Create(ctx, &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: prefix + name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+		}))
+	}
+	reconcileExt := func(name string) *metav1.Condition {
+		extKey := types.NamespacedName{Name: prefix + name}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		ext := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+	}
+
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, reconcileExt("first").Reason)
+	require.Equal(t, ocv1alpha1.ReasonInstallPending, reconcileExt("second").Reason)
+	cond := reconcileExt("third")
+	require.Equal(t, "waiting for an install slot: 1 of 1 concurrent installs in progress, 1 ahead in the queue", cond.Message)
+
+	t.Log("It removes an extension from the queue once it stops waiting for a slot")
+	ext := &ocv1alpha1.ClusterExtension{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + "second"}, ext))
+	ext.Spec.Version = "9.9.9"
+	require.NoError(t, cl.Update(ctx, ext))
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: prefix + "second"}})
+	require.Error(t, err)
+	cond = reconcileExt("third")
+	require.Equal(t, "waiting for an install slot: 1 of 1 concurrent installs in progress, 0 ahead in the queue", cond.Message)
+
+	t.Log("It stops counting a BundleDeployment without an Installed condition once it has stalled")
+	reconciler.StalledInstallTimeout = time.Nanosecond
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, reconcileExt("third").Reason)
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, reconcileExt("fourth").Reason)
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// installTimeout returns spec.install.timeout, or zero if it is not set.
+func installTimeout(ext *ocv1alpha1.ClusterExtension) time.Duration {
+	if ext.Spec.Install == nil || ext.Spec.Install.Timeout == nil {
+		return 0
+	}
+	return ext.Spec.Install.Timeout.Duration
+}
+
+// applyInstallTimeout holds back an Installed condition of True while rukpak reports
+// the installed objects as unhealthy, and sets it to False once they have not become
+// healthy within spec.install.timeout. The wait starts when the previous Installed
+// condition last changed, or now if it was True. It returns how long remains until
+// the timeout, or zero if the install is not waiting.
+func applyInstallTimeout(ext *ocv1alpha1.ClusterExtension, bd *rukpakv1alpha2.BundleDeployment, previous *metav1.Condition, now time.Time) time.Duration {
+	timeout := installTimeout(ext)
+	if timeout == 0 || !apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1alpha1.TypeInstalled) {
+		return 0
+	}
+	healthy := apimeta.FindStatusCondition(bd.Status.Conditions, rukpakv1alpha2.TypeHealthy)
+	if healthy == nil || healthy.Status == metav1.ConditionTrue {
+		return 0
+	}
+
+	start := now
+	if previous != nil && previous.Status != metav1.ConditionTrue && !previous.LastTransitionTime.IsZero() {
+		start = previous.LastTransitionTime.Time
+	}
+	ext.Status.InstalledBundle = nil
+	remaining := start.Add(timeout).Sub(now)
+	if remaining > 0 {
+		setInstalledStatusConditionUnknown(&ext.Status.Conditions,
+			fmt.Sprintf("waiting up to %s for the installed objects to become healthy: %s", timeout, healthy.Message), ext.GetGeneration())
+	} else {
+		remaining = 0
+		setInstalledStatusConditionFailed(&ext.Status.Conditions,
+			fmt.Sprintf("installed objects did not become healthy within %s: %s", timeout, healthy.Message), ext.GetGeneration())
+	}
+	// rukpak has already reported the install as succeeded, so the condition just
+	// transitioned from True. Keep the start of the wait as its transition time for
+	// the next reconcile to measure the timeout from.
+	apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled).LastTransitionTime = metav1.NewTime(start)
+	return remaining
+}
@@ -0,0 +1,89 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionInstallTimeout(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName: "prometheus",
+			Install:     &ocv1alpha1.InstallConfig{Timeout: &metav1.Duration{Duration: time.Hour}},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+
+	setHealthy := func(status metav1.ConditionStatus, message string) {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:   rukpakv1alpha2.TypeInstalled,
+			Status: metav1.ConditionTrue,
+			Reason: rukpakv1alpha2.ReasonInstallationSucceeded,
+		})
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeHealthy,
+			Status:  status,
+			Reason:  rukpakv1alpha2.ReasonUnhealthy,
+			Message: message,
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+	}
+	reconcile := func() (ctrl.Result, *metav1.Condition) {
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return res, apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+	}
+
+	t.Log("It waits for the installed objects to become healthy")
+	setHealthy(metav1.ConditionFalse, "deployment prometheus-operator is not available")
+	res, cond := reconcile()
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, "waiting up to 1h0m0s for the installed objects to become healthy: deployment prometheus-operator is not available", cond.Message)
+	require.Nil(t, ext.Status.InstalledBundle)
+	require.Greater(t, res.RequeueAfter, time.Duration(0))
+	require.LessOrEqual(t, res.RequeueAfter, time.Hour)
+
+	t.Log("It fails the install once the timeout has passed")
+	ext.Spec.Install.Timeout = &metav1.Duration{Duration: time.Millisecond}
+	require.NoError(t, cl.Update(ctx, ext))
+	time.Sleep(time.Millisecond)
+	res, cond = reconcile()
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, "installed objects did not become healthy within 1ms: deployment prometheus-operator is not available", cond.Message)
+	require.Zero(t, res.RequeueAfter)
+	_, cond = reconcile()
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+
+	t.Log("It reports the install once the installed objects are healthy")
+	setHealthy(metav1.ConditionTrue, "BundleDeployment is healthy")
+	_, cond = reconcile()
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.NotNil(t, ext.Status.InstalledBundle)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, ext.Status.InstalledBundle.BundleMetadata)
+}
@@ -0,0 +1,80 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionInstalledBundleDigest(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "2.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	reconcile := func() *ocv1alpha1.InstalledBundleMetadata {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext.Status.InstalledBundle
+	}
+	installFrom := func(resolvedRef string) {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		bd.Status.ObservedGeneration = bd.Generation
+		bd.Status.ResolvedSource = &rukpakv1alpha2.BundleSource{
+			Type:  rukpakv1alpha2.SourceTypeImage,
+			Image: &rukpakv1alpha2.ImageSource{Ref: resolvedRef},
+		}
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+	}
+
+	t.Log("It does not report an installed bundle before the BundleDeployment is installed")
+	require.Nil(t, reconcile())
+
+	t.Log("It reports the digest rukpak unpacked and when the bundle was installed")
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	installFrom("quay.io/operatorhubio/prometheus@" + digest)
+	installed := reconcile()
+	require.NotNil(t, installed)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, installed.BundleMetadata)
+	require.Equal(t, digest, installed.Digest)
+	require.NotNil(t, installed.LastInstalled)
+
+	t.Log("It keeps the install time while the same image remains installed")
+	lastInstalled := installed.LastInstalled
+	installed = reconcile()
+	require.NotNil(t, installed)
+	require.Equal(t, lastInstalled, installed.LastInstalled)
+
+	t.Log("It reports a new digest when rukpak unpacks a different image")
+	otherDigest := "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+	installFrom("quay.io/operatorhubio/prometheus@" + otherDigest)
+	installed = reconcile()
+	require.NotNil(t, installed)
+	require.Equal(t, otherDigest, installed.Digest)
+	require.NotNil(t, installed.LastInstalled)
+}
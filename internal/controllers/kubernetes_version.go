@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+	"k8s.io/client-go/discovery"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// KubernetesVersionProvider returns the Kubernetes version of the cluster, which
+// bundles declaring a minimum Kubernetes version must be compatible with.
+type KubernetesVersionProvider interface {
+	KubernetesVersion(ctx context.Context) (bsemver.Version, error)
+}
+
+// DiscoveryKubernetesVersion reads the Kubernetes version from the API server's
+// version endpoint.
+type DiscoveryKubernetesVersion struct {
+	Discovery discovery.ServerVersionInterface
+}
+
+func (d *DiscoveryKubernetesVersion) KubernetesVersion(_ context.Context) (bsemver.Version, error) {
+	info, err := d.Discovery.ServerVersion()
+	if err != nil {
+		return bsemver.Version{}, fmt.Errorf("error getting the Kubernetes version: %w", err)
+	}
+	version, err := bsemver.ParseTolerant(info.GitVersion)
+	if err != nil {
+		return bsemver.Version{}, fmt.Errorf("error parsing the Kubernetes version %q: %w", info.GitVersion, err)
+	}
+	return version, nil
+}
+
+// declaresMinKubeVersion reports whether any of the bundles declares a minimum
+// Kubernetes version, or declares one that cannot be read.
+func declaresMinKubeVersion(bundles []*catalogmetadata.Bundle) bool {
+	for _, b := range bundles {
+		if minKubeVersion, err := b.MinKubeVersion(); err != nil || minKubeVersion != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyKubernetesVersion returns the candidates, in their original order, whose
+// minimum Kubernetes version the cluster satisfies. It returns an error naming the
+// minimum the most preferred candidate requires if no candidate remains.
+func applyKubernetesVersion(ext *ocv1alpha1.ClusterExtension, candidates []*catalogmetadata.Bundle, version bsemver.Version) ([]*catalogmetadata.Bundle, error) {
+	compatible := catalogfilter.Filter(candidates, catalogfilter.CompatibleWithKubernetesVersion(version))
+	if len(compatible) > 0 || len(candidates) == 0 {
+		return compatible, nil
+	}
+	requirement := "a minimum Kubernetes version that could not be read"
+	if minKubeVersion, err := candidates[0].MinKubeVersion(); err == nil {
+		requirement = fmt.Sprintf("Kubernetes version %q or later", minKubeVersion)
+	}
+	return nil, &resolutionError{
+		reason: ocv1alpha1.ReasonKubernetesVersionIncompatible,
+		err:    fmt.Errorf("no bundle of %s is compatible with Kubernetes version %q: bundle %q requires %s", describePackage(ext), version.String(), candidates[0].Name, requirement),
+	}
+}
@@ -0,0 +1,95 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+type fakeKubernetesVersion struct {
+	version bsemver.Version
+}
+
+func (f *fakeKubernetesVersion) KubernetesVersion(context.Context) (bsemver.Version, error) {
+	return f.version, nil
+}
+
+func TestDiscoveryKubernetesVersion(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.29.3+k3s1"},
+	}
+	v, err := (&controllers.DiscoveryKubernetesVersion{Discovery: discovery}).KubernetesVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1.29.3+k3s1", v.String())
+
+	discovery.FakedServerVersion = &version.Info{GitVersion: "not a version"}
+	_, err = (&controllers.DiscoveryKubernetesVersion{Discovery: discovery}).KubernetesVersion(context.Background())
+	require.ErrorContains(t, err, `error parsing the Kubernetes version "not a version"`)
+}
+
+func TestClusterExtensionMinKubeVersion(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, version, minKubeVersion string) *catalogmetadata.Bundle {
+		return testutil.NewBundle(pkg, version,
+			property.Property{Type: property.TypeCSVMetadata, Value: json.RawMessage(`{"minKubeVersion":"` + minKubeVersion + `"}`)})
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "1.0.0", "1.27.0"),
+		bundle("widgets", "2.0.0", "1.30.0"),
+		bundle("gadgets", "1.0.0", "1.30.0"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:            cl,
+		BundleProvider:    &fakeCatalogClient,
+		KubernetesVersion: &fakeKubernetesVersion{version: bsemver.MustParse("1.29.2")},
+	}
+
+	reconcile := func(pkg string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It excludes bundles that require a newer Kubernetes version than the cluster's")
+	ext, err := reconcile("widgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It reports the required Kubernetes version when no bundle is compatible")
+	ext, err = reconcile("gadgets")
+	require.EqualError(t, err, `no bundle of package "gadgets" is compatible with Kubernetes version "1.29.2": bundle "gadgets.v1.0.0" requires Kubernetes version "1.30.0" or later`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonKubernetesVersionIncompatible, cond.Reason)
+}
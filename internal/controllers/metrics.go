@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// otherPackagesLabel is the package label value for packages that are not
+// in the reconciler's ResolutionMetricsPackages allowlist.
+const otherPackagesLabel = "other"
+
+var resolutionsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "operator_controller_resolutions_total",
+		Help: "Number of ClusterExtension resolutions, by package and outcome. " +
+			"The outcome is Success or the reason of the failed Resolved condition.",
+	},
+	[]string{"package", "outcome"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(resolutionsTotal)
+}
+
+// recordResolution counts the outcome of resolving the ClusterExtension. Packages are
+// only given their own label value when they are in ResolutionMetricsPackages, to keep
+// the metric's cardinality bounded.
+func (r *ClusterExtensionReconciler) recordResolution(ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle, err error) {
+	packageName := ext.Spec.PackageName
+	if bundle != nil {
+		packageName = bundle.Package
+	}
+	if !r.ResolutionMetricsPackages.Has(packageName) {
+		packageName = otherPackagesLabel
+	}
+
+	outcome := ocv1alpha1.ReasonSuccess
+	if err != nil {
+		outcome = resolutionFailureReason(err)
+	}
+	resolutionsTotal.WithLabelValues(packageName, outcome).Inc()
+}
@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionResolutionMetrics(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.ResolutionMetricsPackages = sets.New("prometheus")
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	reconcileSpec := func(spec ocv1alpha1.ClusterExtensionSpec) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		require.NoError(t, cl.Create(ctx, &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}))
+		_, _ = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	}
+
+	success := resolutionCount(t, "prometheus", ocv1alpha1.ReasonSuccess)
+	failed := resolutionCount(t, "prometheus", ocv1alpha1.ReasonResolutionFailed)
+	other := resolutionCount(t, "other", ocv1alpha1.ReasonResolutionFailed)
+
+	reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"})
+	reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "missing"})
+
+	require.Equal(t, success+1, resolutionCount(t, "prometheus", ocv1alpha1.ReasonSuccess))
+	require.Equal(t, failed+1, resolutionCount(t, "prometheus", ocv1alpha1.ReasonResolutionFailed))
+	require.Equal(t, other+1, resolutionCount(t, "other", ocv1alpha1.ReasonResolutionFailed))
+	require.Zero(t, resolutionCount(t, "missing", ocv1alpha1.ReasonResolutionFailed))
+}
+
+// resolutionCount returns the value of the resolution counter for the given labels.
+func resolutionCount(t *testing.T, packageName, outcome string) float64 {
+	families, err := metrics.Registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "operator_controller_resolutions_total" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["package"] == packageName && labels["outcome"] == outcome {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// NodePlatformProvider returns the platforms of the cluster's nodes, every one of
+// which bundles declaring their supported platforms must support.
+type NodePlatformProvider interface {
+	NodePlatforms(ctx context.Context) ([]catalogmetadata.Platform, error)
+}
+
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=list;watch
+
+// ClusterNodePlatforms reads the node platforms from the kubernetes.io/os and
+// kubernetes.io/arch labels of the cluster's Nodes. Nodes without both labels are
+// ignored. Only the Nodes' metadata is read, so a cached Reader only watches Node
+// metadata.
+type ClusterNodePlatforms struct {
+	Reader client.Reader
+}
+
+func (c *ClusterNodePlatforms) NodePlatforms(ctx context.Context) ([]catalogmetadata.Platform, error) {
+	nodes := &metav1.PartialObjectMetadataList{}
+	nodes.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := c.Reader.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("error listing nodes: %w", err)
+	}
+	seen := map[catalogmetadata.Platform]struct{}{}
+	var platforms []catalogmetadata.Platform
+	for _, node := range nodes.Items {
+		p := catalogmetadata.Platform{OS: node.Labels[corev1.LabelOSStable], Architecture: node.Labels[corev1.LabelArchStable]}
+		if p.OS == "" || p.Architecture == "" {
+			continue
+		}
+		if _, ok := seen[p]; !ok {
+			seen[p] = struct{}{}
+			platforms = append(platforms, p)
+		}
+	}
+	sort.Slice(platforms, func(i, j int) bool { return platforms[i].String() < platforms[j].String() })
+	return platforms, nil
+}
+
+// declaresPlatforms reports whether any of the bundles declares the platforms it
+// supports, or has CSV metadata that cannot be read.
+func declaresPlatforms(bundles []*catalogmetadata.Bundle) bool {
+	for _, b := range bundles {
+		archs, err := b.SupportedArchitectures()
+		if err != nil || len(archs) > 0 {
+			return true
+		}
+		if oses, _ := b.SupportedOperatingSystems(); len(oses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyNodePlatforms returns the candidates, in their original order, that support
+// every node platform. It returns an error naming a platform the most preferred
+// candidate does not support if no candidate remains.
+func applyNodePlatforms(ext *ocv1alpha1.ClusterExtension, candidates []*catalogmetadata.Bundle, platforms []catalogmetadata.Platform) ([]*catalogmetadata.Bundle, error) {
+	compatible := catalogfilter.Filter(candidates, catalogfilter.SupportingPlatforms(platforms...))
+	if len(compatible) > 0 || len(candidates) == 0 {
+		return compatible, nil
+	}
+	var unsupported catalogmetadata.Platform
+	for _, p := range platforms {
+		if !catalogfilter.SupportingPlatforms(p)(candidates[0]) {
+			unsupported = p
+			break
+		}
+	}
+	return nil, &resolutionError{
+		reason: ocv1alpha1.ReasonPlatformUnsupported,
+		err:    fmt.Errorf("no bundle of %s supports every node platform (%s): bundle %q does not support %s", describePackage(ext), describePlatforms(platforms), candidates[0].Name, unsupported),
+	}
+}
@@ -0,0 +1,106 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+type fakeNodePlatforms struct {
+	platforms []catalogmetadata.Platform
+}
+
+func (f *fakeNodePlatforms) NodePlatforms(context.Context) ([]catalogmetadata.Platform, error) {
+	return f.platforms, nil
+}
+
+func TestClusterNodePlatforms(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+
+	for name, labels := range map[string]map[string]string{
+		"amd64-a":   {corev1.LabelOSStable: "linux", corev1.LabelArchStable: "amd64"},
+		"amd64-b":   {corev1.LabelOSStable: "linux", corev1.LabelArchStable: "amd64"},
+		"arm64":     {corev1.LabelOSStable: "linux", corev1.LabelArchStable: "arm64"},
+		"unlabeled": {},
+	} {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-platforms-test-" + name, Labels: labels}}
+		require.NoError(t, cl.Create(ctx, node))
+		defer func() { require.NoError(t, cl.Delete(ctx, node)) }()
+	}
+
+	platforms, err := (&controllers.ClusterNodePlatforms{Reader: cl}).NodePlatforms(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []catalogmetadata.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}, platforms)
+}
+
+func TestClusterExtensionNodePlatforms(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, version string, archs ...string) *catalogmetadata.Bundle {
+		labels := map[string]string{catalogmetadata.LabelOperatingSystemPrefix + "linux": catalogmetadata.LabelValueSupported}
+		for _, arch := range archs {
+			labels[catalogmetadata.LabelArchitecturePrefix+arch] = catalogmetadata.LabelValueSupported
+		}
+		metadata, err := json.Marshal(property.CSVMetadata{Labels: labels})
+		require.NoError(t, err)
+		return testutil.NewBundle(pkg, version, property.Property{Type: property.TypeCSVMetadata, Value: metadata})
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "1.0.0", "amd64", "arm64"),
+		bundle("widgets", "2.0.0", "amd64"),
+		bundle("gadgets", "1.0.0", "amd64"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+		NodePlatforms: &fakeNodePlatforms{platforms: []catalogmetadata.Platform{
+			{OS: "linux", Architecture: "amd64"},
+			{OS: "linux", Architecture: "arm64"},
+		}},
+	}
+
+	reconcile := func(pkg string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It excludes bundles that do not support every node platform")
+	ext, err := reconcile("widgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It reports the unsupported platform when no bundle supports every node platform")
+	ext, err = reconcile("gadgets")
+	require.EqualError(t, err, `no bundle of package "gadgets" supports every node platform (linux/amd64, linux/arm64): bundle "gadgets.v1.0.0" does not support linux/arm64`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonPlatformUnsupported, cond.Reason)
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	bsemver "github.com/blang/semver/v4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// checkRequiredPackages returns an error describing the first olm.package.required
+// dependency of the bundle that no other ClusterExtension satisfies by having a
+// version of the required package within the required range installed. With the
+// Install dependency mode it first has a dependent ClusterExtension install the
+// required package. It records every dependency in status.dependencies, followed by
+// the dependencies of the ClusterExtensions satisfying them.
+func (r *ClusterExtensionReconciler) checkRequiredPackages(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) error {
+	ext.Status.Dependencies = nil
+	requiredPackages, err := bundle.RequiredPackages()
+	if err != nil {
+		return fmt.Errorf("bundle %q has an invalid %q property: %w", bundle.Name, property.TypePackageRequired, err)
+	}
+	if len(requiredPackages) == 0 {
+		return nil
+	}
+
+	clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, &clusterExtensions); err != nil {
+		return err
+	}
+	var (
+		dependencies []ocv1alpha1.ResolvedDependency
+		transitive   []ocv1alpha1.ResolvedDependency
+		firstErr     error
+	)
+	for _, required := range requiredPackages {
+		dependency := ocv1alpha1.ResolvedDependency{
+			Package:      required.PackageName,
+			VersionRange: required.VersionRange,
+			RequiredBy:   bundle.Package,
+		}
+		if other := clusterExtensionInstallingInRange(clusterExtensions.Items, ext.Name, required); other != nil {
+			dependency.ClusterExtension = other.Name
+			dependency.Version = other.Status.InstalledBundle.Version
+			dependencies = append(dependencies, dependency)
+			transitive = append(transitive, other.Status.Dependencies...)
+			continue
+		}
+		if firstErr == nil && dependencyMode(ext) == ocv1alpha1.DependencyModeInstall {
+			name, err := r.ensureDependentClusterExtension(ctx, ext, clusterExtensions.Items, required)
+			if err != nil {
+				return fmt.Errorf("error installing package %q required by bundle %q: %w", required.PackageName, bundle.Name, err)
+			}
+			dependency.ClusterExtension = name
+			firstErr = fmt.Errorf("bundle %q requires package %q in range %q, which ClusterExtension %q has not installed yet",
+				bundle.Name, required.PackageName, required.VersionRange, name)
+			if other := clusterExtensionNamed(clusterExtensions.Items, name); other != nil && other.Status.InstalledBundle != nil {
+				firstErr = fmt.Errorf("bundle %q requires package %q in range %q, but ClusterExtension %q has installed version %q",
+					bundle.Name, required.PackageName, required.VersionRange, name, other.Status.InstalledBundle.Version)
+			}
+		} else if firstErr == nil {
+			firstErr = fmt.Errorf("bundle %q requires package %q in range %q, which is not installed by any ClusterExtension",
+				bundle.Name, required.PackageName, required.VersionRange)
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	for _, dependency := range transitive {
+		if !slices.Contains(dependencies, dependency) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	ext.Status.Dependencies = dependencies
+	return firstErr
+}
+
+// clusterExtensionInstallingInRange returns a ClusterExtension other than the one
+// named exclude that has installed a version of the required package within its
+// range, or nil if there is none. ClusterExtensions that are being deleted are not
+// considered.
+func clusterExtensionInstallingInRange(clusterExtensions []ocv1alpha1.ClusterExtension, exclude string, required catalogmetadata.PackageRequired) *ocv1alpha1.ClusterExtension {
+	for i := range clusterExtensions {
+		other := &clusterExtensions[i]
+		if other.Name == exclude || other.Status.InstalledBundle == nil || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if clusterExtensionPackage(other) != required.PackageName {
+			continue
+		}
+		v, err := bsemver.Parse(other.Status.InstalledBundle.Version)
+		if err != nil {
+			continue
+		}
+		if required.SemverRange(v) {
+			return other
+		}
+	}
+	return nil
+}
+
+// clusterExtensionInstallingPackage returns a ClusterExtension other than the one
+// named exclude that installs the package or is installing it, whatever the version,
+// or nil if there is none. ClusterExtensions that are being deleted are not considered.
+func clusterExtensionInstallingPackage(clusterExtensions []ocv1alpha1.ClusterExtension, exclude, pkg string) *ocv1alpha1.ClusterExtension {
+	for i := range clusterExtensions {
+		other := &clusterExtensions[i]
+		if other.Name != exclude && other.DeletionTimestamp.IsZero() && clusterExtensionPackage(other) == pkg {
+			return other
+		}
+	}
+	return nil
+}
+
+// clusterExtensionPackage returns the package the ClusterExtension installs: the one
+// it selects by name, or else the one it last resolved to.
+func clusterExtensionPackage(ext *ocv1alpha1.ClusterExtension) string {
+	if ext.Spec.PackageName != "" {
+		return ext.Spec.PackageName
+	}
+	if ext.Status.Resolution != nil && ext.Status.Resolution.Selected != nil {
+		return ext.Status.Resolution.Selected.Package
+	}
+	return ""
+}
+
+// clusterExtensionNamed returns the ClusterExtension with the name, or nil if there is none.
+func clusterExtensionNamed(clusterExtensions []ocv1alpha1.ClusterExtension, name string) *ocv1alpha1.ClusterExtension {
+	for i := range clusterExtensions {
+		if clusterExtensions[i].Name == name {
+			return &clusterExtensions[i]
+		}
+	}
+	return nil
+}
+
+// dependencyMode returns the dependency mode of the ClusterExtension, which defaults
+// to Manual.
+func dependencyMode(ext *ocv1alpha1.ClusterExtension) ocv1alpha1.DependencyMode {
+	if ext.Spec.Dependencies == nil || ext.Spec.Dependencies.Mode == "" {
+		return ocv1alpha1.DependencyModeManual
+	}
+	return ext.Spec.Dependencies.Mode
+}
+
+// ensureDependentClusterExtension makes sure a ClusterExtension named after the
+// required package installs it, and returns its name. It creates one that selects
+// the required range from the same catalogs and installs its own dependencies the
+// same way, owned by ext so that it is deleted with the last ClusterExtension
+// depending on it. If the ClusterExtension exists and was created as a dependency,
+// ext is added to its owners; one created by a user is left as it is. No
+// ClusterExtension is created while another one of clusterExtensions installs the
+// package under a different name: its name is returned instead, as two
+// ClusterExtensions installing the same package would conflict over its CRDs.
+func (r *ClusterExtensionReconciler) ensureDependentClusterExtension(ctx context.Context, ext *ocv1alpha1.ClusterExtension, clusterExtensions []ocv1alpha1.ClusterExtension, required catalogmetadata.PackageRequired) (string, error) {
+	owner := metav1.OwnerReference{
+		APIVersion:         ocv1alpha1.GroupVersion.String(),
+		Kind:               "ClusterExtension",
+		Name:               ext.GetName(),
+		UID:                ext.GetUID(),
+		BlockOwnerDeletion: ptr.To(true),
+	}
+
+	dependent := &ocv1alpha1.ClusterExtension{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: required.PackageName}, dependent)
+	if apierrors.IsNotFound(err) {
+		if other := clusterExtensionInstallingPackage(clusterExtensions, ext.Name, required.PackageName); other != nil {
+			return other.Name, nil
+		}
+		dependent = &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            required.PackageName,
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Spec: ocv1alpha1.ClusterExtensionSpec{
+				PackageName:     required.PackageName,
+				Version:         required.VersionRange,
+				CatalogSelector: ext.Spec.CatalogSelector,
+				Dependencies:    ext.Spec.Dependencies,
+			},
+		}
+		return dependent.Name, r.Client.Create(ctx, dependent)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if dependent.Spec.PackageName != required.PackageName {
+		return "", fmt.Errorf("ClusterExtension %q exists and does not install package %q", dependent.Name, required.PackageName)
+	}
+	if !createdAsDependency(dependent) {
+		return dependent.Name, nil
+	}
+	for _, ref := range dependent.GetOwnerReferences() {
+		if ref.UID == ext.GetUID() {
+			return dependent.Name, nil
+		}
+	}
+	dependent.SetOwnerReferences(append(dependent.GetOwnerReferences(), owner))
+	return dependent.Name, r.Client.Update(ctx, dependent)
+}
+
+// createdAsDependency reports whether the ClusterExtension is owned by another
+// ClusterExtension that it installs a dependency for.
+func createdAsDependency(ext *ocv1alpha1.ClusterExtension) bool {
+	for _, ref := range ext.GetOwnerReferences() {
+		if ref.APIVersion == ocv1alpha1.GroupVersion.String() && ref.Kind == "ClusterExtension" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionRequiredPackages(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle("widgets", "1.0.0"),
+		testutil.NewBundle("widgets", "2.0.0"),
+		testutil.NewBundle("gadgets", "1.0.0", property.MustBuildPackageRequired("widgets", ">=2.0.0")),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name string, spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err == nil {
+			ext.Spec = spec
+			require.NoError(t, cl.Update(ctx, ext))
+		} else {
+			ext = &ocv1alpha1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name}, Spec: spec}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	// installWidgets reconciles the widgets ClusterExtension at the given version and
+	// marks its BundleDeployment as installed, so that it reports an installed bundle.
+	installWidgets := func(version string) {
+		spec := ocv1alpha1.ClusterExtensionSpec{
+			PackageName:             "widgets",
+			Version:                 version,
+			UpgradeConstraintPolicy: ocv1alpha1.UpgradeConstraintPolicyIgnore,
+		}
+		_, err := reconcile("widgets", spec)
+		require.NoError(t, err)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + "widgets"}, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+		ext, err := reconcile("widgets", spec)
+		require.NoError(t, err)
+		require.NotNil(t, ext.Status.InstalledBundle)
+		require.Equal(t, version, ext.Status.InstalledBundle.Version)
+	}
+
+	t.Log("It fails the preflight check when the required package is not installed")
+	_, err := reconcile("gadgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "gadgets"})
+	require.EqualError(t, err, `bundle "gadgets.v1.0.0" requires package "widgets" in range ">=2.0.0", which is not installed by any ClusterExtension`)
+
+	t.Log("It fails the preflight check when the installed version is outside the required range")
+	installWidgets("1.0.0")
+	_, err = reconcile("gadgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "gadgets"})
+	require.EqualError(t, err, `bundle "gadgets.v1.0.0" requires package "widgets" in range ">=2.0.0", which is not installed by any ClusterExtension`)
+
+	t.Log("It installs the bundle once the required package is installed within the range")
+	installWidgets("2.0.0")
+	ext, err := reconcile("gadgets", ocv1alpha1.ClusterExtensionSpec{PackageName: "gadgets"})
+	require.NoError(t, err)
+	require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "RequiredDependencies", Passed: true})
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: ext.Name}, bd))
+	require.Equal(t, "quay.io/example/gadgets@fake1.0.0", bd.Spec.Source.Image.Ref)
+}
+
+func TestClusterExtensionInstallsRequiredPackages(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	lib := fmt.Sprintf("libs-%s", rand.String(8))
+	base := fmt.Sprintf("base-%s", rand.String(8))
+	requiresLib := property.MustBuildPackageRequired(lib, ">=1.0.0")
+	requiresBase := property.MustBuildPackageRequired(base, ">=1.0.0")
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle(base, "1.0.0"),
+		testutil.NewBundle(lib, "1.0.0", requiresBase),
+		testutil.NewBundle("apps", "1.0.0", requiresLib),
+		testutil.NewBundle("tools", "1.0.0", requiresLib),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(name string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: name}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		ext := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+	create := func(pkg string) *ocv1alpha1.ClusterExtension {
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))},
+			Spec: ocv1alpha1.ClusterExtensionSpec{
+				PackageName:  pkg,
+				Dependencies: &ocv1alpha1.DependenciesConfig{Mode: ocv1alpha1.DependencyModeInstall},
+			},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		return ext
+	}
+
+	install := func(name string) {
+		_, err := reconcile(name)
+		require.NoError(t, err)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name}, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+		ext, err := reconcile(name)
+		require.NoError(t, err)
+		require.NotNil(t, ext.Status.InstalledBundle)
+	}
+
+	t.Log("It creates a dependent ClusterExtension for the required package")
+	apps := create("apps")
+	ext, err := reconcile(apps.Name)
+	require.EqualError(t, err, fmt.Sprintf(`bundle "apps.v1.0.0" requires package %q in range ">=1.0.0", which ClusterExtension %q has not installed yet`, lib, lib))
+	require.Equal(t, []ocv1alpha1.ResolvedDependency{
+		{Package: lib, VersionRange: ">=1.0.0", RequiredBy: "apps", ClusterExtension: lib},
+	}, ext.Status.Dependencies)
+	dependent := &ocv1alpha1.ClusterExtension{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: lib}, dependent))
+	require.Equal(t, lib, dependent.Spec.PackageName)
+	require.Equal(t, ">=1.0.0", dependent.Spec.Version)
+	require.Equal(t, apps.Spec.Dependencies, dependent.Spec.Dependencies)
+	require.Len(t, dependent.OwnerReferences, 1)
+	require.Equal(t, apps.UID, dependent.OwnerReferences[0].UID)
+
+	t.Log("It shares the dependent ClusterExtension between the ClusterExtensions requiring the package")
+	tools := create("tools")
+	_, err = reconcile(tools.Name)
+	require.Error(t, err)
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: lib}, dependent))
+	require.Len(t, dependent.OwnerReferences, 2)
+	require.Equal(t, tools.UID, dependent.OwnerReferences[1].UID)
+
+	t.Log("It installs the bundle once the dependent ClusterExtensions have installed the required packages")
+	_, err = reconcile(lib)
+	require.Error(t, err)
+	install(base)
+	install(lib)
+
+	ext, err = reconcile(apps.Name)
+	require.NoError(t, err)
+	require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "RequiredDependencies", Passed: true})
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: apps.Name}, bd))
+	require.Equal(t, "quay.io/example/apps@fake1.0.0", bd.Spec.Source.Image.Ref)
+
+	t.Log("It reports the dependency closure with the ClusterExtension satisfying each dependency")
+	require.Equal(t, []ocv1alpha1.ResolvedDependency{
+		{Package: lib, VersionRange: ">=1.0.0", RequiredBy: "apps", ClusterExtension: lib, Version: "1.0.0"},
+		{Package: base, VersionRange: ">=1.0.0", RequiredBy: lib, ClusterExtension: base, Version: "1.0.0"},
+	}, ext.Status.Dependencies)
+}
+
+func TestClusterExtensionInstallsRequiredPackagesWaitsForExistingInstall(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	lib := fmt.Sprintf("libs-%s", rand.String(8))
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle(lib, "1.0.0"),
+		testutil.NewBundle(lib, "2.0.0"),
+		testutil.NewBundle("apps", "1.0.0", property.MustBuildPackageRequired(lib, ">=2.0.0")),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+	reconcile := func(name string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: name}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		ext := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	userLib := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("my-libs-%s", rand.String(8))},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: lib, Version: "1.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, userLib))
+	apps := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:  "apps",
+			Dependencies: &ocv1alpha1.DependenciesConfig{Mode: ocv1alpha1.DependencyModeInstall},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, apps))
+
+	t.Log("It waits for a ClusterExtension of another name that is installing the required package")
+	ext, err := reconcile(apps.Name)
+	require.EqualError(t, err, fmt.Sprintf(`bundle "apps.v1.0.0" requires package %q in range ">=2.0.0", which ClusterExtension %q has not installed yet`, lib, userLib.Name))
+	require.Equal(t, []ocv1alpha1.ResolvedDependency{
+		{Package: lib, VersionRange: ">=2.0.0", RequiredBy: "apps", ClusterExtension: userLib.Name},
+	}, ext.Status.Dependencies)
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: lib}, &ocv1alpha1.ClusterExtension{})))
+
+	t.Log("It reports the version the ClusterExtension installed outside the required range")
+	_, err = reconcile(userLib.Name)
+	require.NoError(t, err)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: userLib.Name}, bd))
+	bd.Status.ObservedGeneration = bd.GetGeneration()
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:    rukpakv1alpha2.TypeInstalled,
+		Status:  metav1.ConditionTrue,
+		Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+		Message: "installed",
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+	_, err = reconcile(userLib.Name)
+	require.NoError(t, err)
+
+	_, err = reconcile(apps.Name)
+	require.EqualError(t, err, fmt.Sprintf(`bundle "apps.v1.0.0" requires package %q in range ">=2.0.0", but ClusterExtension %q has installed version "1.0.0"`, lib, userLib.Name))
+	require.True(t, apierrors.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: lib}, &ocv1alpha1.ClusterExtension{})))
+}
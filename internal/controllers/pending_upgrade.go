@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogsort "github.com/operator-framework/operator-controller/internal/catalogmetadata/sort"
+)
+
+// pendingUpgradeStatus describes the bundle as a pending upgrade that has not been
+// installed for the given reason.
+func pendingUpgradeStatus(bundle *catalogmetadata.Bundle, reason ocv1alpha1.PendingUpgradeReason, message string) *ocv1alpha1.PendingUpgrade {
+	return &ocv1alpha1.PendingUpgrade{
+		Bundle:   *bundleMetadataFor(bundle),
+		Image:    bundle.Image,
+		Channels: bundleChannelNames(bundle),
+		Reason:   reason,
+		Message:  message,
+	}
+}
+
+// pendingUpgradeWithoutEdge returns the most preferred bundle satisfying the spec
+// when the upgrade graph is ignored as a pending upgrade, if it is newer than the
+// selected bundle and so can only be reached without an upgrade edge.
+func pendingUpgradeWithoutEdge(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle, selected *catalogmetadata.Bundle) *ocv1alpha1.PendingUpgrade {
+	candidates, err := Resolve(ext, allBundles, nil)
+	if err != nil || candidates[0].Name == installedBundle.Name || !catalogsort.ByVersion(candidates[0], selected) {
+		return nil
+	}
+	return pendingUpgradeStatus(candidates[0], ocv1alpha1.PendingUpgradeReasonNoUpgradeEdge,
+		fmt.Sprintf("no upgrade edge leads from the installed bundle %q to the bundle", installedBundle.Name))
+}
+
+// pendingUpgradeHeldBackByDependents returns the bundle excluded by the violated
+// constraints of dependent ClusterExtensions as a pending upgrade.
+func pendingUpgradeHeldBackByDependents(preferred, installedBundle *catalogmetadata.Bundle, violated []dependentConstraint) *ocv1alpha1.PendingUpgrade {
+	if len(violated) == 0 || installedBundle == nil || preferred.Name == installedBundle.Name {
+		return nil
+	}
+	descriptions := make([]string, 0, len(violated))
+	for _, c := range violated {
+		descriptions = append(descriptions, c.String())
+	}
+	return pendingUpgradeStatus(preferred, ocv1alpha1.PendingUpgradeReasonDependentConstraint,
+		"the bundle does not satisfy "+strings.Join(descriptions, "; "))
+}
+
+// pendingUpgradeHeldBackByClusterVersion returns the bundle excluded because it is not
+// compatible with the cluster version as a pending upgrade.
+func pendingUpgradeHeldBackByClusterVersion(heldBack, installedBundle *catalogmetadata.Bundle, hold *ocv1alpha1.ClusterVersionHold) *ocv1alpha1.PendingUpgrade {
+	if hold == nil || installedBundle == nil || heldBack.Name == installedBundle.Name {
+		return nil
+	}
+	message := fmt.Sprintf("the bundle is compatible with cluster versions %q, which must include cluster version %s", hold.CompatibleClusterVersions, hold.ClusterVersion)
+	if hold.NextClusterVersion != "" {
+		message += " and next cluster version " + hold.NextClusterVersion
+	}
+	return pendingUpgradeStatus(heldBack, ocv1alpha1.PendingUpgradeReasonClusterVersionIncompatible, message)
+}
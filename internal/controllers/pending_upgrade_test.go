@@ -0,0 +1,85 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionPendingUpgradeWithoutEdge(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.0.0"},
+			{Name: "widgets.v1.1.0", Replaces: "widgets.v1.0.0"},
+			{Name: "widgets.v2.0.0"},
+		},
+	}}
+	bundle := func(version string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.InChannels = []*catalogmetadata.Channel{&channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{bundle("1.0.0"), bundle("1.1.0"), bundle("2.0.0")})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.1.0"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		mutate(&clusterExtension.Spec)
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	}
+
+	t.Log("It reports no pending upgrade when the newest bundle is installed")
+	reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, clusterExtension.Status.ResolvedBundle)
+	require.Nil(t, clusterExtension.Status.PendingUpgrade)
+
+	t.Log("It reports a newer bundle that no upgrade edge leads to")
+	reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "" })
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, clusterExtension.Status.ResolvedBundle)
+	require.Equal(t, &ocv1alpha1.PendingUpgrade{
+		Bundle:   ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"},
+		Image:    "quay.io/example/widgets@fake2.0.0",
+		Channels: []string{"stable"},
+		Reason:   ocv1alpha1.PendingUpgradeReasonNoUpgradeEdge,
+		Message:  `no upgrade edge leads from the installed bundle "widgets.v1.1.0" to the bundle`,
+	}, clusterExtension.Status.PendingUpgrade)
+
+	t.Log("It installs the bundle once the upgrade graph is ignored")
+	reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.UpgradeConstraintPolicy = ocv1alpha1.UpgradeConstraintPolicyIgnore
+	})
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"}, clusterExtension.Status.ResolvedBundle)
+	require.Nil(t, clusterExtension.Status.PendingUpgrade)
+}
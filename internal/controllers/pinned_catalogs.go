@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// unpinnedCatalogError returns an error with reason CatalogReferenceNotPinned if
+// the ClusterExtension would have resolved from the unpinned catalogs excluded by
+// RequirePinnedCatalogs. Otherwise it returns resolveErr, the error resolving
+// from the pinned catalogs alone.
+func unpinnedCatalogError(ext *ocv1alpha1.ClusterExtension, unpinnedBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle, resolveErr error) error {
+	unpinnedBundles, _ = applyCatalogOverlays(unpinnedBundles)
+	candidates, err := Resolve(ext, unpinnedBundles, installedBundle)
+	if err != nil {
+		return resolveErr
+	}
+	seen := map[string]struct{}{}
+	var catalogs []string
+	for _, b := range candidates {
+		if _, ok := seen[b.CatalogName]; ok {
+			continue
+		}
+		seen[b.CatalogName] = struct{}{}
+		catalogs = append(catalogs, fmt.Sprintf("%q", b.CatalogName))
+	}
+	sort.Strings(catalogs)
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonCatalogReferenceNotPinned,
+		err: fmt.Errorf("%s is only available from catalogs whose image is not referenced by digest: %s",
+			describePackage(ext), strings.Join(catalogs, ", ")),
+	}
+}
@@ -0,0 +1,83 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionRequirePinnedCatalogs(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(pkg, version, catalog, catalogRef string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, version)
+		b.CatalogName = catalog
+		b.CatalogRef = catalogRef
+		return b
+	}
+	pinnedRef := "quay.io/example/pinned@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	taggedRef := "quay.io/example/tagged:latest"
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "1.0.0", "pinned", pinnedRef),
+		bundle("widgets", "2.0.0", "tagged", taggedRef),
+		bundle("gadgets", "1.0.0", "tagged", taggedRef),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(pkg string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It resolves from every catalog by default")
+	ext, err := reconcile("widgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"}, ext.Status.ResolvedBundle)
+
+	reconciler.RequirePinnedCatalogs = true
+
+	t.Log("It only resolves from catalogs referenced by digest when they are required")
+	ext, err = reconcile("widgets")
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It reports packages that are only available from catalogs referenced by a tag")
+	ext, err = reconcile("gadgets")
+	require.EqualError(t, err, `package "gadgets" is only available from catalogs whose image is not referenced by digest: "tagged"`)
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonCatalogReferenceNotPinned, cond.Reason)
+
+	t.Log("It reports the usual resolution failure for packages that no catalog provides")
+	ext, err = reconcile("sprockets")
+	require.EqualError(t, err, `no package "sprockets" found`)
+	cond = apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, ocv1alpha1.ReasonResolutionFailed, cond.Reason)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+	catalogsort "github.com/operator-framework/operator-controller/internal/catalogmetadata/sort"
+)
+
+// pinnedImageBundle returns the bundle for spec.pinnedImage: the catalog bundle of the
+// package with that image if a catalog still provides it, and otherwise the installed
+// bundle if it was installed from that image.
+func (r *ClusterExtensionReconciler) pinnedImageBundle(ctx context.Context, ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle) (*catalogmetadata.Bundle, error) {
+	image := ext.Spec.PinnedImage
+	resultSet := catalogfilter.Filter(allBundles, catalogfilter.And(
+		catalogfilter.WithPackageName(ext.Spec.PackageName),
+		catalogfilter.WithBundleImage(image),
+	))
+	if len(resultSet) > 0 {
+		sort.SliceStable(resultSet, func(i, j int) bool {
+			return catalogsort.ByVersion(resultSet[i], resultSet[j])
+		})
+		return resultSet[0], nil
+	}
+
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: ext.GetName()}, bd); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	installed := ext.Status.InstalledBundle
+	if installed == nil || bd.Spec.Source.Image == nil || bd.Spec.Source.Image.Ref != image {
+		return nil, fmt.Errorf("pinned image %q of package %q is not available from any catalog and is not installed", image, ext.Spec.PackageName)
+	}
+
+	// The catalogs have moved on from the installed bundle, so describe it from the
+	// metadata recorded when it was installed.
+	return recordedBundle(ext.Spec.PackageName, installed.BundleMetadata, image)
+}
+
+// recordedBundle describes a bundle that no catalog provides anymore from the
+// metadata and image recorded when it was installed.
+func recordedBundle(packageName string, metadata ocv1alpha1.BundleMetadata, image string) (*catalogmetadata.Bundle, error) {
+	pkgProperty, err := json.Marshal(property.Package{PackageName: packageName, Version: metadata.Version})
+	if err != nil {
+		return nil, err
+	}
+	return &catalogmetadata.Bundle{
+		Bundle: declcfg.Bundle{
+			Name:    metadata.Name,
+			Package: packageName,
+			Image:   image,
+			Properties: []property.Property{
+				{Type: property.TypePackage, Value: pkgProperty},
+			},
+		},
+	}, nil
+}
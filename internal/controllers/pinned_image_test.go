@@ -0,0 +1,89 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionPinnedImage(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	image := func(version string) string {
+		return "quay.io/example/widgets@sha256:" + strings.Repeat(version[:1], 64)
+	}
+	bundle := func(version string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.Image = image(version)
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{bundle("1.0.0"), bundle("2.0.0")})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", PinnedImage: image("1.0.0")},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	reconcile := func() error {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return err
+	}
+
+	t.Log("It installs the pinned image instead of the newest bundle")
+	require.NoError(t, reconcile())
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, extKey, bd))
+	require.Equal(t, image("1.0.0"), bd.Spec.Source.Image.Ref)
+
+	bd.Status.ObservedGeneration = bd.GetGeneration()
+	apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+		Type:   rukpakv1alpha2.TypeInstalled,
+		Status: metav1.ConditionTrue,
+		Reason: rukpakv1alpha2.ReasonInstallationSucceeded,
+	})
+	require.NoError(t, cl.Status().Update(ctx, bd))
+	require.NoError(t, reconcile())
+	require.NotNil(t, ext.Status.InstalledBundle)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.InstalledBundle.BundleMetadata)
+
+	t.Log("It keeps the pinned image installed once the catalog has moved on")
+	fakeCatalogClient = testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{bundle("2.0.0")})
+	require.NoError(t, reconcile())
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+	require.NoError(t, cl.Get(ctx, extKey, bd))
+	require.Equal(t, image("1.0.0"), bd.Spec.Source.Image.Ref)
+
+	t.Log("It fails when the pinned image is neither in a catalog nor installed")
+	ext.Spec.PinnedImage = image("3.0.0")
+	require.NoError(t, cl.Update(ctx, ext))
+	err := reconcile()
+	require.EqualError(t, err, fmt.Sprintf(`pinned image %q of package "widgets" is not available from any catalog and is not installed`, image("3.0.0")))
+	cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/conditionsets"
+)
+
+// PreflightCheck is a named check run against the resolved bundle before it is
+// installed. Its result is recorded in status.preflightChecks and, like every
+// preflight check, a failure blocks the install unless spec.preflight.mode is Warn.
+type PreflightCheck struct {
+	// Name identifies the check in status.preflightChecks.
+	Name string
+	// ConditionType, if set, is the type of a condition the check contributes to the
+	// ClusterExtension's status, e.g. "SecurityPolicyCheck". The condition is True
+	// when the bundle passed the check, False when it failed and Unknown when the
+	// check has not been run against the resolved bundle. The condition is removed
+	// once the check is no longer registered.
+	ConditionType string
+	// Run returns an error describing why the bundle fails the check.
+	Run func(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bundle *catalogmetadata.Bundle) error
+}
+
+// ValidatePreflightChecks returns an error if the checks have missing or duplicate
+// names, or contribute condition types that are invalid, duplicated or built in.
+func ValidatePreflightChecks(checks []PreflightCheck) error {
+	names := sets.New[string]("RequiredDependencies", "CRDOwnership")
+	conditionTypes := sets.New[string](conditionsets.ConditionTypes...)
+	for _, check := range checks {
+		if check.Name == "" || check.Run == nil {
+			return fmt.Errorf("invalid preflight check %q: a name and a run function are required", check.Name)
+		}
+		if names.Has(check.Name) {
+			return fmt.Errorf("invalid preflight check %q: the name is already registered", check.Name)
+		}
+		names.Insert(check.Name)
+		if check.ConditionType == "" {
+			continue
+		}
+		if errs := validation.IsQualifiedName(check.ConditionType); len(errs) > 0 {
+			return fmt.Errorf("invalid preflight check %q: invalid condition type %q: %s", check.Name, check.ConditionType, strings.Join(errs, "; "))
+		}
+		if conditionTypes.Has(check.ConditionType) {
+			return fmt.Errorf("invalid preflight check %q: condition type %q is already in use", check.Name, check.ConditionType)
+		}
+		conditionTypes.Insert(check.ConditionType)
+	}
+	return nil
+}
+
+// setPreflightCheckConditions sets the conditions contributed by the registered
+// preflight checks from the results in status.preflightChecks, and removes every
+// condition that is neither built in nor contributed by a registered check.
+func (r *ClusterExtensionReconciler) setPreflightCheckConditions(ext *ocv1alpha1.ClusterExtension) {
+	known := sets.New[string](conditionsets.ConditionTypes...)
+	for _, check := range r.PreflightChecks {
+		if check.ConditionType == "" {
+			continue
+		}
+		known.Insert(check.ConditionType)
+		cond := metav1.Condition{
+			Type:               check.ConditionType,
+			Status:             metav1.ConditionUnknown,
+			Reason:             ocv1alpha1.ReasonPreflightCheckNotRun,
+			Message:            "the check has not been run against the resolved bundle",
+			ObservedGeneration: ext.GetGeneration(),
+		}
+		for _, result := range ext.Status.PreflightChecks {
+			if result.Name != check.Name {
+				continue
+			}
+			if result.Passed {
+				cond.Status, cond.Reason, cond.Message = metav1.ConditionTrue, ocv1alpha1.ReasonPreflightCheckPassed, "the resolved bundle passed the check"
+			} else {
+				cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, ocv1alpha1.ReasonPreflightCheckFailed, result.Message
+			}
+		}
+		apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+	}
+
+	for _, cond := range append([]metav1.Condition(nil), ext.Status.Conditions...) {
+		if !known.Has(cond.Type) {
+			apimeta.RemoveStatusCondition(&ext.Status.Conditions, cond.Type)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+func TestValidatePreflightChecks(t *testing.T) {
+	run := func(context.Context, *ocv1alpha1.ClusterExtension, *catalogmetadata.Bundle) error { return nil }
+	for _, tt := range []struct {
+		name    string
+		checks  []controllers.PreflightCheck
+		wantErr string
+	}{
+		{
+			name: "valid checks",
+			checks: []controllers.PreflightCheck{
+				{Name: "SecurityPolicy", ConditionType: "SecurityPolicyCheck", Run: run},
+				{Name: "Quota", Run: run},
+			},
+		},
+		{
+			name:    "missing run function",
+			checks:  []controllers.PreflightCheck{{Name: "SecurityPolicy"}},
+			wantErr: `invalid preflight check "SecurityPolicy": a name and a run function are required`,
+		},
+		{
+			name:    "built-in name",
+			checks:  []controllers.PreflightCheck{{Name: "RequiredDependencies", Run: run}},
+			wantErr: `invalid preflight check "RequiredDependencies": the name is already registered`,
+		},
+		{
+			name:    "built-in condition type",
+			checks:  []controllers.PreflightCheck{{Name: "SecurityPolicy", ConditionType: ocv1alpha1.TypeInstalled, Run: run}},
+			wantErr: `invalid preflight check "SecurityPolicy": condition type "Installed" is already in use`,
+		},
+		{
+			name: "duplicate condition type",
+			checks: []controllers.PreflightCheck{
+				{Name: "SecurityPolicy", ConditionType: "SecurityPolicyCheck", Run: run},
+				{Name: "Quota", ConditionType: "SecurityPolicyCheck", Run: run},
+			},
+			wantErr: `invalid preflight check "Quota": condition type "SecurityPolicyCheck" is already in use`,
+		},
+		{
+			name:    "invalid condition type",
+			checks:  []controllers.PreflightCheck{{Name: "SecurityPolicy", ConditionType: "security policy", Run: run}},
+			wantErr: `invalid preflight check "SecurityPolicy": invalid condition type "security policy": name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := controllers.ValidatePreflightChecks(tt.checks)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClusterExtensionPreflightCheckConditions(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	var policyErr error
+	reconciler.PreflightChecks = []controllers.PreflightCheck{{
+		Name:          "SecurityPolicy",
+		ConditionType: "SecurityPolicyCheck",
+		Run: func(context.Context, *ocv1alpha1.ClusterExtension, *catalogmetadata.Bundle) error {
+			return policyErr
+		},
+	}}
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	reconcileSpec := func(spec ocv1alpha1.ClusterExtensionSpec) (*metav1.Condition, error) {
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		ext.Spec = spec
+		require.NoError(t, cl.Update(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		return apimeta.FindStatusCondition(ext.Status.Conditions, "SecurityPolicyCheck"), err
+	}
+
+	t.Log("It reports the check's condition as Unknown when resolution fails")
+	cond, err := reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"})
+	require.Error(t, err)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonPreflightCheckNotRun, cond.Reason)
+	require.Equal(t, ext.GetGeneration(), cond.ObservedGeneration)
+
+	t.Log("It reports the check's condition as True when the bundle passes")
+	cond, err = reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	require.NoError(t, err)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonPreflightCheckPassed, cond.Reason)
+	require.Contains(t, ext.Status.PreflightChecks, ocv1alpha1.PreflightCheckStatus{Name: "SecurityPolicy", ConditionType: "SecurityPolicyCheck", Passed: true})
+
+	t.Log("It reports the check's condition as False and blocks the install when the bundle fails")
+	policyErr = errors.New("the bundle requests privileged containers")
+	cond, err = reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	require.EqualError(t, err, "the bundle requests privileged containers")
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonPreflightCheckFailed, cond.Reason)
+	require.Equal(t, "the bundle requests privileged containers", cond.Message)
+	installed := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeInstalled)
+	require.NotNil(t, installed)
+	require.Equal(t, metav1.ConditionFalse, installed.Status)
+
+	t.Log("It removes the check's condition once the check is no longer registered")
+	reconciler.PreflightChecks = nil
+	cond, err = reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	require.NoError(t, err)
+	require.Nil(t, cond)
+	verifyInvariants(ctx, t, reconciler.Client, ext)
+}
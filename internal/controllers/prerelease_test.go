@@ -0,0 +1,65 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionAllowPrerelease(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle("widgets", "1.0.0"),
+		testutil.NewBundle("widgets", "1.1.0-rc.1"),
+		testutil.NewBundle("gadgets", "1.0.0-rc.1"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It skips pre-release versions by default")
+	ext, err := reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It skips pre-release versions even when the version range names one")
+	_, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.1.0-rc.1"})
+	require.EqualError(t, err, `no package "widgets" found that is not a pre-release, set allowPrerelease to resolve pre-release versions`)
+
+	t.Log("It resolves pre-release versions when allowPrerelease is set")
+	ext, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", AllowPrerelease: true})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0-rc.1", Version: "1.1.0-rc.1"}, ext.Status.ResolvedBundle)
+
+	t.Log("It fails resolution when a package only has pre-release versions")
+	_, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "gadgets"})
+	require.EqualError(t, err, `no package "gadgets" found that is not a pre-release, set allowPrerelease to resolve pre-release versions`)
+}
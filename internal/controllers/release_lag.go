@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"sort"
+
+	bsemver "github.com/blang/semver/v4"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// lagReleases returns the number of releases behind the channel head the
+// ClusterExtension targets.
+func lagReleases(ext *ocv1alpha1.ClusterExtension) int {
+	if ext.Spec.Upgrade == nil || ext.Spec.Upgrade.LagReleases < 0 {
+		return 0
+	}
+	return int(ext.Spec.Upgrade.LagReleases)
+}
+
+// channelReleases returns the distinct versions of the ClusterExtension's package
+// in its channels, or in all of its bundles if no channel is set, newest first.
+func channelReleases(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle) ([]bsemver.Version, error) {
+	predicates := []catalogfilter.Predicate[catalogmetadata.Bundle]{packagePredicate(ext)}
+	if channels := specChannels(ext); len(channels) > 0 {
+		predicates = append(predicates, channelPredicate(channels))
+	}
+
+	var releases []bsemver.Version
+	seen := map[string]struct{}{}
+	for _, b := range catalogfilter.Filter(allBundles, catalogfilter.And(predicates...)) {
+		version, err := b.Version()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[version.String()]; ok {
+			continue
+		}
+		seen[version.String()] = struct{}{}
+		releases = append(releases, *version)
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].GT(releases[j])
+	})
+	return releases, nil
+}
+
+// laggedReleaseTarget returns the release lagReleases behind the head of releases,
+// or the oldest release if there are not that many.
+func laggedReleaseTarget(releases []bsemver.Version, lag int) bsemver.Version {
+	if lag >= len(releases) {
+		return releases[len(releases)-1]
+	}
+	return releases[lag]
+}
+
+// releaseLagStatus describes how far the selected bundle is behind the head of
+// the ClusterExtension's channel.
+func releaseLagStatus(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, selected *catalogmetadata.Bundle) (*ocv1alpha1.ReleaseLagStatus, error) {
+	releases, err := channelReleases(ext, allBundles)
+	if err != nil || len(releases) == 0 {
+		return nil, err
+	}
+	selectedVersion, err := selected.Version()
+	if err != nil {
+		return nil, err
+	}
+	var behind int32
+	for _, release := range releases {
+		if release.GT(*selectedVersion) {
+			behind++
+		}
+	}
+	target := laggedReleaseTarget(releases, lagReleases(ext))
+	return &ocv1alpha1.ReleaseLagStatus{
+		HeadVersion:        releases[0].String(),
+		TargetVersion:      target.String(),
+		ReleasesBehindHead: behind,
+	}, nil
+}
@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// CatalogRevisionProvider returns a revision identifying the contents of the catalogs
+// that bundles are resolved from. The revision must change whenever the bundles the
+// BundleProvider returns may change. ok is false if there is no such revision.
+type CatalogRevisionProvider interface {
+	CatalogRevision(ctx context.Context) (revision string, ok bool, err error)
+}
+
+// ClusterCatalogRevision derives the revision from the name, labels, image and
+// resolved image digest of each unpacked Catalog. There is no revision while an
+// unpacked Catalog has no resolved digest.
+type ClusterCatalogRevision struct {
+	Reader client.Reader
+}
+
+func (c *ClusterCatalogRevision) CatalogRevision(ctx context.Context) (string, bool, error) {
+	catalogs := catalogd.CatalogList{}
+	if err := c.Reader.List(ctx, &catalogs); err != nil {
+		return "", false, err
+	}
+	type catalogRevision struct {
+		Name        string            `json:"name"`
+		Labels      map[string]string `json:"labels,omitempty"`
+		Ref         string            `json:"ref,omitempty"`
+		ResolvedRef string            `json:"resolvedRef"`
+	}
+	var revisions []catalogRevision
+	for i := range catalogs.Items {
+		catalog := &catalogs.Items[i]
+		if !meta.IsStatusConditionPresentAndEqual(catalog.Status.Conditions, catalogd.TypeUnpacked, metav1.ConditionTrue) {
+			continue
+		}
+		resolvedRef := resolvedCatalogRef(catalog)
+		if resolvedRef == "" {
+			return "", false, nil
+		}
+		var ref string
+		if catalog.Spec.Source.Image != nil {
+			ref = catalog.Spec.Source.Image.Ref
+		}
+		revisions = append(revisions, catalogRevision{Name: catalog.Name, Labels: catalog.Labels, Ref: ref, ResolvedRef: resolvedRef})
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Name < revisions[j].Name })
+	revision, err := hashJSON(revisions)
+	if err != nil {
+		return "", false, err
+	}
+	return revision, true, nil
+}
+
+// cachedResolution is the outcome of a successful resolution and the inputs it was
+// computed from.
+type cachedResolution struct {
+	key            string
+	environment    resolutionEnvironment
+	bundle         *catalogmetadata.Bundle
+	resolution     *ocv1alpha1.ResolutionStatus
+	pendingUpgrade *ocv1alpha1.PendingUpgrade
+	// otherInstalled is the bundle installed by every other ClusterExtension, keyed
+	// by name, which the preflight checks consult. The inputs in the key determine it.
+	otherInstalled map[string]*catalogmetadata.Bundle
+}
+
+// resolutionEnvironment records the properties of the cluster that resolution
+// consulted. Properties that were not consulted are nil. Whether a property is
+// consulted depends only on the inputs in the cache key, so an outcome remains valid
+// while the properties it consulted are unchanged.
+type resolutionEnvironment struct {
+	kubernetesVersion *string
+	nodePlatforms     *string
+	clusterVersions   *string
+}
+
+// resolutionCacheKey returns the key of the inputs of resolving the ClusterExtension
+// from catalogs: the catalog revision, the spec, the bundle image of every
+// BundleDeployment and the installed bundle of every other ClusterExtension. ok is
+// false if the outcome cannot be cached.
+func (r *ClusterExtensionReconciler) resolutionCacheKey(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (string, bool, error) {
+	if r.CatalogRevisions == nil || ext.Spec.RollbackTo != nil || ext.Spec.PinnedImage != "" {
+		return "", false, nil
+	}
+	revision, ok, err := r.CatalogRevisions.CatalogRevision(ctx)
+	if err != nil || !ok {
+		return "", false, err
+	}
+
+	bundleDeployments := rukpakv1alpha2.BundleDeploymentList{}
+	if err := r.Client.List(ctx, &bundleDeployments); err != nil {
+		return "", false, err
+	}
+	images := map[string]string{}
+	for _, bd := range bundleDeployments.Items {
+		if bd.Spec.Source.Image != nil {
+			images[bd.Name] = bd.Spec.Source.Image.Ref
+		}
+	}
+
+	clusterExtensions := ocv1alpha1.ClusterExtensionList{}
+	if err := r.Client.List(ctx, &clusterExtensions); err != nil {
+		return "", false, err
+	}
+	type otherExtension struct {
+		Name       string `json:"name"`
+		Generation int64  `json:"generation"`
+		Deleting   bool   `json:"deleting,omitempty"`
+		Installed  string `json:"installed,omitempty"`
+	}
+	var others []otherExtension
+	for _, other := range clusterExtensions.Items {
+		if other.Name == ext.Name {
+			continue
+		}
+		o := otherExtension{Name: other.Name, Generation: other.Generation, Deleting: !other.DeletionTimestamp.IsZero()}
+		if other.Status.InstalledBundle != nil {
+			o.Installed = other.Status.InstalledBundle.Name
+		}
+		others = append(others, o)
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].Name < others[j].Name })
+
+	key, err := hashJSON(struct {
+		Catalogs          string                          `json:"catalogs"`
+		Spec              ocv1alpha1.ClusterExtensionSpec `json:"spec"`
+		BundleDeployments map[string]string               `json:"bundleDeployments"`
+		ClusterExtensions []otherExtension                `json:"clusterExtensions"`
+	}{revision, ext.Spec, images, others})
+	if err != nil {
+		return "", false, err
+	}
+	return key, true, nil
+}
+
+// cachedResolutionFor returns the cached resolution of the ClusterExtension if it was
+// computed from the inputs with the key and the cluster properties it consulted are
+// unchanged.
+func (r *ClusterExtensionReconciler) cachedResolutionFor(ctx context.Context, ext *ocv1alpha1.ClusterExtension, key string) (*cachedResolution, error) {
+	value, ok := r.resolutions.Load(ext.Name)
+	if !ok {
+		return nil, nil
+	}
+	cached := value.(*cachedResolution)
+	if cached.key != key {
+		return nil, nil
+	}
+	env := cached.environment
+	if env.kubernetesVersion != nil {
+		version, err := r.KubernetesVersion.KubernetesVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if version.String() != *env.kubernetesVersion {
+			return nil, nil
+		}
+	}
+	if env.nodePlatforms != nil {
+		platforms, err := r.NodePlatforms.NodePlatforms(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if describePlatforms(platforms) != *env.nodePlatforms {
+			return nil, nil
+		}
+	}
+	if env.clusterVersions != nil {
+		versions, err := r.clusterVersions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if versions.String() != *env.clusterVersions {
+			return nil, nil
+		}
+	}
+	return cached, nil
+}
+
+// cacheResolution records the resolution of the ClusterExtension, as set in its
+// status, as the outcome of the inputs with the key.
+func (r *ClusterExtensionReconciler) cacheResolution(ext *ocv1alpha1.ClusterExtension, key string, env resolutionEnvironment, bundle *catalogmetadata.Bundle, otherInstalled map[string]*catalogmetadata.Bundle) {
+	r.resolutions.Store(ext.Name, &cachedResolution{
+		key:            key,
+		environment:    env,
+		bundle:         bundle,
+		resolution:     ext.Status.Resolution.DeepCopy(),
+		pendingUpgrade: ext.Status.PendingUpgrade.DeepCopy(),
+		otherInstalled: otherInstalled,
+	})
+}
+
+// describePlatforms lists the platforms as os/arch pairs.
+func describePlatforms(platforms []catalogmetadata.Platform) string {
+	names := make([]string, 0, len(platforms))
+	for _, p := range platforms {
+		names = append(names, p.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func hashJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
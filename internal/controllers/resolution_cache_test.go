@@ -0,0 +1,242 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	"github.com/operator-framework/operator-controller/pkg/scheme"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+type fakeCatalogRevision struct {
+	revision string
+	ok       bool
+}
+
+func (f *fakeCatalogRevision) CatalogRevision(context.Context) (string, bool, error) {
+	return f.revision, f.ok, nil
+}
+
+// countingBundleProvider counts the times the catalogs' bundles are fetched.
+type countingBundleProvider struct {
+	controllers.BundleProvider
+	calls int
+}
+
+func (p *countingBundleProvider) Bundles(ctx context.Context) ([]*catalogmetadata.Bundle, error) {
+	p.calls++
+	return p.BundleProvider.Bundles(ctx)
+}
+
+// failingReader fails every read, standing in for the uncached API reader.
+type failingReader struct {
+	client.Reader
+}
+
+func (failingReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return errors.New("unexpected uncached read")
+}
+
+func TestClusterCatalogRevision(t *testing.T) {
+	catalog := func(name, resolvedRef string, unpacked bool) *catalogd.Catalog {
+		c := &catalogd.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: catalogd.CatalogSpec{Source: catalogd.CatalogSource{
+				Type:  catalogd.SourceTypeImage,
+				Image: &catalogd.ImageSource{Ref: "quay.io/example/" + name + ":latest"},
+			}},
+		}
+		if resolvedRef != "" {
+			c.Status.ResolvedSource = &catalogd.ResolvedCatalogSource{
+				Type:  catalogd.SourceTypeImage,
+				Image: &catalogd.ResolvedImageSource{Ref: c.Spec.Source.Image.Ref, ResolvedRef: resolvedRef},
+			}
+		}
+		if unpacked {
+			c.Status.Conditions = []metav1.Condition{{Type: catalogd.TypeUnpacked, Status: metav1.ConditionTrue, Reason: catalogd.ReasonUnpackSuccessful}}
+		}
+		return c
+	}
+	revision := func(catalogs ...*catalogd.Catalog) (string, bool) {
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		for _, c := range catalogs {
+			builder = builder.WithObjects(c)
+		}
+		provider := &controllers.ClusterCatalogRevision{Reader: builder.Build()}
+		rev, ok, err := provider.CatalogRevision(context.Background())
+		require.NoError(t, err)
+		return rev, ok
+	}
+
+	base, ok := revision(catalog("a", "quay.io/example/a@sha256:1", true), catalog("b", "", false))
+	require.True(t, ok)
+
+	t.Log("It ignores catalogs that are not unpacked")
+	rev, ok := revision(catalog("a", "quay.io/example/a@sha256:1", true))
+	require.True(t, ok)
+	require.Equal(t, base, rev)
+
+	t.Log("It changes when a catalog's resolved digest changes")
+	rev, ok = revision(catalog("a", "quay.io/example/a@sha256:2", true))
+	require.True(t, ok)
+	require.NotEqual(t, base, rev)
+
+	t.Log("It changes when a catalog's labels change")
+	labeled := catalog("a", "quay.io/example/a@sha256:1", true)
+	labeled.Labels = map[string]string{catalogmetadata.LabelCatalogPriority: "10"}
+	rev, ok = revision(labeled)
+	require.True(t, ok)
+	require.NotEqual(t, base, rev)
+
+	t.Log("It has no revision while an unpacked catalog has no resolved digest")
+	_, ok = revision(catalog("a", "quay.io/example/a@sha256:1", true), catalog("b", "", true))
+	require.False(t, ok)
+}
+
+func TestClusterExtensionResolutionCache(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(version string) *catalogmetadata.Bundle {
+		return testutil.NewBundle("widgets", version)
+	}
+	catalogs := func(versions ...string) controllers.BundleProvider {
+		var bundles []*catalogmetadata.Bundle
+		for _, v := range versions {
+			bundles = append(bundles, bundle(v))
+		}
+		fakeCatalogClient := testutil.NewFakeCatalogClient(bundles)
+		return &fakeCatalogClient
+	}
+	revisions := &fakeCatalogRevision{revision: "1", ok: true}
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:           cl,
+		BundleProvider:   catalogs("1.0.0"),
+		CatalogRevisions: revisions,
+	}
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:             "widgets",
+			UpgradeConstraintPolicy: ocv1alpha1.UpgradeConstraintPolicyIgnore,
+		},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	resolved := func() string {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		require.NotNil(t, ext.Status.ResolvedBundle)
+		return ext.Status.ResolvedBundle.Version
+	}
+	require.Equal(t, "1.0.0", resolved())
+
+	t.Log("It reuses the outcome once the bundle is installed and the catalog revision is unchanged")
+	require.Equal(t, "1.0.0", resolved())
+	reconciler.BundleProvider = catalogs("1.0.0", "2.0.0")
+	require.Equal(t, "1.0.0", resolved())
+	require.NotNil(t, ext.Status.Resolution)
+	require.Equal(t, "widgets.v1.0.0", ext.Status.Resolution.Selected.Bundle.Name)
+
+	t.Log("It resolves again when the catalog revision changes")
+	revisions.revision = "2"
+	require.Equal(t, "2.0.0", resolved())
+
+	t.Log("It resolves again when the spec changes")
+	reconciler.BundleProvider = catalogs("1.0.0", "2.0.0", "2.1.0")
+	ext.Spec.Version = "2.x"
+	require.NoError(t, cl.Update(ctx, ext))
+	require.Equal(t, "2.1.0", resolved())
+
+	t.Log("It does not cache the outcome without a catalog revision")
+	revisions.ok = false
+	reconciler.BundleProvider = catalogs("1.0.0", "2.0.0", "2.1.0", "2.2.0")
+	require.Equal(t, "2.2.0", resolved())
+}
+
+func TestClusterExtensionResolutionCacheSkipsCatalogFetch(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	group := fmt.Sprintf("%s.example.com", rand.String(8))
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		testutil.NewBundle("widgets", "1.0.0", property.MustBuildGVK(group, "v1", "Widget")),
+		testutil.NewBundle("gadgets", "1.0.0", property.MustBuildGVKRequired(group, "v1", "Widget")),
+	})
+	provider := &countingBundleProvider{BundleProvider: &fakeCatalogClient}
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:           cl,
+		CRDReader:        failingReader{},
+		BundleProvider:   provider,
+		CatalogRevisions: &fakeCatalogRevision{revision: "1", ok: true},
+	}
+
+	prefix := fmt.Sprintf("cluster-extension-test-%s-", rand.String(8))
+	reconcile := func(name string) *ocv1alpha1.ClusterExtension {
+		extKey := types.NamespacedName{Name: prefix + name}
+		ext := &ocv1alpha1.ClusterExtension{}
+		if err := cl.Get(ctx, extKey, ext); err != nil {
+			ext = &ocv1alpha1.ClusterExtension{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name}, Spec: ocv1alpha1.ClusterExtensionSpec{PackageName: name}}
+			require.NoError(t, cl.Create(ctx, ext))
+		}
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		return ext
+	}
+	installed := func(name string) {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: prefix + name}, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+	}
+
+	reconcile("widgets")
+	installed("widgets")
+	reconcile("widgets")
+	reconcile("gadgets")
+	installed("gadgets")
+	reconcile("gadgets")
+	// Installing gadgets changed an input of resolving widgets.
+	reconcile("widgets")
+
+	t.Log("It runs the preflight checks of a cached resolution without fetching the catalogs")
+	provider.calls = 0
+	for _, name := range []string{"widgets", "gadgets"} {
+		ext := reconcile(name)
+		require.Len(t, ext.Status.PreflightChecks, 2)
+		for _, check := range ext.Status.PreflightChecks {
+			require.True(t, check.Passed, check.Message)
+		}
+	}
+	require.Zero(t, provider.calls)
+}
@@ -0,0 +1,255 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	mmsemver "github.com/Masterminds/semver/v3"
+	bsemver "github.com/blang/semver/v4"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// maxRejectedBundles is the number of rejected bundles listed in
+// status.resolution.explanation.
+const maxRejectedBundles = 20
+
+// resolutionExplanation accumulates the constraints that resolution considered and
+// the bundles each of them rejected, to explain why resolution failed.
+type resolutionExplanation struct {
+	constraints []string
+	rejected    []rejectedBundle
+}
+
+type rejectedBundle struct {
+	bundle  *catalogmetadata.Bundle
+	reason  ocv1alpha1.RejectionReason
+	message string
+}
+
+// filter records the constraint and rejects the bundles that keep does not keep,
+// describing each with message. It returns the kept bundles.
+func (x *resolutionExplanation) filter(bundles []*catalogmetadata.Bundle, constraint string, reason ocv1alpha1.RejectionReason,
+	keep catalogfilter.Predicate[catalogmetadata.Bundle], message func(*catalogmetadata.Bundle) string) []*catalogmetadata.Bundle {
+	kept := catalogfilter.Filter(bundles, keep)
+	x.excluded(constraint, bundles, kept, reason, message)
+	return kept
+}
+
+// excluded records the constraint and rejects the bundles of before that are not in
+// after, describing each with message.
+func (x *resolutionExplanation) excluded(constraint string, before, after []*catalogmetadata.Bundle, reason ocv1alpha1.RejectionReason,
+	message func(*catalogmetadata.Bundle) string) {
+	x.constraints = append(x.constraints, constraint)
+	kept := make(map[*catalogmetadata.Bundle]struct{}, len(after))
+	for _, b := range after {
+		kept[b] = struct{}{}
+	}
+	for _, b := range before {
+		if _, ok := kept[b]; !ok {
+			x.rejected = append(x.rejected, rejectedBundle{bundle: b, reason: reason, message: message(b)})
+		}
+	}
+}
+
+// extend appends the constraints and rejections of other to those of x.
+func (x *resolutionExplanation) extend(other *resolutionExplanation) {
+	x.constraints = append(x.constraints, other.constraints...)
+	x.rejected = append(x.rejected, other.rejected...)
+}
+
+// status returns the explanation for status.resolution.explanation, listing the
+// rejected bundles newest version first.
+func (x *resolutionExplanation) status() *ocv1alpha1.ResolutionExplanation {
+	sorted := slices.Clone(x.rejected)
+	version := func(b *catalogmetadata.Bundle) bsemver.Version {
+		if v, err := b.Version(); err == nil {
+			return *v
+		}
+		return bsemver.Version{}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if c := version(sorted[i].bundle).Compare(version(sorted[j].bundle)); c != 0 {
+			return c > 0
+		}
+		return sorted[i].bundle.CatalogName < sorted[j].bundle.CatalogName
+	})
+	if len(sorted) > maxRejectedBundles {
+		sorted = sorted[:maxRejectedBundles]
+	}
+	explanation := &ocv1alpha1.ResolutionExplanation{
+		Constraints:   x.constraints,
+		RejectedCount: int32(len(x.rejected)),
+	}
+	for _, r := range sorted {
+		explanation.Rejected = append(explanation.Rejected, ocv1alpha1.RejectedBundle{
+			Bundle:     *bundleMetadataFor(r.bundle),
+			Catalog:    r.bundle.CatalogName,
+			Reason:     r.reason,
+			Message:    r.message,
+			Deprecated: r.bundle.IsDeprecated() || r.bundle.InDeprecatedChannels(),
+		})
+	}
+	return explanation
+}
+
+// summary describes the failed resolution and how many bundles each constraint
+// rejected, for the ResolutionFailed event.
+func (x *resolutionExplanation) summary(err error) string {
+	if len(x.rejected) == 0 {
+		return err.Error()
+	}
+	counts := map[ocv1alpha1.RejectionReason]int{}
+	for _, r := range x.rejected {
+		counts[r.reason]++
+	}
+	reasons := make([]ocv1alpha1.RejectionReason, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	described := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		described = append(described, fmt.Sprintf("%d by %s", counts[reason], reason))
+	}
+	return fmt.Sprintf("%v; rejected %d bundles: %s", err, len(x.rejected), strings.Join(described, ", "))
+}
+
+// explainResolve applies the constraints of Resolve to the bundles of the
+// ClusterExtension's package one at a time, recording the bundles each rejects.
+// Constraints that cannot be evaluated end the explanation, as Resolve reports them.
+func explainResolve(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) *resolutionExplanation {
+	x := &resolutionExplanation{constraints: []string{describePackage(ext)}}
+	bundles := catalogfilter.Filter(allBundles, packagePredicate(ext))
+	version := func(b *catalogmetadata.Bundle) string {
+		return bundleMetadataFor(b).Version
+	}
+
+	if channels := specChannels(ext); len(channels) > 0 {
+		bundles = x.filter(bundles, "in "+describeChannels(channels), ocv1alpha1.RejectionReasonChannel, channelPredicate(channels),
+			func(*catalogmetadata.Bundle) string {
+				return "not in " + describeChannels(channels)
+			})
+	}
+
+	if versionRange := ext.Spec.Version; versionRange != "" {
+		vr, err := mmsemver.NewConstraint(versionRange)
+		if err != nil {
+			return x
+		}
+		bundles = x.filter(bundles, fmt.Sprintf("version in range %q", versionRange), ocv1alpha1.RejectionReasonVersionRange, catalogfilter.InMastermindsSemverRange(vr),
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("version %s is not in range %q", version(b), versionRange)
+			})
+	}
+
+	if ext.Spec.UpgradeConstraintPolicy != ocv1alpha1.UpgradeConstraintPolicyIgnore && installedBundle != nil {
+		upgradePredicate, err := upgradeSuccessorsPredicate(ext, allBundles, installedBundle)
+		if err != nil {
+			return x
+		}
+		edge := "upgrade"
+		if ext.Spec.UpgradeConstraintPolicy == ocv1alpha1.UpgradeConstraintPolicyAllowDowngrade {
+			edge = "upgrade or downgrade"
+		}
+		bundles = x.filter(bundles, fmt.Sprintf("%s of installed bundle %q", edge, installedBundle.Name), ocv1alpha1.RejectionReasonUpgradeEdge, upgradePredicate,
+			func(*catalogmetadata.Bundle) string {
+				return fmt.Sprintf("not an %s of installed bundle %q", edge, installedBundle.Name)
+			})
+	}
+
+	var installedVersion *bsemver.Version
+	if installedBundle != nil {
+		var err error
+		if installedVersion, err = installedBundle.Version(); err != nil {
+			return x
+		}
+	}
+
+	if minimumVersion := ext.Spec.MinimumVersion; minimumVersion != "" {
+		floor, err := bsemver.ParseTolerant(minimumVersion)
+		if err != nil {
+			return x
+		}
+		bundles = x.filter(bundles, fmt.Sprintf("at or above minimum version %q", minimumVersion), ocv1alpha1.RejectionReasonMinimumVersion,
+			catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool { return v.GTE(floor) }),
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("version %s is below minimum version %q", version(b), minimumVersion)
+			})
+	}
+
+	if !ext.Spec.AllowPrerelease {
+		bundles = x.filter(bundles, "not a pre-release", ocv1alpha1.RejectionReasonPrerelease,
+			catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+				return len(v.Pre) == 0 || (installedVersion != nil && v.EQ(*installedVersion))
+			}),
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("version %s is a pre-release and allowPrerelease is not set", version(b))
+			})
+	}
+
+	for _, expression := range ext.Spec.SelectorExpressions {
+		matching, err := catalogfilter.MatchingSelectorExpression(expression)
+		if err != nil {
+			return x
+		}
+		bundles = x.filter(bundles, fmt.Sprintf("matching selector expression %q", expression), ocv1alpha1.RejectionReasonSelectorExpression, matching,
+			func(*catalogmetadata.Bundle) string {
+				return fmt.Sprintf("does not match selector expression %q", expression)
+			})
+	}
+
+	if len(ext.Spec.VersionBlocklist) > 0 {
+		notBlocked, err := notBlockedPredicate(ext)
+		if err != nil {
+			return x
+		}
+		bundles = x.filter(bundles, "not in versionBlocklist", ocv1alpha1.RejectionReasonVersionBlocklist, notBlocked,
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("version %s is in versionBlocklist", version(b))
+			})
+	}
+
+	if digest := ext.Spec.ResolvedBundleDigest; digest != "" {
+		bundles = x.filter(bundles, fmt.Sprintf("image digest %q", digest), ocv1alpha1.RejectionReasonImageDigest, catalogfilter.WithBundleImageDigest(digest),
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("image %q does not have digest %q", b.Image, digest)
+			})
+	}
+
+	if lag := lagReleases(ext); lag > 0 {
+		releases, err := channelReleases(ext, allBundles)
+		if err != nil {
+			return x
+		}
+		if len(releases) > 0 {
+			target := laggedReleaseTarget(releases, lag)
+			bundles = x.filter(bundles, fmt.Sprintf("at or below version %q, %d releases behind channel head %q", target.String(), lag, releases[0].String()),
+				ocv1alpha1.RejectionReasonLagReleases,
+				catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+					return v.LTE(target) || (installedVersion != nil && v.EQ(*installedVersion))
+				}),
+				func(b *catalogmetadata.Bundle) string {
+					return fmt.Sprintf("version %s is newer than version %q, the target for lagReleases %d", version(b), target.String(), lag)
+				})
+		}
+	}
+
+	if highest := fromHighestPriorityCatalogs(bundles); len(highest) > 0 {
+		x.excluded("from the highest priority catalog providing the package", bundles, highest, ocv1alpha1.RejectionReasonCatalogPriority,
+			func(b *catalogmetadata.Bundle) string {
+				return fmt.Sprintf("catalog %q has priority %d, below priority %d of catalog %q",
+					b.CatalogName, b.CatalogPriority(), highest[0].CatalogPriority(), highest[0].CatalogName)
+			})
+	}
+	return x
+}
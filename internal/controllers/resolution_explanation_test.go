@@ -0,0 +1,134 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionResolutionExplanation(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	channel := func(name string) *catalogmetadata.Channel {
+		return &catalogmetadata.Channel{Channel: declcfg.Channel{Name: name, Package: "widgets"}}
+	}
+	bundle := func(version, catalog, priority, channelName, minKubeVersion string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version,
+			property.Property{Type: property.TypeCSVMetadata, Value: json.RawMessage(`{"minKubeVersion":"` + minKubeVersion + `"}`)})
+		b.Name = fmt.Sprintf("%s.widgets.v%s", catalog, version)
+		b.CatalogName = catalog
+		b.CatalogLabels = map[string]string{catalogmetadata.LabelCatalogPriority: priority}
+		b.InChannels = []*catalogmetadata.Channel{channel(channelName)}
+		return b
+	}
+	deprecated := bundle("2.0.0", "community", "0", "beta", "")
+	deprecated.Deprecations = []declcfg.DeprecationEntry{{
+		Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaBundle, Name: deprecated.Name},
+		Message:   "widgets 2.0.0 is deprecated",
+	}}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("0.9.0", "certified", "10", "stable", ""),
+		bundle("1.1.0", "certified", "10", "stable", "1.30.0"),
+		bundle("1.0.0", "community", "0", "stable", ""),
+		deprecated,
+		bundle("3.0.0-rc.1", "community", "0", "stable", ""),
+	})
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:            cl,
+		BundleProvider:    &fakeCatalogClient,
+		KubernetesVersion: &fakeKubernetesVersion{version: bsemver.MustParse("1.29.0")},
+		Recorder:          recorder,
+	}
+
+	reconcile := func(spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It explains which constraint rejected each bundle when resolution fails")
+	ext, err := reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Channel: "stable", Version: ">=1.0.0-0"})
+	resolutionErr := `no bundle of package "widgets" is compatible with Kubernetes version "1.29.0": bundle "certified.widgets.v1.1.0" requires Kubernetes version "1.30.0" or later`
+	require.EqualError(t, err, resolutionErr)
+	require.Equal(t, &ocv1alpha1.ResolutionExplanation{
+		Constraints: []string{
+			`package "widgets"`,
+			`in channel "stable"`,
+			`version in range ">=1.0.0-0"`,
+			"not a pre-release",
+			"from the highest priority catalog providing the package",
+			`compatible with Kubernetes version "1.29.0"`,
+		},
+		Rejected: []ocv1alpha1.RejectedBundle{
+			{
+				Bundle:  ocv1alpha1.BundleMetadata{Name: "community.widgets.v3.0.0-rc.1", Version: "3.0.0-rc.1"},
+				Catalog: "community",
+				Reason:  ocv1alpha1.RejectionReasonPrerelease,
+				Message: "version 3.0.0-rc.1 is a pre-release and allowPrerelease is not set",
+			},
+			{
+				Bundle:     ocv1alpha1.BundleMetadata{Name: "community.widgets.v2.0.0", Version: "2.0.0"},
+				Catalog:    "community",
+				Reason:     ocv1alpha1.RejectionReasonChannel,
+				Message:    `not in channel "stable"`,
+				Deprecated: true,
+			},
+			{
+				Bundle:  ocv1alpha1.BundleMetadata{Name: "certified.widgets.v1.1.0", Version: "1.1.0"},
+				Catalog: "certified",
+				Reason:  ocv1alpha1.RejectionReasonKubernetesVersion,
+				Message: `requires Kubernetes version "1.30.0" or later`,
+			},
+			{
+				Bundle:  ocv1alpha1.BundleMetadata{Name: "community.widgets.v1.0.0", Version: "1.0.0"},
+				Catalog: "community",
+				Reason:  ocv1alpha1.RejectionReasonCatalogPriority,
+				Message: `catalog "community" has priority 0, below priority 10 of catalog "certified"`,
+			},
+			{
+				Bundle:  ocv1alpha1.BundleMetadata{Name: "certified.widgets.v0.9.0", Version: "0.9.0"},
+				Catalog: "certified",
+				Reason:  ocv1alpha1.RejectionReasonVersionRange,
+				Message: `version 0.9.0 is not in range ">=1.0.0-0"`,
+			},
+		},
+		RejectedCount: 5,
+	}, ext.Status.Resolution.Explanation)
+	require.Len(t, recorder.Events, 1)
+	require.Equal(t, "Warning ResolutionFailed "+resolutionErr+
+		"; rejected 5 bundles: 1 by CatalogPriority, 1 by Channel, 1 by KubernetesVersion, 1 by Prerelease, 1 by VersionRange", <-recorder.Events)
+
+	t.Log("It does not explain a successful resolution")
+	ext, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Channel: "stable", Version: "<1.0.0"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "certified.widgets.v0.9.0", Version: "0.9.0"}, ext.Status.ResolvedBundle)
+	require.Nil(t, ext.Status.Resolution.Explanation)
+	require.Empty(t, recorder.Events)
+}
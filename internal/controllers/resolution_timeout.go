@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// DefaultResolutionTimeout is the --resolution-timeout the manager is started with
+// unless it is set.
+const DefaultResolutionTimeout = 2 * time.Minute
+
+// resolveWithTimeout resolves the ClusterExtension, giving up once ResolutionTimeout
+// has passed. Resolution runs on a copy of the ClusterExtension with a context that is
+// cancelled at the timeout. An abandoned resolution stops fetching catalogs and stops
+// at the next stage of the pipeline, and it neither holds the worker, changes the
+// status, caches its outcome nor records events once it has been given up on. It
+// returns an error with reason ResolutionTimedOut when it gives up.
+func (r *ClusterExtensionReconciler) resolveWithTimeout(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, map[string]*catalogmetadata.Bundle, error) {
+	if r.ResolutionTimeout <= 0 {
+		return r.resolve(ctx, ext)
+	}
+	resolveCtx, cancel := context.WithTimeout(ctx, r.ResolutionTimeout)
+	defer cancel()
+
+	type result struct {
+		bundle         *catalogmetadata.Bundle
+		otherInstalled map[string]*catalogmetadata.Bundle
+		err            error
+	}
+	resolving := ext.DeepCopy()
+	done := make(chan result, 1)
+	go func() {
+		bundle, otherInstalled, err := r.resolve(resolveCtx, resolving)
+		done <- result{bundle: bundle, otherInstalled: otherInstalled, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		ext.Status = resolving.Status
+		if res.err != nil && errors.Is(resolveCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, nil, r.resolutionTimedOutError()
+		}
+		return res.bundle, res.otherInstalled, res.err
+	case <-resolveCtx.Done():
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		ext.Status.Resolution = nil
+		ext.Status.PendingUpgrade = nil
+		return nil, nil, r.resolutionTimedOutError()
+	}
+}
+
+func (r *ClusterExtensionReconciler) resolutionTimedOutError() error {
+	return &resolutionError{
+		reason: ocv1alpha1.ReasonResolutionTimedOut,
+		err:    fmt.Errorf("resolution did not complete within %s: %w", r.ResolutionTimeout, context.DeadlineExceeded),
+	}
+}
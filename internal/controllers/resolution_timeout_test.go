@@ -0,0 +1,144 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+// slowBundleProvider blocks until release is closed, or until its context is done
+// if it honors the context, and then returns its bundles.
+type slowBundleProvider struct {
+	release       chan struct{}
+	honorsContext bool
+	bundles       []*catalogmetadata.Bundle
+	calls         atomic.Int32
+}
+
+func (p *slowBundleProvider) Bundles(ctx context.Context) ([]*catalogmetadata.Bundle, error) {
+	p.calls.Add(1)
+	if !p.honorsContext {
+		<-p.release
+		return p.bundles, nil
+	}
+	select {
+	case <-p.release:
+		return p.bundles, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestClusterExtensionResolutionTimeout(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	for _, honorsContext := range []bool{true, false} {
+		t.Logf("It fails with reason ResolutionTimedOut when resolution takes too long, with a bundle provider that honors the context: %t", honorsContext)
+		provider := &slowBundleProvider{release: make(chan struct{}), honorsContext: honorsContext}
+		reconciler := &controllers.ClusterExtensionReconciler{
+			Client:            cl,
+			BundleProvider:    provider,
+			ResolutionTimeout: 100 * time.Millisecond,
+		}
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		close(provider.release)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.EqualError(t, err, "resolution did not complete within 100ms: context deadline exceeded")
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		require.Nil(t, ext.Status.ResolvedBundle)
+		cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeResolved)
+		require.NotNil(t, cond)
+		require.Equal(t, metav1.ConditionFalse, cond.Status)
+		require.Equal(t, ocv1alpha1.ReasonResolutionTimedOut, cond.Reason)
+	}
+}
+
+// cacheReader reads like the manager's cached client, which serves reads from its
+// informers irrespective of the context.
+type cacheReader struct {
+	client.Client
+}
+
+func (c cacheReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.Client.Get(context.Background(), key, obj, opts...)
+}
+
+func (c cacheReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.Client.List(context.Background(), list, opts...)
+}
+
+func TestClusterExtensionResolutionTimeoutNotCached(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	provider := &slowBundleProvider{
+		release: make(chan struct{}),
+		bundles: []*catalogmetadata.Bundle{{
+			Bundle: declcfg.Bundle{
+				Name:    "widgets.v1.0.0",
+				Package: "widgets",
+				Image:   "quay.io/example/widgets@fake1.0.0",
+				Properties: []property.Property{
+					{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"widgets","version":"1.0.0"}`)},
+				},
+			},
+			CatalogName: "fake-catalog",
+		}},
+	}
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:            cacheReader{Client: cl},
+		BundleProvider:    provider,
+		CatalogRevisions:  &fakeCatalogRevision{revision: "1", ok: true},
+		ResolutionTimeout: 100 * time.Millisecond,
+	}
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets"},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+
+	t.Log("It does not cache the outcome of a resolution that completes after it timed out")
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	close(provider.release)
+	// Give the abandoned resolution the chance to finish.
+	time.Sleep(200 * time.Millisecond)
+	reconciler.ResolutionTimeout = 0
+
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), provider.calls.Load())
+	require.NoError(t, cl.Get(ctx, extKey, ext))
+	verifyInvariants(ctx, t, reconciler.Client, ext)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+}
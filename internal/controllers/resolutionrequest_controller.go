@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	catalogd "github.com/operator-framework/catalogd/api/core/v1alpha1"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// ResolutionRequestReconciler resolves the constraints of each ResolutionRequest the way
+// the ClusterExtensionReconciler resolves those of a ClusterExtension that has nothing
+// installed, and reports the outcome in its status. Nothing is installed.
+type ResolutionRequestReconciler struct {
+	client.Client
+	BundleProvider BundleProvider
+	// DefaultCatalogSelector restricts resolution to catalogs whose labels match it,
+	// unless a ResolutionRequest sets spec.catalogSelector.
+	DefaultCatalogSelector labels.Selector
+	// RequirePinnedCatalogs excludes catalogs whose image is referenced by a tag rather
+	// than a digest from resolution.
+	RequirePinnedCatalogs bool
+	// KubernetesVersion provides the Kubernetes version that bundles declaring a
+	// minimum Kubernetes version must be compatible with. The minimum is not
+	// enforced if it is nil.
+	KubernetesVersion KubernetesVersionProvider
+	// NodePlatforms provides the platforms of the cluster's nodes, which bundles
+	// declaring their supported platforms must all support. Platforms are not
+	// checked if it is nil.
+	NodePlatforms NodePlatformProvider
+	// ClusterVersions provides the cluster versions that bundles must be compatible
+	// with when spec.clusterVersionPolicy is Enforce.
+	ClusterVersions ClusterVersionProvider
+}
+
+//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=resolutionrequests,verbs=get;list;watch
+//+kubebuilder:rbac:groups=olm.operatorframework.io,resources=resolutionrequests/status,verbs=update;patch
+
+func (r *ResolutionRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx).WithName("resolutionrequest-controller")
+	l.V(1).Info("starting")
+	defer l.V(1).Info("ending")
+
+	existing := &ocv1alpha1.ResolutionRequest{}
+	if err := r.Client.Get(ctx, req.NamespacedName, existing); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	reconciled := existing.DeepCopy()
+	reconcileErr := r.reconcile(ctx, reconciled)
+	if !equality.Semantic.DeepEqual(existing.Status, reconciled.Status) {
+		if err := r.Client.Status().Update(ctx, reconciled); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, reconcileErr
+}
+
+// reconcile sets the status of the ResolutionRequest. Failed resolutions are reported
+// in status and are not returned as errors, as they are retried when the catalogs
+// change. Errors reading the catalogs or the cluster are returned, leaving the status
+// of the previous resolution in place.
+func (r *ResolutionRequestReconciler) reconcile(ctx context.Context, rr *ocv1alpha1.ResolutionRequest) error {
+	ext := clusterExtensionForResolutionRequest(rr)
+	selected, err := r.resolve(ctx, ext)
+	if errors.As(err, &clusterError{}) {
+		return err
+	}
+	rr.Status.Resolution = ext.Status.Resolution
+	if err != nil {
+		rr.Status.ResolvedBundle = nil
+		setResolvedStatusConditionFailedWithReason(&rr.Status.Conditions, resolutionFailureReason(err), err.Error(), rr.GetGeneration())
+		return nil
+	}
+	rr.Status.ResolvedBundle = bundleMetadataFor(selected)
+	setResolvedStatusConditionSuccess(&rr.Status.Conditions, fmt.Sprintf("resolved to %s", describeBundleSource(ext, selected)), rr.GetGeneration())
+	return nil
+}
+
+// resolve returns the bundle a fresh install of the ClusterExtension would resolve to,
+// and sets status.resolution. Errors that are not resolution failures are wrapped in
+// a clusterError.
+func (r *ResolutionRequestReconciler) resolve(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*catalogmetadata.Bundle, error) {
+	allBundles, err := r.BundleProvider.Bundles(ctx)
+	if err != nil {
+		return nil, clusterError{err}
+	}
+	resolver := &ClusterExtensionReconciler{
+		Client:                 r.Client,
+		BundleProvider:         r.BundleProvider,
+		DefaultCatalogSelector: r.DefaultCatalogSelector,
+		RequirePinnedCatalogs:  r.RequirePinnedCatalogs,
+		ClusterVersions:        r.ClusterVersions,
+		KubernetesVersion:      r.KubernetesVersion,
+		NodePlatforms:          r.NodePlatforms,
+	}
+	selected, _, err := resolver.resolveFromCatalogs(ctx, ext, allBundles, nil)
+	return selected, err
+}
+
+// clusterExtensionForResolutionRequest returns a ClusterExtension with the constraints
+// of the ResolutionRequest, to resolve as if it were being installed.
+func clusterExtensionForResolutionRequest(rr *ocv1alpha1.ResolutionRequest) *ocv1alpha1.ClusterExtension {
+	return &ocv1alpha1.ClusterExtension{
+		ObjectMeta: rr.ObjectMeta,
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:         rr.Spec.PackageName,
+			Version:             rr.Spec.Version,
+			MinimumVersion:      rr.Spec.MinimumVersion,
+			AllowPrerelease:     rr.Spec.AllowPrerelease,
+			Channel:             rr.Spec.Channel,
+			Channels:            rr.Spec.Channels,
+			CatalogSelector:     rr.Spec.CatalogSelector,
+			CatalogSnapshots:    rr.Spec.CatalogSnapshots,
+			SelectorExpressions: rr.Spec.SelectorExpressions,
+			VersionBlocklist:    rr.Spec.VersionBlocklist,
+			Upgrade:             &ocv1alpha1.UpgradeConfig{ClusterVersionPolicy: rr.Spec.ClusterVersionPolicy},
+		},
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ResolutionRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ocv1alpha1.ResolutionRequest{}).
+		Watches(&catalogd.Catalog{},
+			handler.EnqueueRequestsFromMapFunc(resolutionRequestsForCatalog(mgr.GetClient(), mgr.GetLogger())),
+			builder.WithPredicates(catalogContentChanged())).
+		Complete(r)
+}
+
+// resolutionRequestsForCatalog requests a reconcile of every ResolutionRequest when a
+// catalog's content changes.
+func resolutionRequestsForCatalog(c client.Reader, logger logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, _ client.Object) []reconcile.Request {
+		requests := ocv1alpha1.ResolutionRequestList{}
+		if err := c.List(ctx, &requests); err != nil {
+			logger.Error(err, "unable to enqueue resolution requests for catalog reconcile")
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, rr := range requests.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: rr.GetName()}})
+		}
+		return reqs
+	}
+}
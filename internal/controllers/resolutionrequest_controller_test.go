@@ -0,0 +1,98 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	bsemver "github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestResolutionRequestReconciler(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	defer func() {
+		require.NoError(t, cl.DeleteAllOf(ctx, &ocv1alpha1.ResolutionRequest{}))
+	}()
+
+	bundle := func(version string, props ...property.Property) *catalogmetadata.Bundle {
+		return testutil.NewBundle("widgets", version, props...)
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0"),
+		bundle("1.1.0"),
+		bundle("2.0.0", property.Property{Type: catalogmetadata.PropertyClusterVersionRange, Value: json.RawMessage(`">=4.16.0"`)}),
+	})
+	reconciler := &controllers.ResolutionRequestReconciler{
+		Client:          cl,
+		BundleProvider:  &fakeCatalogClient,
+		ClusterVersions: &fakeClusterVersions{versions: controllers.ClusterVersions{Current: bsemver.MustParse("4.15.0")}},
+	}
+
+	reconcile := func(spec ocv1alpha1.ResolutionRequestSpec) (*ocv1alpha1.ResolutionRequest, error) {
+		key := types.NamespacedName{Name: fmt.Sprintf("resolution-request-test-%s", rand.String(8))}
+		rr := &ocv1alpha1.ResolutionRequest{ObjectMeta: metav1.ObjectMeta{Name: key.Name}, Spec: spec}
+		require.NoError(t, cl.Create(ctx, rr))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		require.NoError(t, cl.Get(ctx, key, rr))
+		return rr, err
+	}
+
+	t.Log("It reports the bundle that would be installed, without installing it")
+	rr, err := reconcile(ocv1alpha1.ResolutionRequestSpec{PackageName: "widgets", Version: "1.x"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, rr.Status.ResolvedBundle)
+	require.Equal(t, "widgets.v1.1.0", rr.Status.Resolution.Selected.Bundle.Name)
+	cond := apimeta.FindStatusCondition(rr.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonSuccess, cond.Reason)
+	require.Equal(t, `resolved to "quay.io/example/widgets@fake1.1.0"`, cond.Message)
+	bds := &rukpakv1alpha2.BundleDeploymentList{}
+	require.NoError(t, cl.List(ctx, bds))
+	for _, bd := range bds.Items {
+		require.NotEqual(t, rr.Name, bd.Name)
+	}
+
+	t.Log("It reports and explains a failed resolution without returning an error")
+	rr, err = reconcile(ocv1alpha1.ResolutionRequestSpec{PackageName: "widgets", Version: "3.x"})
+	require.NoError(t, err)
+	require.Nil(t, rr.Status.ResolvedBundle)
+	cond = apimeta.FindStatusCondition(rr.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonResolutionFailed, cond.Reason)
+	require.Equal(t, `no package "widgets" matching version "3.x" found`, cond.Message)
+	require.Equal(t, int32(3), rr.Status.Resolution.Explanation.RejectedCount)
+
+	t.Log("It applies the cluster version policy")
+	rr, err = reconcile(ocv1alpha1.ResolutionRequestSpec{PackageName: "widgets"})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"}, rr.Status.ResolvedBundle)
+	rr, err = reconcile(ocv1alpha1.ResolutionRequestSpec{PackageName: "widgets", ClusterVersionPolicy: ocv1alpha1.ClusterVersionPolicyEnforce})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, rr.Status.ResolvedBundle)
+
+	t.Log("It returns errors reading the catalogs")
+	failingCatalogClient := testutil.NewFakeCatalogClientWithError(errors.New("catalogs unavailable"))
+	reconciler.BundleProvider = &failingCatalogClient
+	rr, err = reconcile(ocv1alpha1.ResolutionRequestSpec{PackageName: "widgets"})
+	require.EqualError(t, err, "catalogs unavailable")
+	require.Empty(t, rr.Status.Conditions)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// retryStatus returns status.retry after a reconcile that returned err, counting the
+// attempt on top of the previous ones. It returns nil if the reconcile succeeded.
+func (r *ClusterExtensionReconciler) retryStatus(previous *ocv1alpha1.RetryStatus, ext *ocv1alpha1.ClusterExtension, err error, now time.Time) *ocv1alpha1.RetryStatus {
+	if err == nil {
+		return nil
+	}
+	retry := &ocv1alpha1.RetryStatus{
+		Attempts:         1,
+		FirstFailureTime: metav1.NewTime(now),
+		LastAttemptTime:  metav1.NewTime(now),
+	}
+	if previous != nil {
+		retry.Attempts = previous.Attempts + 1
+		retry.FirstFailureTime = previous.FirstFailureTime
+	}
+	if ext.Status.ResolvedBundle != nil {
+		attempted := *ext.Status.ResolvedBundle
+		retry.LastAttemptedBundle = &attempted
+	}
+	retry.NextRetryTime = metav1.NewTime(now.Add(r.backoffPolicy(ext.Status.FailureClass).delay(retry.Attempts)))
+	return retry
+}
+
+// backoffPolicy returns the backoff policy of the failure class.
+func (r *ClusterExtensionReconciler) backoffPolicy(class ocv1alpha1.FailureClass) BackoffPolicy {
+	if policy, ok := r.BackoffPolicies[class]; ok {
+		return policy
+	}
+	if policy, ok := DefaultBackoffPolicies()[class]; ok {
+		return policy
+	}
+	return DefaultBackoffPolicies()[ocv1alpha1.FailureClassDefault]
+}
+
+// delay returns how long the policy waits before retrying after the given number
+// of consecutive failures, mirroring the workqueue's exponential rate limiter.
+func (p BackoffPolicy) delay(failures int32) time.Duration {
+	backoff := float64(p.Base.Nanoseconds()) * math.Pow(2, float64(failures-1))
+	if backoff > math.MaxInt64 || time.Duration(backoff) > p.Max {
+		return p.Max
+	}
+	return time.Duration(backoff)
+}
+
+// ignoreRetryStatusUpdates filters out updates of a ClusterExtension that only change
+// its retry bookkeeping. Each failed attempt updates status.retry, which
+// would otherwise trigger an immediate retry and defeat the backoff.
+func ignoreRetryStatusUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldExt, ok := e.ObjectOld.(*ocv1alpha1.ClusterExtension)
+			if !ok {
+				return true
+			}
+			newExt, ok := e.ObjectNew.(*ocv1alpha1.ClusterExtension)
+			if !ok {
+				return true
+			}
+			return !retryStatusUpdateOnly(oldExt, newExt)
+		},
+	}
+}
+
+// retryStatusUpdateOnly reports whether status.retry changed between the objects and
+// nothing else did, apart from the timings and the metadata every update changes.
+func retryStatusUpdateOnly(oldExt, newExt *ocv1alpha1.ClusterExtension) bool {
+	if equality.Semantic.DeepEqual(oldExt.Status.Retry, newExt.Status.Retry) {
+		return false
+	}
+	a, b := oldExt.DeepCopy(), newExt.DeepCopy()
+	for _, ext := range []*ocv1alpha1.ClusterExtension{a, b} {
+		ext.ResourceVersion = ""
+		ext.ManagedFields = nil
+		ext.Status.Retry = nil
+		ext.Status.Timings = nil
+	}
+	return equality.Semantic.DeepEqual(a, b)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// DefaultRevisionHistoryLimit is the number of ClusterExtensionRevisions kept for
+// each ClusterExtension when RevisionHistoryLimit is not set.
+const DefaultRevisionHistoryLimit = 10
+
+// ensureRevision creates the ClusterExtensionRevision for the most recent revision
+// in status.installHistory if the BundleDeployment installed it and it does not exist
+// yet, then deletes the oldest revisions beyond the retention limit. The revision is
+// labelled with RevisionLabels and the ClusterExtension's spec.revisionLabels.
+func (r *ClusterExtensionReconciler) ensureRevision(ctx context.Context, ext *ocv1alpha1.ClusterExtension, bd *rukpakv1alpha2.BundleDeployment) error {
+	history := ext.Status.InstallHistory
+	if len(history) == 0 || bd.Spec.Source.Image == nil || history[len(history)-1].Image != bd.Spec.Source.Image.Ref {
+		return nil
+	}
+	rev := history[len(history)-1]
+
+	name := fmt.Sprintf("%s-%d", ext.GetName(), rev.Revision)
+	err := r.Client.Get(ctx, client.ObjectKey{Name: name}, &ocv1alpha1.ClusterExtensionRevision{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	specJSON, err := json.Marshal(bd.Spec)
+	if err != nil {
+		return err
+	}
+	revisionLabels := map[string]string{}
+	maps.Copy(revisionLabels, r.RevisionLabels)
+	maps.Copy(revisionLabels, ext.Spec.RevisionLabels)
+	revisionLabels[ocv1alpha1.ClusterExtensionRevisionOwnerLabel] = ext.GetName()
+	revision := &ocv1alpha1.ClusterExtensionRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: revisionLabels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion:         ocv1alpha1.GroupVersion.String(),
+				Kind:               "ClusterExtension",
+				Name:               ext.GetName(),
+				UID:                ext.GetUID(),
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}},
+		},
+		Spec: ocv1alpha1.ClusterExtensionRevisionSpec{
+			ClusterExtensionName: ext.GetName(),
+			Revision:             rev.Revision,
+			Bundle:               rev.Bundle,
+			Image:                rev.Image,
+			ManifestHash:         fmt.Sprintf("sha256:%x", sha256.Sum256(specJSON)),
+			RollbackOf:           rev.RollbackOf,
+			InstalledAt:          metav1.Now(),
+		},
+	}
+	if err := r.Client.Create(ctx, revision); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return r.pruneRevisions(ctx, ext)
+}
+
+// pruneRevisions deletes the oldest ClusterExtensionRevisions of the ClusterExtension
+// beyond RevisionHistoryLimit.
+func (r *ClusterExtensionReconciler) pruneRevisions(ctx context.Context, ext *ocv1alpha1.ClusterExtension) error {
+	limit := r.RevisionHistoryLimit
+	if limit <= 0 {
+		limit = DefaultRevisionHistoryLimit
+	}
+	revisions := &ocv1alpha1.ClusterExtensionRevisionList{}
+	if err := r.Client.List(ctx, revisions, client.MatchingLabels{ocv1alpha1.ClusterExtensionRevisionOwnerLabel: ext.GetName()}); err != nil {
+		return err
+	}
+	if len(revisions.Items) <= limit {
+		return nil
+	}
+	slices.SortFunc(revisions.Items, func(a, b ocv1alpha1.ClusterExtensionRevision) int {
+		return int(a.Spec.Revision - b.Spec.Revision)
+	})
+	for i := range revisions.Items[:len(revisions.Items)-limit] {
+		if err := r.Client.Delete(ctx, &revisions.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
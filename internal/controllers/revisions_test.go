@@ -0,0 +1,98 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionRevisions(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	reconciler.RevisionHistoryLimit = 2
+	reconciler.RevisionLabels = map[string]string{"team": "platform", "env": "prod"}
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl, &ocv1alpha1.ClusterExtensionRevision{})
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:    "prometheus",
+			Channel:        "beta",
+			RevisionLabels: map[string]string{"env": "staging"},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	install := func(version string) {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		clusterExtension.Spec.Version = version
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:   rukpakv1alpha2.TypeInstalled,
+			Status: metav1.ConditionTrue,
+			Reason: rukpakv1alpha2.ReasonInstallationSucceeded,
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+	}
+	revisions := func() []ocv1alpha1.ClusterExtensionRevision {
+		list := &ocv1alpha1.ClusterExtensionRevisionList{}
+		require.NoError(t, cl.List(ctx, list, client.MatchingLabels{ocv1alpha1.ClusterExtensionRevisionOwnerLabel: extKey.Name}))
+		return list.Items
+	}
+
+	t.Log("It creates a revision for a successful install")
+	install("1.0.0")
+	revs := revisions()
+	require.Len(t, revs, 1)
+	require.Equal(t, fmt.Sprintf("%s-1", extKey.Name), revs[0].GetName())
+	require.Equal(t, extKey.Name, revs[0].Spec.ClusterExtensionName)
+	require.Equal(t, int64(1), revs[0].Spec.Revision)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, revs[0].Spec.Bundle)
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", revs[0].Spec.Image)
+	require.Regexp(t, "^sha256:[0-9a-f]{64}$", revs[0].Spec.ManifestHash)
+	require.False(t, revs[0].Spec.InstalledAt.IsZero())
+	require.Equal(t, clusterExtension.GetUID(), metav1.GetControllerOf(&revs[0]).UID)
+
+	t.Log("It labels the revision with the controller's labels, overridden by spec.revisionLabels")
+	require.Equal(t, map[string]string{
+		ocv1alpha1.ClusterExtensionRevisionOwnerLabel: extKey.Name,
+		"team": "platform",
+		"env":  "staging",
+	}, revs[0].GetLabels())
+
+	t.Log("It does not create another revision while the same bundle stays installed")
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.Len(t, revisions(), 1)
+
+	t.Log("It deletes the oldest revisions beyond the retention limit")
+	install("1.0.1")
+	install("1.2.0")
+	var names []string
+	for _, rev := range revisions() {
+		names = append(names, rev.GetName())
+	}
+	require.ElementsMatch(t, []string{fmt.Sprintf("%s-2", extKey.Name), fmt.Sprintf("%s-3", extKey.Name)}, names)
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// maxInstallHistory is the number of revisions kept in status.installHistory.
+const maxInstallHistory = 10
+
+// rollbackBundle returns the bundle installed by the revision that spec.rollbackTo
+// selects: the catalog bundle of the package with the revision's image if a catalog
+// still provides it, and otherwise the bundle described by the revision. Revisions
+// that have aged out of status.installHistory are read from their
+// ClusterExtensionRevision. Once a rollback has completed, the revision it installed
+// stands in for the one it rolled back to.
+func (r *ClusterExtensionReconciler) rollbackBundle(ctx context.Context, ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle) (*catalogmetadata.Bundle, error) {
+	rev, err := r.rollbackRevision(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+	resultSet := catalogfilter.Filter(allBundles, catalogfilter.And(
+		packagePredicate(ext),
+		catalogfilter.WithBundleImage(rev.Image),
+	))
+	if len(resultSet) > 0 {
+		return resultSet[0], nil
+	}
+	return recordedBundle(ext.Spec.PackageName, rev.Bundle, rev.Image)
+}
+
+// rollbackRevision returns the revision that spec.rollbackTo selects.
+func (r *ClusterExtensionReconciler) rollbackRevision(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (*ocv1alpha1.InstallRevision, error) {
+	revision := ext.Spec.RollbackTo.Revision
+	history := ext.Status.InstallHistory
+	for i := range history {
+		if history[i].Revision == revision {
+			return &history[i], nil
+		}
+	}
+
+	stored := &ocv1alpha1.ClusterExtensionRevision{}
+	err := r.Client.Get(ctx, client.ObjectKey{Name: fmt.Sprintf("%s-%d", ext.GetName(), revision)}, stored)
+	if err == nil && stored.Spec.ClusterExtensionName == ext.GetName() {
+		return &ocv1alpha1.InstallRevision{
+			Revision:   stored.Spec.Revision,
+			Bundle:     stored.Spec.Bundle,
+			Image:      stored.Spec.Image,
+			RollbackOf: stored.Spec.RollbackOf,
+		}, nil
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+
+	if len(history) > 0 && history[len(history)-1].RollbackOf == revision {
+		return &history[len(history)-1], nil
+	}
+	return nil, &resolutionError{
+		reason: ocv1alpha1.ReasonInvalidSpec,
+		err:    fmt.Errorf("revision %d to roll back to is not recorded in status.installHistory or a ClusterExtensionRevision", revision),
+	}
+}
+
+// recordInstallRevision records the bundle installed by the BundleDeployment as a new
+// revision in status.installHistory, unless it is the most recently recorded one.
+func recordInstallRevision(ext *ocv1alpha1.ClusterExtension, bd *rukpakv1alpha2.BundleDeployment) {
+	if ext.Status.InstalledBundle == nil || ext.Spec.BundleImage != nil || bd.Spec.Source.Image == nil {
+		return
+	}
+	history := ext.Status.InstallHistory
+	rev := ocv1alpha1.InstallRevision{
+		Revision: 1,
+		Bundle:   ext.Status.InstalledBundle.BundleMetadata,
+		Image:    bd.Spec.Source.Image.Ref,
+	}
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		if last.Image == rev.Image {
+			return
+		}
+		rev.Revision = last.Revision + 1
+	}
+	if ext.Spec.RollbackTo != nil {
+		rev.RollbackOf = ext.Spec.RollbackTo.Revision
+	}
+	history = append(history, rev)
+	if len(history) > maxInstallHistory {
+		history = history[len(history)-maxInstallHistory:]
+	}
+	ext.Status.InstallHistory = history
+}
@@ -0,0 +1,134 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionRollback(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl, &ocv1alpha1.ClusterExtensionRevision{})
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Channel: "beta", Version: "1.0.0"},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+
+	// reconcileAndInstall applies the spec change, lets rukpak report the resulting
+	// BundleDeployment as installed and reconciles again to observe it.
+	reconcileAndInstall := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) error {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		mutate(&clusterExtension.Spec)
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey}); err != nil {
+			require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+			return err
+		}
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		bd.Status.ObservedGeneration = bd.GetGeneration()
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:   rukpakv1alpha2.TypeInstalled,
+			Status: metav1.ConditionTrue,
+			Reason: rukpakv1alpha2.ReasonInstallationSucceeded,
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		return err
+	}
+	revision := func(rev int64, version string, rollbackOf int64) ocv1alpha1.InstallRevision {
+		return ocv1alpha1.InstallRevision{
+			Revision:   rev,
+			Bundle:     ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/" + version, Version: version},
+			Image:      "quay.io/operatorhubio/prometheus@fake" + version,
+			RollbackOf: rollbackOf,
+		}
+	}
+
+	t.Log("It records each installed bundle as a revision")
+	require.NoError(t, reconcileAndInstall(func(*ocv1alpha1.ClusterExtensionSpec) {}))
+	require.NoError(t, reconcileAndInstall(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "1.0.1" }))
+	require.Equal(t, []ocv1alpha1.InstallRevision{revision(1, "1.0.0", 0), revision(2, "1.0.1", 0)}, clusterExtension.Status.InstallHistory)
+
+	t.Log("It reinstalls the bundle of the revision to roll back to")
+	require.NoError(t, reconcileAndInstall(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.RollbackTo = &ocv1alpha1.RollbackConfig{Revision: 1}
+	}))
+	require.NotNil(t, clusterExtension.Status.InstalledBundle)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, clusterExtension.Status.InstalledBundle.BundleMetadata)
+	require.Equal(t, []ocv1alpha1.InstallRevision{revision(1, "1.0.0", 0), revision(2, "1.0.1", 0), revision(3, "1.0.0", 1)}, clusterExtension.Status.InstallHistory)
+
+	t.Log("It rolls back to a revision whose bundle the catalogs no longer provide")
+	catalogBundles := reconciler.BundleProvider
+	var remaining []*catalogmetadata.Bundle
+	for _, b := range testBundleList {
+		if b.Image != "quay.io/operatorhubio/prometheus@fake1.0.1" {
+			remaining = append(remaining, b)
+		}
+	}
+	withoutRevision2 := testutil.NewFakeCatalogClient(remaining)
+	reconciler.BundleProvider = &withoutRevision2
+	require.NoError(t, reconcileAndInstall(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.RollbackTo = &ocv1alpha1.RollbackConfig{Revision: 2}
+	}))
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.1", Version: "1.0.1"}, clusterExtension.Status.InstalledBundle.BundleMetadata)
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	require.NoError(t, cl.Get(ctx, extKey, bd))
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.1", bd.Spec.Source.Image.Ref)
+	require.Equal(t, revision(4, "1.0.1", 2), clusterExtension.Status.InstallHistory[3])
+	reconciler.BundleProvider = catalogBundles
+
+	// ageOut drops all but the most recent revision from status.installHistory, as if
+	// the others had aged out.
+	ageOut := func() {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		history := clusterExtension.Status.InstallHistory
+		clusterExtension.Status.InstallHistory = history[len(history)-1:]
+		require.NoError(t, cl.Status().Update(ctx, clusterExtension))
+	}
+
+	t.Log("It reads a revision that has aged out of status.installHistory from its ClusterExtensionRevision")
+	ageOut()
+	require.NoError(t, reconcileAndInstall(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.RollbackTo = &ocv1alpha1.RollbackConfig{Revision: 1}
+	}))
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, clusterExtension.Status.InstalledBundle.BundleMetadata)
+	require.Equal(t, []ocv1alpha1.InstallRevision{revision(4, "1.0.1", 2), revision(5, "1.0.0", 1)}, clusterExtension.Status.InstallHistory)
+
+	t.Log("It keeps a completed rollback once the revision it rolled back to is gone")
+	ageOut()
+	require.NoError(t, cl.Delete(ctx, &ocv1alpha1.ClusterExtensionRevision{ObjectMeta: metav1.ObjectMeta{Name: extKey.Name + "-1"}}))
+	require.NoError(t, reconcileAndInstall(func(*ocv1alpha1.ClusterExtensionSpec) {}))
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, clusterExtension.Status.InstalledBundle.BundleMetadata)
+	require.Equal(t, []ocv1alpha1.InstallRevision{revision(5, "1.0.0", 1)}, clusterExtension.Status.InstallHistory)
+
+	t.Log("It fails when the revision to roll back to is not recorded")
+	err := reconcileAndInstall(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.RollbackTo = &ocv1alpha1.RollbackConfig{Revision: 7}
+	})
+	require.EqualError(t, err, "revision 7 to roll back to is not recorded in status.installHistory or a ClusterExtensionRevision")
+	cond := apimeta.FindStatusCondition(clusterExtension.Status.Conditions, ocv1alpha1.TypeResolved)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonInvalidSpec, cond.Reason)
+	require.Len(t, clusterExtension.Status.InstallHistory, 1)
+}
@@ -0,0 +1,80 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionSelectorExpressions(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	bundle := func(version, fips string) *catalogmetadata.Bundle {
+		return testutil.NewBundle("widgets", version, property.Property{Type: "features.fips", Value: json.RawMessage(fips)})
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.0.0", `true`),
+		bundle("2.0.0", `false`),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(expressions ...string) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", SelectorExpressions: expressions},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It only resolves bundles matching every selector expression")
+	ext, err := reconcile(`properties['features.fips'].exists(v, v == true)`)
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It fails resolution and explains the rejections when no bundle matches")
+	ext, err = reconcile(`properties['features.fips'].exists(v, v == true)`, `version.startsWith("2.")`)
+	require.EqualError(t, err, `no package "widgets" matching selector expression "version.startsWith(\"2.\")" found`)
+	require.NotNil(t, ext.Status.Resolution.Explanation)
+	require.Equal(t, []ocv1alpha1.RejectedBundle{
+		{
+			Bundle:  ocv1alpha1.BundleMetadata{Name: "widgets.v2.0.0", Version: "2.0.0"},
+			Catalog: "fake-catalog",
+			Reason:  ocv1alpha1.RejectionReasonSelectorExpression,
+			Message: `does not match selector expression "properties['features.fips'].exists(v, v == true)"`,
+		},
+		{
+			Bundle:  ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"},
+			Catalog: "fake-catalog",
+			Reason:  ocv1alpha1.RejectionReasonSelectorExpression,
+			Message: `does not match selector expression "version.startsWith(\"2.\")"`,
+		},
+	}, ext.Status.Resolution.Explanation.Rejected)
+
+	t.Log("It fails resolution when a selector expression is not valid")
+	_, err = reconcile(`name.size()`)
+	require.EqualError(t, err, `invalid selector expression "name.size()": expression evaluates to int, not bool`)
+}
@@ -4,18 +4,70 @@ import (
 	"fmt"
 
 	mmsemver "github.com/Masterminds/semver/v3"
+	bsemver "github.com/blang/semver/v4"
 
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
 	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
 	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
 	"github.com/operator-framework/operator-controller/pkg/features"
 )
 
 func SuccessorsPredicate(installedBundle *catalogmetadata.Bundle) (catalogfilter.Predicate[catalogmetadata.Bundle], error) {
-	var successors successorsPredicateFunc = legacySemanticsSuccessorsPredicate
-	if features.OperatorControllerFeatureGate.Enabled(features.ForceSemverUpgradeConstraints) {
+	return successorsOrInstalledPredicate(activeSuccessorsPredicate(), installedBundle)
+}
+
+// upgradeSuccessorsPredicate is SuccessorsPredicate with successors found by the
+// edges selected by the ClusterExtension's spec.upgrade.edges. With the
+// AllowDowngrade policy it also matches every lower version of the package.
+func upgradeSuccessorsPredicate(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) (catalogfilter.Predicate[catalogmetadata.Bundle], error) {
+	var edges ocv1alpha1.UpgradeEdges
+	if ext.Spec.Upgrade != nil {
+		edges = ext.Spec.Upgrade.Edges
+	}
+	successors := activeSuccessorsPredicate()
+	switch edges {
+	case ocv1alpha1.UpgradeEdgesLegacy:
+		successors = legacySemanticsSuccessorsPredicate
+	case ocv1alpha1.UpgradeEdgesSemver:
+		successors = semverSuccessorsPredicate
+	case ocv1alpha1.UpgradeEdgesAuto:
 		successors = semverSuccessorsPredicate
+		if declaresLegacyEdges(catalogfilter.Filter(allBundles, catalogfilter.WithPackageName(installedBundle.Package))) {
+			successors = legacySemanticsSuccessorsPredicate
+		}
+	}
+	predicate, err := successorsOrInstalledPredicate(successors, installedBundle)
+	if err != nil || ext.Spec.UpgradeConstraintPolicy != ocv1alpha1.UpgradeConstraintPolicyAllowDowngrade {
+		return predicate, err
+	}
+	installedVersion, err := installedBundle.Version()
+	if err != nil {
+		return nil, err
 	}
+	return catalogfilter.Or(predicate, catalogfilter.And(
+		catalogfilter.WithPackageName(installedBundle.Package),
+		catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool { return v.LT(*installedVersion) }),
+	)), nil
+}
+
+// declaresLegacyEdges reports whether any channel of the bundles declares a
+// replaces, skips or skipRange edge.
+func declaresLegacyEdges(bundles []*catalogmetadata.Bundle) bool {
+	for _, b := range bundles {
+		for _, ch := range b.InChannels {
+			for _, entry := range ch.Entries {
+				if entry.Replaces != "" || len(entry.Skips) > 0 || entry.SkipRange != "" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
 
+// successorsOrInstalledPredicate returns a predicate matching the successors of the
+// installed bundle found by successors, and bundles of the installed version.
+func successorsOrInstalledPredicate(successors successorsPredicateFunc, installedBundle *catalogmetadata.Bundle) (catalogfilter.Predicate[catalogmetadata.Bundle], error) {
 	installedBundleVersion, err := installedBundle.Version()
 	if err != nil {
 		return nil, err
@@ -41,6 +93,15 @@ func SuccessorsPredicate(installedBundle *catalogmetadata.Bundle) (catalogfilter
 	), nil
 }
 
+// activeSuccessorsPredicate returns the successorsPredicateFunc
+// selected by the ForceSemverUpgradeConstraints feature gate.
+func activeSuccessorsPredicate() successorsPredicateFunc {
+	if features.OperatorControllerFeatureGate.Enabled(features.ForceSemverUpgradeConstraints) {
+		return semverSuccessorsPredicate
+	}
+	return legacySemanticsSuccessorsPredicate
+}
+
 // successorsPredicateFunc returns a predicate to find successors
 // for a bundle. Predicate must not include the current version.
 type successorsPredicateFunc func(bundle *catalogmetadata.Bundle) (catalogfilter.Predicate[catalogmetadata.Bundle], error)
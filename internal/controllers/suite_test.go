@@ -17,6 +17,7 @@ limitations under the License.
 package controllers_test
 
 import (
+	"context"
 	"log"
 	"os"
 	"path/filepath"
@@ -28,6 +29,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
 	"github.com/operator-framework/operator-controller/internal/controllers"
 	"github.com/operator-framework/operator-controller/pkg/scheme"
 	testutil "github.com/operator-framework/operator-controller/test/util"
@@ -61,6 +65,17 @@ func newClientAndExtensionReconciler(t *testing.T) (client.Client, *controllers.
 	return cl, reconciler
 }
 
+// deleteAllOnCleanup deletes every ClusterExtension and BundleDeployment, and every
+// object of the further cluster-scoped types given, when the test finishes.
+func deleteAllOnCleanup(t *testing.T, cl client.Client, objs ...client.Object) {
+	t.Cleanup(func() {
+		ctx := context.Background()
+		for _, obj := range append([]client.Object{&ocv1alpha1.ClusterExtension{}, &rukpakv1alpha2.BundleDeployment{}}, objs...) {
+			require.NoError(t, cl.DeleteAllOf(ctx, obj))
+		}
+	})
+}
+
 var (
 	cfg *rest.Config
 )
@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startPhaseTimer starts timing a reconcile phase. The returned function reports
+// the time elapsed since, rounded to the millisecond.
+func startPhaseTimer() func() *metav1.Duration {
+	start := time.Now()
+	return func() *metav1.Duration {
+		return &metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)}
+	}
+}
@@ -0,0 +1,62 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionTimings(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	reconcileSpec := func(spec ocv1alpha1.ClusterExtensionSpec) (types.NamespacedName, *ocv1alpha1.ClusterExtension) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, _ = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		return extKey, ext
+	}
+
+	t.Log("It times every phase of a reconcile that creates a BundleDeployment")
+	extKey, ext := reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"})
+	timings := ext.Status.Timings
+	require.NotNil(t, timings)
+	require.NotNil(t, timings.Resolution)
+	require.NotNil(t, timings.Preflight)
+	require.NotNil(t, timings.Apply)
+	require.NotNil(t, timings.Total)
+
+	t.Log("It does not update the status only because the timings changed")
+	// The second reconcile resolves against the installed bundle, which narrows the
+	// candidates reported in the resolution status.
+	_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(ctx, extKey, ext))
+	resourceVersion, timings := ext.ResourceVersion, ext.Status.Timings
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(ctx, extKey, ext))
+	require.Equal(t, resourceVersion, ext.ResourceVersion)
+	require.Equal(t, timings, ext.Status.Timings)
+
+	t.Log("It omits the phases that were not reached")
+	_, ext = reconcileSpec(ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus", Version: "99.0.0"})
+	require.NotNil(t, ext.Status.Timings.Resolution)
+	require.NotNil(t, ext.Status.Timings.Total)
+	require.Nil(t, ext.Status.Timings.Preflight)
+	require.Nil(t, ext.Status.Timings.Apply)
+}
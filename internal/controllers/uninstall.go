@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+const (
+	// uninstallFinalizer holds the deletion of a ClusterExtension until its
+	// custom resources have been handled according to spec.uninstall.crPolicy.
+	uninstallFinalizer = "olm.operatorframework.io/uninstall"
+
+	// uninstallRequeueInterval is how often an uninstall waiting for custom
+	// resources to be removed checks on them.
+	uninstallRequeueInterval = 5 * time.Second
+
+	// maxReportedCustomResources bounds status.uninstall.remainingCustomResources.
+	maxReportedCustomResources = 10
+
+	// customResourceCleanupAggregationLabel is the label of the ClusterRoles that are
+	// aggregated into the role the manager lists and deletes custom resources with on
+	// uninstall. That role grants nothing by default: cluster admins grant access to
+	// the API groups of the bundles' CRDs with ClusterRoles carrying this label set to
+	// "true".
+	customResourceCleanupAggregationLabel = "olm.operatorframework.io/aggregate-to-custom-resource-cleanup"
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=patch
+
+// crPolicy returns the effective spec.uninstall.crPolicy.
+func crPolicy(ext *ocv1alpha1.ClusterExtension) ocv1alpha1.CRPolicy {
+	if ext.Spec.Uninstall == nil || ext.Spec.Uninstall.CRPolicy == "" {
+		return ocv1alpha1.CRPolicyBlock
+	}
+	return ext.Spec.Uninstall.CRPolicy
+}
+
+// propagationPolicy returns the effective spec.uninstall.propagationPolicy.
+func propagationPolicy(ext *ocv1alpha1.ClusterExtension) ocv1alpha1.PropagationPolicy {
+	if ext.Spec.Uninstall == nil || ext.Spec.Uninstall.PropagationPolicy == "" {
+		return ocv1alpha1.PropagationPolicyDelete
+	}
+	return ext.Spec.Uninstall.PropagationPolicy
+}
+
+// uninstall handles the custom resources of a deleted ClusterExtension according
+// to its crPolicy, and removes the uninstall finalizer once the BundleDeployment
+// can be garbage collected. The finalizer only holds back background deletion:
+// foreground deletion removes the BundleDeployment before the ClusterExtension.
+func (r *ClusterExtensionReconciler) uninstall(ctx context.Context, ext *ocv1alpha1.ClusterExtension) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(ext, uninstallFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if propagationPolicy(ext) == ocv1alpha1.PropagationPolicyOrphan {
+		if err := r.orphanBundleDeployment(ctx, ext); err != nil {
+			return ctrl.Result{}, err
+		}
+		ext.Status.Uninstall = nil
+		controllerutil.RemoveFinalizer(ext, uninstallFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	crds, err := r.bundleCRDs(ctx, ext.GetName())
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("error listing CRDs installed by the bundle: %w", err)
+	}
+
+	policy := crPolicy(ext)
+	if policy == ocv1alpha1.CRPolicyRetain {
+		for i := range crds.Items {
+			if err := r.retainCRD(ctx, &crds.Items[i], ext.GetName()); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		ext.Status.Uninstall = nil
+		controllerutil.RemoveFinalizer(ext, uninstallFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	remaining, err := r.customResources(ctx, crds.Items)
+	if apierrors.IsForbidden(err) {
+		return r.uninstallForbidden(ext, policy, err), nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(remaining) == 0 {
+		ext.Status.Uninstall = nil
+		controllerutil.RemoveFinalizer(ext, uninstallFinalizer)
+		return ctrl.Result{}, nil
+	}
+
+	var message string
+	switch policy {
+	case ocv1alpha1.CRPolicyDelete:
+		for _, cr := range remaining {
+			if cr.object.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if err := r.Client.Delete(ctx, cr.object); client.IgnoreNotFound(err) != nil {
+				err = fmt.Errorf("error deleting custom resource %s: %w", cr.reference, err)
+				if apierrors.IsForbidden(err) {
+					return r.uninstallForbidden(ext, policy, err), nil
+				}
+				return ctrl.Result{}, err
+			}
+		}
+		message = fmt.Sprintf("waiting for %d custom resources to be deleted", len(remaining))
+	default:
+		message = fmt.Sprintf("uninstall is blocked by crPolicy %s while %d custom resources exist", policy, len(remaining))
+	}
+	if r.Recorder != nil {
+		r.Recorder.Event(ext, corev1.EventTypeNormal, "UninstallWaiting", message)
+	}
+
+	references := make([]string, 0, len(remaining))
+	for i := 0; i < len(remaining) && i < maxReportedCustomResources; i++ {
+		references = append(references, remaining[i].reference)
+	}
+	ext.Status.Uninstall = &ocv1alpha1.UninstallStatus{Message: message, RemainingCustomResources: references}
+	return ctrl.Result{RequeueAfter: uninstallRequeueInterval}, nil
+}
+
+// uninstallForbidden holds the uninstall when the manager is not permitted to list or
+// delete the custom resources it has to handle for the crPolicy.
+func (r *ClusterExtensionReconciler) uninstallForbidden(ext *ocv1alpha1.ClusterExtension, policy ocv1alpha1.CRPolicy, err error) ctrl.Result {
+	message := fmt.Sprintf("uninstall is blocked by crPolicy %s: %v; grant access to the custom resources with a ClusterRole labeled %s=true", policy, err, customResourceCleanupAggregationLabel)
+	if r.Recorder != nil {
+		r.Recorder.Event(ext, corev1.EventTypeWarning, "UninstallForbidden", message)
+	}
+	ext.Status.Uninstall = &ocv1alpha1.UninstallStatus{Message: message}
+	return ctrl.Result{RequeueAfter: uninstallRequeueInterval}
+}
+
+// orphanBundleDeployment removes the ClusterExtension's owner reference from its
+// BundleDeployment, so that garbage collection does not uninstall the bundle.
+func (r *ClusterExtensionReconciler) orphanBundleDeployment(ctx context.Context, ext *ocv1alpha1.ClusterExtension) error {
+	bd := &rukpakv1alpha2.BundleDeployment{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: ext.GetName()}, bd); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	owners := bd.GetOwnerReferences()
+	kept := slices.DeleteFunc(slices.Clone(owners), func(owner metav1.OwnerReference) bool {
+		return owner.UID == ext.GetUID()
+	})
+	if len(kept) == len(owners) {
+		return nil
+	}
+	patch := client.MergeFrom(bd.DeepCopy())
+	bd.SetOwnerReferences(kept)
+	if err := r.Client.Patch(ctx, bd, patch); err != nil {
+		return fmt.Errorf("error orphaning BundleDeployment %q: %w", bd.GetName(), err)
+	}
+	return nil
+}
+
+// retainCRD removes the BundleDeployment's owner reference from a CRD installed
+// by the bundle. rukpak sets that reference on every object it installs, so
+// without it garbage collection would delete the CRD, and with it the custom
+// resources, once the BundleDeployment is deleted.
+func (r *ClusterExtensionReconciler) retainCRD(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition, bundleDeploymentName string) error {
+	owners := crd.GetOwnerReferences()
+	kept := slices.DeleteFunc(slices.Clone(owners), func(owner metav1.OwnerReference) bool {
+		return owner.Kind == rukpakv1alpha2.BundleDeploymentKind && owner.Name == bundleDeploymentName
+	})
+	if len(kept) == len(owners) {
+		return nil
+	}
+	patch := client.MergeFrom(crd.DeepCopy())
+	crd.SetOwnerReferences(kept)
+	if err := r.Client.Patch(ctx, crd, patch); err != nil {
+		return fmt.Errorf("error retaining CRD %q: %w", crd.GetName(), err)
+	}
+	return nil
+}
+
+// customResource is a custom resource of a CRD installed by a bundle.
+type customResource struct {
+	object    *unstructured.Unstructured
+	reference string
+}
+
+// customResources lists the custom resources of the given CRDs, ordered by reference.
+func (r *ClusterExtensionReconciler) customResources(ctx context.Context, crds []apiextensionsv1.CustomResourceDefinition) ([]customResource, error) {
+	var result []customResource
+	for _, crd := range crds {
+		version := ""
+		for _, v := range crd.Spec.Versions {
+			if v.Storage {
+				version = v.Name
+			}
+		}
+		if version == "" {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.ListKind})
+		if err := r.Client.List(ctx, list); err != nil {
+			return nil, fmt.Errorf("error listing custom resources of CRD %q: %w", crd.GetName(), err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			reference := crd.GetName() + "/" + obj.GetName()
+			if obj.GetNamespace() != "" {
+				reference = crd.GetName() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+			}
+			result = append(result, customResource{object: obj, reference: reference})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].reference < result[j].reference
+	})
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package controllers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+)
+
+// customResourcesForbidden denies listing custom resources, like the manager's client
+// before access to their API group has been aggregated into its cleanup role.
+type customResourcesForbidden struct {
+	client.Client
+}
+
+func (c customResourcesForbidden) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if u, ok := list.(*unstructured.UnstructuredList); ok {
+		gvk := u.GroupVersionKind()
+		return apierrors.NewForbidden(schema.GroupResource{Group: gvk.Group, Resource: "widgets"}, "", errors.New("not permitted"))
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestClusterExtensionUninstallCRPolicy(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	defer func() {
+		require.NoError(t, cl.DeleteAllOf(ctx, &rukpakv1alpha2.BundleDeployment{}))
+	}()
+
+	// setup creates a ClusterExtension with the given crPolicy, a CRD installed
+	// for it and one custom resource, and then deletes the ClusterExtension.
+	setup := func(t *testing.T, policy ocv1alpha1.CRPolicy) (types.NamespacedName, *apiextensionsv1.CustomResourceDefinition, *unstructured.Unstructured) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		clusterExtension := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "prometheus"},
+		}
+		if policy != "" {
+			clusterExtension.Spec.Uninstall = &ocv1alpha1.UninstallConfig{CRPolicy: policy}
+		}
+		require.NoError(t, cl.Create(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Contains(t, clusterExtension.Finalizers, "olm.operatorframework.io/uninstall")
+
+		// rukpak labels the objects it installs and makes the BundleDeployment
+		// their owner.
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		crdGroup := fmt.Sprintf("%s.example.com", rand.String(8))
+		crd := &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "widgets." + crdGroup,
+				Labels: map[string]string{
+					"core.rukpak.io/owner-kind": rukpakv1alpha2.BundleDeploymentKind,
+					"core.rukpak.io/owner-name": extKey.Name,
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion:         rukpakv1alpha2.GroupVersion.String(),
+					Kind:               rukpakv1alpha2.BundleDeploymentKind,
+					Name:               bd.Name,
+					UID:                bd.UID,
+					Controller:         ptr.To(true),
+					BlockOwnerDeletion: ptr.To(true),
+				}},
+			},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: crdGroup,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets", Singular: "widget", Kind: "Widget", ListKind: "WidgetList"},
+				Scope: apiextensionsv1.ClusterScoped,
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:    "v1",
+					Served:  true,
+					Storage: true,
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"},
+					},
+				}},
+			},
+		}
+		require.NoError(t, cl.Create(ctx, crd))
+		t.Cleanup(func() { require.NoError(t, client.IgnoreNotFound(cl.Delete(ctx, crd))) })
+
+		cr := &unstructured.Unstructured{}
+		cr.SetAPIVersion(crdGroup + "/v1")
+		cr.SetKind("Widget")
+		cr.SetName("my-widget")
+		require.Eventually(t, func() bool {
+			return cl.Create(ctx, cr) == nil
+		}, 10*time.Second, 100*time.Millisecond)
+
+		require.NoError(t, cl.Delete(ctx, clusterExtension))
+		return extKey, crd, cr
+	}
+	extensionDeleted := func(t *testing.T, extKey types.NamespacedName) bool {
+		err := cl.Get(ctx, extKey, &ocv1alpha1.ClusterExtension{})
+		require.NoError(t, client.IgnoreNotFound(err))
+		return apierrors.IsNotFound(err)
+	}
+	crExists := func(t *testing.T, cr *unstructured.Unstructured) bool {
+		got := &unstructured.Unstructured{}
+		got.SetGroupVersionKind(cr.GroupVersionKind())
+		err := cl.Get(ctx, client.ObjectKeyFromObject(cr), got)
+		require.NoError(t, client.IgnoreNotFound(err))
+		return err == nil
+	}
+
+	t.Run("Block is the default and waits for custom resources to be removed", func(t *testing.T) {
+		extKey, crd, cr := setup(t, "")
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NotZero(t, res.RequeueAfter)
+		clusterExtension := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Equal(t, &ocv1alpha1.UninstallStatus{
+			Message:                  "uninstall is blocked by crPolicy Block while 1 custom resources exist",
+			RemainingCustomResources: []string{crd.Name + "/my-widget"},
+		}, clusterExtension.Status.Uninstall)
+		require.True(t, crExists(t, cr))
+
+		require.NoError(t, cl.Delete(ctx, cr))
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.True(t, extensionDeleted(t, extKey))
+	})
+
+	t.Run("Block holds the uninstall while custom resources cannot be listed", func(t *testing.T) {
+		extKey, _, cr := setup(t, "")
+		forbidden := &controllers.ClusterExtensionReconciler{
+			Client:         customResourcesForbidden{Client: cl},
+			BundleProvider: reconciler.BundleProvider,
+			Scheme:         reconciler.Scheme,
+		}
+
+		res, err := forbidden.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NotZero(t, res.RequeueAfter)
+		clusterExtension := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.NotNil(t, clusterExtension.Status.Uninstall)
+		require.Contains(t, clusterExtension.Status.Uninstall.Message, "uninstall is blocked by crPolicy Block")
+		require.Contains(t, clusterExtension.Status.Uninstall.Message, "olm.operatorframework.io/aggregate-to-custom-resource-cleanup=true")
+		require.True(t, crExists(t, cr))
+
+		require.NoError(t, cl.Delete(ctx, cr))
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.True(t, extensionDeleted(t, extKey))
+	})
+
+	t.Run("Delete deletes custom resources before uninstalling", func(t *testing.T) {
+		extKey, _, cr := setup(t, ocv1alpha1.CRPolicyDelete)
+
+		res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NotZero(t, res.RequeueAfter)
+		clusterExtension := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		require.Equal(t, "waiting for 1 custom resources to be deleted", clusterExtension.Status.Uninstall.Message)
+		require.False(t, crExists(t, cr))
+
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.True(t, extensionDeleted(t, extKey))
+	})
+
+	t.Run("Orphan leaves the BundleDeployment and custom resources in place", func(t *testing.T) {
+		extKey, _, cr := setup(t, "")
+		clusterExtension := &ocv1alpha1.ClusterExtension{}
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		clusterExtension.Spec.Uninstall = &ocv1alpha1.UninstallConfig{PropagationPolicy: ocv1alpha1.PropagationPolicyOrphan}
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.True(t, extensionDeleted(t, extKey))
+		require.True(t, crExists(t, cr))
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		require.Empty(t, bd.GetOwnerReferences())
+	})
+
+	t.Run("Retain keeps custom resources and their CRDs", func(t *testing.T) {
+		extKey, crd, cr := setup(t, ocv1alpha1.CRPolicyRetain)
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.True(t, extensionDeleted(t, extKey))
+
+		// envtest runs no garbage collector, so delete the BundleDeployment and
+		// collect the CRD the way garbage collection would if it were still owned.
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		require.NoError(t, cl.Delete(ctx, bd))
+		require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(crd), crd))
+		for _, owner := range crd.GetOwnerReferences() {
+			if owner.UID == bd.UID {
+				require.NoError(t, cl.Delete(ctx, crd))
+			}
+		}
+
+		require.NoError(t, cl.Get(ctx, client.ObjectKeyFromObject(crd), crd))
+		require.Nil(t, crd.GetDeletionTimestamp())
+		require.True(t, crExists(t, cr))
+	})
+}
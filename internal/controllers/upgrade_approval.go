@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+func upgradeApproval(ext *ocv1alpha1.ClusterExtension) ocv1alpha1.UpgradeApproval {
+	if ext.Spec.Upgrade == nil || ext.Spec.Upgrade.Approval == "" {
+		return ocv1alpha1.UpgradeApprovalAutomatic
+	}
+	return ext.Spec.Upgrade.Approval
+}
+
+// applyUpgradeApproval returns the bundle to install given the candidates, most
+// preferred first, and the ClusterExtension's upgrade approval. When approval is
+// Manual and the preferred candidate would move the extension off the installed
+// bundle without its version having been approved, the installed bundle is kept and
+// the preferred candidate is returned as the unapproved upgrade. With AutomaticPatch
+// the same applies only to a preferred candidate outside the installed bundle's major
+// and minor version, and the most preferred candidate within it is installed instead.
+// A candidate whose version has been approved is installed even if it is not the
+// preferred candidate, which is then returned as the unapproved upgrade.
+func applyUpgradeApproval(ext *ocv1alpha1.ClusterExtension, candidates []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) (*catalogmetadata.Bundle, *catalogmetadata.Bundle, error) {
+	selected := candidates[0]
+	approval := upgradeApproval(ext)
+	if approval == ocv1alpha1.UpgradeApprovalAutomatic || installedBundle == nil || selected.Name == installedBundle.Name {
+		return selected, nil, nil
+	}
+	if approved := ext.Spec.Upgrade.ApprovedVersion; approved != "" {
+		for _, candidate := range candidates {
+			if bundleMetadataFor(candidate).Version != approved {
+				continue
+			}
+			if candidate == selected {
+				return selected, nil, nil
+			}
+			return candidate, selected, nil
+		}
+	}
+	if approval == ocv1alpha1.UpgradeApprovalManual {
+		return installedBundle, selected, nil
+	}
+
+	installedVersion, err := installedBundle.Version()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, candidate := range candidates {
+		version, err := candidate.Version()
+		if err != nil {
+			return nil, nil, err
+		}
+		if version.Major == installedVersion.Major && version.Minor == installedVersion.Minor {
+			if candidate == selected {
+				return selected, nil, nil
+			}
+			return candidate, selected, nil
+		}
+	}
+	return installedBundle, selected, nil
+}
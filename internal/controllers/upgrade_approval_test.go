@@ -0,0 +1,134 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionManualUpgradeApproval(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName: "prometheus",
+			Channel:     "beta",
+			Version:     "1.0.0",
+			Upgrade:     &ocv1alpha1.UpgradeConfig{Approval: ocv1alpha1.UpgradeApprovalManual},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) *rukpakv1alpha2.BundleDeployment {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		mutate(&clusterExtension.Spec)
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		return bd
+	}
+
+	t.Log("It installs the initial bundle without approval")
+	bd := reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.Nil(t, clusterExtension.Status.PendingUpgrade)
+
+	t.Log("It reports an upgrade that has not been approved without installing it")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "" })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.0", Version: "1.0.0"}, clusterExtension.Status.ResolvedBundle)
+	require.NotNil(t, clusterExtension.Status.PendingUpgrade)
+	require.Equal(t, ocv1alpha1.PendingUpgradeReasonApprovalRequired, clusterExtension.Status.PendingUpgrade.Reason)
+	require.Equal(t, []string{"beta"}, clusterExtension.Status.PendingUpgrade.Channels)
+	pending := clusterExtension.Status.PendingUpgrade.Bundle
+	require.NotEqual(t, "1.0.0", pending.Version)
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake"+pending.Version, clusterExtension.Status.PendingUpgrade.Image)
+	require.Equal(t, fmt.Sprintf("the upgrade to version %s has not been approved in spec.upgrade.approvedVersion", pending.Version), clusterExtension.Status.PendingUpgrade.Message)
+
+	t.Log("It keeps the installed bundle when a different version is approved")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Upgrade.ApprovedVersion = "9.9.9" })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.Equal(t, pending, clusterExtension.Status.PendingUpgrade.Bundle)
+
+	t.Log("It installs the upgrade once its version is approved")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Upgrade.ApprovedVersion = pending.Version })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake"+pending.Version, bd.Spec.Source.Image.Ref)
+	require.Equal(t, &pending, clusterExtension.Status.ResolvedBundle)
+	if clusterExtension.Status.PendingUpgrade != nil {
+		require.NotEqual(t, ocv1alpha1.PendingUpgradeReasonApprovalRequired, clusterExtension.Status.PendingUpgrade.Reason)
+	}
+}
+
+func TestClusterExtensionAutomaticPatchUpgradeApproval(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	clusterExtension := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:             "prometheus",
+			Channel:                 "beta",
+			Version:                 "1.0.0",
+			UpgradeConstraintPolicy: ocv1alpha1.UpgradeConstraintPolicyIgnore,
+			Upgrade:                 &ocv1alpha1.UpgradeConfig{Approval: ocv1alpha1.UpgradeApprovalAutomaticPatch},
+		},
+	}
+	require.NoError(t, cl.Create(ctx, clusterExtension))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) *rukpakv1alpha2.BundleDeployment {
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		mutate(&clusterExtension.Spec)
+		require.NoError(t, cl.Update(ctx, clusterExtension))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, clusterExtension))
+		verifyInvariants(ctx, t, reconciler.Client, clusterExtension)
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		return bd
+	}
+
+	t.Log("It installs the initial bundle without approval")
+	bd := reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.0", bd.Spec.Source.Image.Ref)
+	require.Nil(t, clusterExtension.Status.PendingUpgrade)
+
+	t.Log("It installs the newest patch release and reports the unapproved upgrade")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "" })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.0.1", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.0.1", Version: "1.0.1"}, clusterExtension.Status.ResolvedBundle)
+	require.NotNil(t, clusterExtension.Status.PendingUpgrade)
+	require.Equal(t, ocv1alpha1.PendingUpgradeReasonApprovalRequired, clusterExtension.Status.PendingUpgrade.Reason)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, clusterExtension.Status.PendingUpgrade.Bundle)
+
+	t.Log("It installs an approved candidate that is not the preferred one and reports the newer upgrade")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Upgrade.ApprovedVersion = "1.2.0" })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake1.2.0", bd.Spec.Source.Image.Ref)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/1.2.0", Version: "1.2.0"}, clusterExtension.Status.ResolvedBundle)
+	require.NotNil(t, clusterExtension.Status.PendingUpgrade)
+	require.Equal(t, ocv1alpha1.PendingUpgradeReasonApprovalRequired, clusterExtension.Status.PendingUpgrade.Reason)
+	require.Equal(t, ocv1alpha1.BundleMetadata{Name: "operatorhub/prometheus/beta/2.0.0", Version: "2.0.0"}, clusterExtension.Status.PendingUpgrade.Bundle)
+
+	t.Log("It installs a minor or major upgrade once its version is approved")
+	bd = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Upgrade.ApprovedVersion = "2.0.0" })
+	require.Equal(t, "quay.io/operatorhubio/prometheus@fake2.0.0", bd.Spec.Source.Image.Ref)
+	require.Nil(t, clusterExtension.Status.PendingUpgrade)
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+// setUpgradeAvailableStatus sets the UpgradeAvailable condition from the outcome of
+// the reconcile. It is True while the resolved bundle is newer than the installed
+// bundle and is being installed, or while a newer bundle is reported in
+// status.pendingUpgrade.
+func setUpgradeAvailableStatus(ext *ocv1alpha1.ClusterExtension) {
+	cond := metav1.Condition{
+		Type:               ocv1alpha1.TypeUpgradeAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ocv1alpha1.ReasonUpToDate,
+		Message:            "the installed bundle is the newest that satisfies the spec",
+		ObservedGeneration: ext.GetGeneration(),
+	}
+	// While an upgrade is being installed, status.installedBundle is cleared, so
+	// compare against the most recently installed bundle instead.
+	var installed *ocv1alpha1.BundleMetadata
+	if ext.Status.InstalledBundle != nil {
+		installed = &ext.Status.InstalledBundle.BundleMetadata
+	} else if history := ext.Status.InstallHistory; len(history) > 0 {
+		installed = &history[len(history)-1].Bundle
+	}
+	resolved := ext.Status.ResolvedBundle
+	switch {
+	case !apimeta.IsStatusConditionTrue(ext.Status.Conditions, ocv1alpha1.TypeResolved):
+		cond.Status, cond.Reason = metav1.ConditionUnknown, ocv1alpha1.ReasonResolutionUnknown
+		cond.Message = "upgrades have not been checked as the extension has not been resolved"
+	case installed == nil:
+		cond.Status, cond.Reason = metav1.ConditionUnknown, ocv1alpha1.ReasonInstallationStatusUnknown
+		cond.Message = "upgrades have not been checked as no bundle is installed"
+	case ext.Status.PendingUpgrade != nil:
+		cond.Status, cond.Reason = metav1.ConditionTrue, ocv1alpha1.ReasonUpgradeAvailable
+		cond.Message = fmt.Sprintf("version %s is available: %s", ext.Status.PendingUpgrade.Bundle.Version, ext.Status.PendingUpgrade.Message)
+	case resolved != nil && newerVersion(resolved.Version, installed.Version):
+		cond.Status, cond.Reason = metav1.ConditionTrue, ocv1alpha1.ReasonUpgradeAvailable
+		cond.Message = fmt.Sprintf("version %s is available and is being installed", resolved.Version)
+	}
+	apimeta.SetStatusCondition(&ext.Status.Conditions, cond)
+}
+
+// newerVersion reports whether version is newer than other. Versions that cannot be
+// parsed are never newer.
+func newerVersion(version, other string) bool {
+	v, err := bsemver.Parse(version)
+	if err != nil {
+		return false
+	}
+	o, err := bsemver.Parse(other)
+	if err != nil {
+		return false
+	}
+	return v.GT(o)
+}
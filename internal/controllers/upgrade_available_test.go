@@ -0,0 +1,101 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	rukpakv1alpha2 "github.com/operator-framework/rukpak/api/v1alpha2"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+)
+
+func TestClusterExtensionUpgradeAvailable(t *testing.T) {
+	cl, reconciler := newClientAndReconciler(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+	ext := &ocv1alpha1.ClusterExtension{
+		ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+		Spec: ocv1alpha1.ClusterExtensionSpec{
+			PackageName:             "prometheus",
+			Version:                 "1.0.0",
+			UpgradeConstraintPolicy: ocv1alpha1.UpgradeConstraintPolicyIgnore,
+		},
+	}
+	require.NoError(t, cl.Create(ctx, ext))
+	reconcileSpec := func(mutate func(*ocv1alpha1.ClusterExtensionSpec)) (*metav1.Condition, error) {
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		mutate(&ext.Spec)
+		require.NoError(t, cl.Update(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		cond := apimeta.FindStatusCondition(ext.Status.Conditions, ocv1alpha1.TypeUpgradeAvailable)
+		require.NotNil(t, cond)
+		return cond, err
+	}
+	markInstalled := func() {
+		bd := &rukpakv1alpha2.BundleDeployment{}
+		require.NoError(t, cl.Get(ctx, extKey, bd))
+		bd.Status.ObservedGeneration = bd.Generation
+		apimeta.SetStatusCondition(&bd.Status.Conditions, metav1.Condition{
+			Type:    rukpakv1alpha2.TypeInstalled,
+			Status:  metav1.ConditionTrue,
+			Reason:  rukpakv1alpha2.ReasonInstallationSucceeded,
+			Message: "installed",
+		})
+		require.NoError(t, cl.Status().Update(ctx, bd))
+	}
+
+	t.Log("It does not check for upgrades before a bundle is installed")
+	cond, err := reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.NoError(t, err)
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonInstallationStatusUnknown, cond.Reason)
+
+	t.Log("It reports no upgrade when the installed bundle is the newest that satisfies the spec")
+	markInstalled()
+	cond, err = reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.NoError(t, err)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonUpToDate, cond.Reason)
+
+	t.Log("It reports an upgrade awaiting approval")
+	cond, err = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) {
+		spec.Version = ""
+		spec.Upgrade = &ocv1alpha1.UpgradeConfig{Approval: ocv1alpha1.UpgradeApprovalManual}
+	})
+	require.NoError(t, err)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonUpgradeAvailable, cond.Reason)
+	require.Equal(t, "version 2.0.0 is available: the upgrade to version 2.0.0 has not been approved in spec.upgrade.approvedVersion", cond.Message)
+
+	t.Log("It reports an upgrade that is being installed")
+	cond, err = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Upgrade = nil })
+	require.NoError(t, err)
+	require.Equal(t, metav1.ConditionTrue, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonUpgradeAvailable, cond.Reason)
+	require.Equal(t, "version 2.0.0 is available and is being installed", cond.Message)
+
+	t.Log("It reports no upgrade once the newer bundle is installed")
+	markInstalled()
+	cond, err = reconcileSpec(func(*ocv1alpha1.ClusterExtensionSpec) {})
+	require.NoError(t, err)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonUpToDate, cond.Reason)
+
+	t.Log("It does not check for upgrades when resolution fails")
+	cond, err = reconcileSpec(func(spec *ocv1alpha1.ClusterExtensionSpec) { spec.Version = "99.0.0" })
+	require.EqualError(t, err, `error upgrading from currently installed version "2.0.0": no package "prometheus" matching version "99.0.0" found`)
+	require.Equal(t, metav1.ConditionUnknown, cond.Status)
+	require.Equal(t, ocv1alpha1.ReasonResolutionUnknown, cond.Reason)
+}
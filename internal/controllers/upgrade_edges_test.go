@@ -0,0 +1,88 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionUpgradeEdges(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	// widgets' channel declares no edges, gadgets' channel upgrades across a major version.
+	widgetsChannel := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{{Name: "widgets.v1.0.0"}, {Name: "widgets.v1.1.0"}},
+	}}
+	gadgetsChannel := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "gadgets",
+		Entries: []declcfg.ChannelEntry{{Name: "gadgets.v1.0.0"}, {Name: "gadgets.v2.0.0", Replaces: "gadgets.v1.0.0"}},
+	}}
+	bundle := func(pkg, version string, channel *catalogmetadata.Channel) *catalogmetadata.Bundle {
+		b := testutil.NewBundle(pkg, version)
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("widgets", "1.0.0", widgetsChannel),
+		bundle("widgets", "1.1.0", widgetsChannel),
+		bundle("gadgets", "1.0.0", gadgetsChannel),
+		bundle("gadgets", "2.0.0", gadgetsChannel),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	// upgrade installs version 1.0.0 of the package, then returns the bundle
+	// resolved as its upgrade with the given edges.
+	upgrade := func(pkg string, edges ocv1alpha1.UpgradeEdges) *ocv1alpha1.BundleMetadata {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: pkg, Version: "1.0.0"},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		ext.Spec = ocv1alpha1.ClusterExtensionSpec{PackageName: pkg, Upgrade: &ocv1alpha1.UpgradeConfig{Edges: edges}}
+		require.NoError(t, cl.Update(ctx, ext))
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext.Status.ResolvedBundle
+	}
+
+	t.Log("It follows only replaces, skips and skipRange edges by default and with Legacy")
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, upgrade("widgets", ""))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.0.0", Version: "1.0.0"}, upgrade("widgets", ocv1alpha1.UpgradeEdgesLegacy))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "gadgets.v2.0.0", Version: "2.0.0"}, upgrade("gadgets", ocv1alpha1.UpgradeEdgesLegacy))
+
+	t.Log("It upgrades within the installed major version with Semver")
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, upgrade("widgets", ocv1alpha1.UpgradeEdgesSemver))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "gadgets.v1.0.0", Version: "1.0.0"}, upgrade("gadgets", ocv1alpha1.UpgradeEdgesSemver))
+
+	t.Log("It uses Legacy edges with Auto only when the package's channels declare them")
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.1.0", Version: "1.1.0"}, upgrade("widgets", ocv1alpha1.UpgradeEdgesAuto))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "gadgets.v2.0.0", Version: "2.0.0"}, upgrade("gadgets", ocv1alpha1.UpgradeEdgesAuto))
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// UpgradeGraph describes the upgrades available between the bundles of a package.
+type UpgradeGraph struct {
+	// Nodes are the bundles of the package, ordered by version.
+	Nodes []UpgradeGraphNode `json:"nodes"`
+	// Edges are the upgrades from one bundle to another, ordered by bundle name.
+	Edges []UpgradeGraphEdge `json:"edges"`
+}
+
+// UpgradeGraphNode is a bundle in an UpgradeGraph.
+type UpgradeGraphNode struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Catalogs are the names of the catalogs the bundle was found in.
+	Catalogs []string `json:"catalogs"`
+}
+
+// UpgradeGraphEdge is an upgrade from the bundle named From to the bundle named To.
+type UpgradeGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// BuildUpgradeGraph returns the upgrade graph of the named package across the given bundles.
+// Edges are computed with the same successor semantics the resolver uses, so they follow
+// replaces, skips and skipRange unless the ForceSemverUpgradeConstraints feature gate is
+// enabled, in which case they follow semver.
+func BuildUpgradeGraph(packageName string, allBundles []*catalogmetadata.Bundle) (*UpgradeGraph, error) {
+	bundles := catalogfilter.Filter(allBundles, catalogfilter.WithPackageName(packageName))
+
+	nodes := map[string]*UpgradeGraphNode{}
+	var order []*catalogmetadata.Bundle
+	for _, b := range bundles {
+		if node, ok := nodes[b.Name]; ok {
+			node.Catalogs = append(node.Catalogs, b.CatalogName)
+			continue
+		}
+		version, err := b.Version()
+		if err != nil {
+			return nil, err
+		}
+		nodes[b.Name] = &UpgradeGraphNode{Name: b.Name, Version: version.String(), Catalogs: []string{b.CatalogName}}
+		order = append(order, b)
+	}
+
+	successors := activeSuccessorsPredicate()
+	edges := map[UpgradeGraphEdge]struct{}{}
+	for _, b := range bundles {
+		predicate, err := successors(b)
+		if err != nil {
+			return nil, err
+		}
+		for _, successor := range catalogfilter.Filter(bundles, predicate) {
+			if successor.Name == b.Name {
+				continue
+			}
+			edges[UpgradeGraphEdge{From: b.Name, To: successor.Name}] = struct{}{}
+		}
+	}
+
+	graph := &UpgradeGraph{
+		Nodes: make([]UpgradeGraphNode, 0, len(nodes)),
+		Edges: make([]UpgradeGraphEdge, 0, len(edges)),
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		vi, _ := order[i].Version()
+		vj, _ := order[j].Version()
+		if !vi.EQ(*vj) {
+			return vi.LT(*vj)
+		}
+		return order[i].Name < order[j].Name
+	})
+	for _, b := range order {
+		node := nodes[b.Name]
+		sort.Strings(node.Catalogs)
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+	for edge := range edges {
+		graph.Edges = append(graph.Edges, edge)
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph, nil
+}
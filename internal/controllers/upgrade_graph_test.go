@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	"github.com/operator-framework/operator-controller/pkg/features"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func upgradeGraphTestBundles() []*catalogmetadata.Bundle {
+	channel := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "test-package",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "test-package.v1.0.0"},
+			{Name: "test-package.v1.1.0", Replaces: "test-package.v1.0.0"},
+			{Name: "test-package.v2.0.0", Replaces: "test-package.v1.1.0", Skips: []string{"test-package.v1.0.0"}},
+		},
+	}}
+	bundle := func(version, catalog string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("test-package", version)
+		b.CatalogName = catalog
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		return b
+	}
+	return []*catalogmetadata.Bundle{
+		bundle("2.0.0", "catalog-a"),
+		bundle("1.0.0", "catalog-b"),
+		bundle("1.1.0", "catalog-a"),
+		bundle("1.0.0", "catalog-a"),
+		{Bundle: declcfg.Bundle{Name: "other-package.v1.0.0", Package: "other-package"}},
+	}
+}
+
+func TestBuildUpgradeGraph(t *testing.T) {
+	graph, err := controllers.BuildUpgradeGraph("test-package", upgradeGraphTestBundles())
+	require.NoError(t, err)
+	assert.Equal(t, &controllers.UpgradeGraph{
+		Nodes: []controllers.UpgradeGraphNode{
+			{Name: "test-package.v1.0.0", Version: "1.0.0", Catalogs: []string{"catalog-a", "catalog-b"}},
+			{Name: "test-package.v1.1.0", Version: "1.1.0", Catalogs: []string{"catalog-a"}},
+			{Name: "test-package.v2.0.0", Version: "2.0.0", Catalogs: []string{"catalog-a"}},
+		},
+		Edges: []controllers.UpgradeGraphEdge{
+			{From: "test-package.v1.0.0", To: "test-package.v1.1.0"},
+			{From: "test-package.v1.0.0", To: "test-package.v2.0.0"},
+			{From: "test-package.v1.1.0", To: "test-package.v2.0.0"},
+		},
+	}, graph)
+}
+
+func TestBuildUpgradeGraphWithForceSemverUpgradeConstraintsEnabled(t *testing.T) {
+	defer featuregatetesting.SetFeatureGateDuringTest(t, features.OperatorControllerFeatureGate, features.ForceSemverUpgradeConstraints, true)()
+
+	graph, err := controllers.BuildUpgradeGraph("test-package", upgradeGraphTestBundles())
+	require.NoError(t, err)
+	// Semver successors stay within the major version.
+	assert.Equal(t, []controllers.UpgradeGraphEdge{
+		{From: "test-package.v1.0.0", To: "test-package.v1.1.0"},
+	}, graph.Edges)
+}
+
+func TestBuildUpgradeGraphUnknownPackage(t *testing.T) {
+	graph, err := controllers.BuildUpgradeGraph("missing-package", upgradeGraphTestBundles())
+	require.NoError(t, err)
+	assert.Empty(t, graph.Nodes)
+	assert.Empty(t, graph.Edges)
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"sort"
+
+	bsemver "github.com/blang/semver/v4"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+	catalogsort "github.com/operator-framework/operator-controller/internal/catalogmetadata/sort"
+)
+
+// planUpgradePath plans a sequence of upgrades from the installed bundle to the bundle
+// the ClusterExtension resolves to when the upgrade graph is ignored, for when no
+// upgrade edge leads there directly and spec.upgrade.path is Planned. Every bundle on
+// the path satisfies the constraints of the ClusterExtension other than version,
+// minimumVersion and resolvedBundleDigest, which only the target must satisfy. It
+// returns the bundles to install in order, ending with the target, and the candidates
+// for the first of them, most preferred first. Both are nil if there is no such path.
+func planUpgradePath(ext *ocv1alpha1.ClusterExtension, allBundles []*catalogmetadata.Bundle, installedBundle *catalogmetadata.Bundle) ([]*catalogmetadata.Bundle, []*catalogmetadata.Bundle, error) {
+	if installedBundle == nil || ext.Spec.Upgrade == nil || ext.Spec.Upgrade.Path != ocv1alpha1.UpgradePathPolicyPlanned ||
+		ext.Spec.UpgradeConstraintPolicy == ocv1alpha1.UpgradeConstraintPolicyIgnore {
+		return nil, nil, nil
+	}
+	targets, err := Resolve(ext, allBundles, nil)
+	if err != nil || !catalogsort.ByVersion(targets[0], installedBundle) {
+		return nil, nil, nil
+	}
+	target := targets[0]
+
+	hopExt := ext.DeepCopy()
+	hopExt.Spec.Version = ""
+	hopExt.Spec.MinimumVersion = ""
+	hopExt.Spec.ResolvedBundleDigest = ""
+	eligible, err := Resolve(hopExt, allBundles, nil)
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	path, err := shortestUpgradePath(ext, allBundles, eligible, installedBundle, target)
+	if err != nil || len(path) == 0 {
+		return nil, nil, err
+	}
+	next := path[0]
+	if next.Name == target.Name {
+		return path, targets, nil
+	}
+	nextVersion, err := next.Version()
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates := catalogfilter.Filter(eligible, catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool { return v.EQ(*nextVersion) }))
+	return path, candidates, nil
+}
+
+// shortestUpgradePath returns the fewest upgrades from the installed bundle to the
+// target through the eligible bundles, following the upgrade edges the
+// ClusterExtension's spec.upgrade.edges selects to newer versions only. Among paths of
+// equal length, the one through the newest versions is preferred. It returns nil if
+// no path leads to the target.
+func shortestUpgradePath(ext *ocv1alpha1.ClusterExtension, allBundles, eligible []*catalogmetadata.Bundle, installedBundle, target *catalogmetadata.Bundle) ([]*catalogmetadata.Bundle, error) {
+	previous := map[string]*catalogmetadata.Bundle{installedBundle.Name: nil}
+	queue := []*catalogmetadata.Bundle{installedBundle}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+
+		fromVersion, err := from.Version()
+		if err != nil {
+			return nil, err
+		}
+		successors, err := upgradeSuccessorsPredicate(ext, allBundles, from)
+		if err != nil {
+			return nil, err
+		}
+		next := catalogfilter.Filter(eligible, catalogfilter.And(successors,
+			catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool { return v.GT(*fromVersion) })))
+		sort.SliceStable(next, func(i, j int) bool { return catalogsort.ByVersion(next[i], next[j]) })
+
+		for _, b := range next {
+			if _, ok := previous[b.Name]; ok {
+				continue
+			}
+			previous[b.Name] = from
+			if b.Name != target.Name {
+				queue = append(queue, b)
+				continue
+			}
+			var path []*catalogmetadata.Bundle
+			for hop := b; hop.Name != installedBundle.Name; hop = previous[hop.Name] {
+				path = append([]*catalogmetadata.Bundle{hop}, path...)
+			}
+			return path, nil
+		}
+	}
+	return nil, nil
+}
+
+// upgradePathStatus describes the bundles still to be installed on the path for
+// status.resolution.upgradePath.
+func upgradePathStatus(path []*catalogmetadata.Bundle) *ocv1alpha1.UpgradePath {
+	if len(path) == 0 {
+		return nil
+	}
+	status := &ocv1alpha1.UpgradePath{Target: *bundleMetadataFor(path[len(path)-1])}
+	for _, b := range path {
+		status.Bundles = append(status.Bundles, *bundleMetadataFor(b))
+	}
+	return status
+}
@@ -0,0 +1,101 @@
+package controllers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionUpgradePath(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	// Each version only replaces the previous one, so 1.2.0 must pass through 1.3.0 to reach 1.4.0.
+	channel := &catalogmetadata.Channel{Channel: declcfg.Channel{
+		Name:    "stable",
+		Package: "widgets",
+		Entries: []declcfg.ChannelEntry{
+			{Name: "widgets.v1.2.0"},
+			{Name: "widgets.v1.3.0", Replaces: "widgets.v1.2.0"},
+			{Name: "widgets.v1.4.0", Replaces: "widgets.v1.3.0"},
+		},
+	}}
+	bundle := func(version string) *catalogmetadata.Bundle {
+		b := testutil.NewBundle("widgets", version)
+		b.InChannels = []*catalogmetadata.Channel{channel}
+		return b
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient([]*catalogmetadata.Bundle{
+		bundle("1.2.0"),
+		bundle("1.3.0"),
+		bundle("1.4.0"),
+	})
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	// install installs version 1.2.0 of the package, then changes the spec to target
+	// version 1.4.0 with a planned upgrade path.
+	install := func(blocklist ...string) (types.NamespacedName, *ocv1alpha1.ClusterExtension) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.2.0"},
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, err)
+
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		ext.Spec.Version = "1.4.0"
+		ext.Spec.VersionBlocklist = blocklist
+		ext.Spec.Upgrade = &ocv1alpha1.UpgradeConfig{Path: ocv1alpha1.UpgradePathPolicyPlanned}
+		require.NoError(t, cl.Update(ctx, ext))
+		return extKey, ext
+	}
+	reconcile := func(extKey types.NamespacedName, ext *ocv1alpha1.ClusterExtension) error {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return err
+	}
+
+	t.Log("It upgrades to the next bundle on the path and reports the rest of it")
+	extKey, ext := install()
+	require.NoError(t, reconcile(extKey, ext))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.3.0", Version: "1.3.0"}, ext.Status.ResolvedBundle)
+	require.Equal(t, &ocv1alpha1.UpgradePath{
+		Target: ocv1alpha1.BundleMetadata{Name: "widgets.v1.4.0", Version: "1.4.0"},
+		Bundles: []ocv1alpha1.BundleMetadata{
+			{Name: "widgets.v1.3.0", Version: "1.3.0"},
+			{Name: "widgets.v1.4.0", Version: "1.4.0"},
+		},
+	}, ext.Status.Resolution.UpgradePath)
+	require.Nil(t, ext.Status.PendingUpgrade)
+
+	t.Log("It upgrades to the target once the intermediate bundle is installed")
+	require.NoError(t, reconcile(extKey, ext))
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.4.0", Version: "1.4.0"}, ext.Status.ResolvedBundle)
+	require.Nil(t, ext.Status.Resolution.UpgradePath)
+
+	t.Log("It does not plan a path through bundles the spec excludes")
+	extKey, ext = install("1.3.0")
+	require.EqualError(t, reconcile(extKey, ext), `error upgrading from currently installed version "1.2.0": no package "widgets" matching version "1.4.0" found`)
+	require.Nil(t, ext.Status.ResolvedBundle)
+	require.Nil(t, ext.Status.Resolution.UpgradePath)
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"fmt"
+
+	bsemver "github.com/blang/semver/v4"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	catalogfilter "github.com/operator-framework/operator-controller/internal/catalogmetadata/filter"
+)
+
+// notBlockedPredicate returns a predicate that keeps bundles whose version is not in
+// spec.versionBlocklist. It returns an error if a blocked version is not a valid
+// semantic version.
+func notBlockedPredicate(ext *ocv1alpha1.ClusterExtension) (catalogfilter.Predicate[catalogmetadata.Bundle], error) {
+	blocked := make([]bsemver.Version, 0, len(ext.Spec.VersionBlocklist))
+	for _, v := range ext.Spec.VersionBlocklist {
+		version, err := bsemver.ParseTolerant(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q in versionBlocklist: %w", v, err)
+		}
+		blocked = append(blocked, version)
+	}
+	return catalogfilter.InBlangSemverRange(func(v bsemver.Version) bool {
+		for _, b := range blocked {
+			if v.EQ(b) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
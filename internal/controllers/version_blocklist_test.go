@@ -0,0 +1,94 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	ocv1alpha1 "github.com/operator-framework/operator-controller/api/v1alpha1"
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+	"github.com/operator-framework/operator-controller/internal/controllers"
+	testutil "github.com/operator-framework/operator-controller/test/util"
+)
+
+func TestClusterExtensionVersionBlocklist(t *testing.T) {
+	cl := newClient(t)
+	ctx := context.Background()
+	deleteAllOnCleanup(t, cl)
+
+	versions := []string{"1.4.1", "1.4.2", "1.5.0", "1.5.1"}
+	channel := &catalogmetadata.Channel{Channel: declcfg.Channel{Name: "stable", Package: "widgets"}}
+	var bundles []*catalogmetadata.Bundle
+	for _, version := range versions {
+		channel.Entries = append(channel.Entries, declcfg.ChannelEntry{Name: "widgets.v" + version})
+		bundles = append(bundles, &catalogmetadata.Bundle{
+			Bundle: declcfg.Bundle{
+				Name:    "widgets.v" + version,
+				Package: "widgets",
+				Image:   fmt.Sprintf("quay.io/example/widgets@fake%s", version),
+				Properties: []property.Property{
+					{Type: property.TypePackage, Value: json.RawMessage(`{"packageName":"widgets","version":"` + version + `"}`)},
+				},
+			},
+			CatalogName: "fake-catalog",
+			InChannels:  []*catalogmetadata.Channel{channel},
+		})
+	}
+	fakeCatalogClient := testutil.NewFakeCatalogClient(bundles)
+	reconciler := &controllers.ClusterExtensionReconciler{
+		Client:         cl,
+		BundleProvider: &fakeCatalogClient,
+	}
+
+	reconcile := func(spec ocv1alpha1.ClusterExtensionSpec) (*ocv1alpha1.ClusterExtension, error) {
+		extKey := types.NamespacedName{Name: fmt.Sprintf("cluster-extension-test-%s", rand.String(8))}
+		ext := &ocv1alpha1.ClusterExtension{
+			ObjectMeta: metav1.ObjectMeta{Name: extKey.Name},
+			Spec:       spec,
+		}
+		require.NoError(t, cl.Create(ctx, ext))
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: extKey})
+		require.NoError(t, cl.Get(ctx, extKey, ext))
+		verifyInvariants(ctx, t, reconciler.Client, ext)
+		return ext, err
+	}
+
+	t.Log("It does not resolve blocked versions")
+	ext, err := reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", VersionBlocklist: []string{"1.5.1"}})
+	require.NoError(t, err)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.5.0", Version: "1.5.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It skips over blocked versions when upgrading")
+	ext, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.4.1"})
+	require.NoError(t, err)
+	ext.Spec = ocv1alpha1.ClusterExtensionSpec{
+		PackageName:      "widgets",
+		Upgrade:          &ocv1alpha1.UpgradeConfig{Edges: ocv1alpha1.UpgradeEdgesSemver},
+		VersionBlocklist: []string{"1.5.1"},
+	}
+	require.NoError(t, cl.Update(ctx, ext))
+	_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: ext.Name}})
+	require.NoError(t, err)
+	require.NoError(t, cl.Get(ctx, types.NamespacedName{Name: ext.Name}, ext))
+	verifyInvariants(ctx, t, reconciler.Client, ext)
+	require.Equal(t, &ocv1alpha1.BundleMetadata{Name: "widgets.v1.5.0", Version: "1.5.0"}, ext.Status.ResolvedBundle)
+
+	t.Log("It fails resolution when every version is blocked")
+	ext, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", Version: "1.5.x", VersionBlocklist: []string{"1.5.0", "1.5.1"}})
+	require.EqualError(t, err, `no package "widgets" found outside versionBlocklist`)
+	require.Equal(t, ocv1alpha1.RejectionReasonVersionBlocklist, ext.Status.Resolution.Explanation.Rejected[0].Reason)
+
+	t.Log("It fails resolution when a blocked version is not valid")
+	_, err = reconcile(ocv1alpha1.ClusterExtensionSpec{PackageName: "widgets", VersionBlocklist: []string{"latest"}})
+	require.ErrorContains(t, err, `invalid version "latest" in versionBlocklist`)
+}
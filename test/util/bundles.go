@@ -0,0 +1,26 @@
+package testutil
+
+import (
+	"fmt"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/operator-framework/operator-controller/internal/catalogmetadata"
+)
+
+// NewBundle returns the bundle for a version of the package in the fake-catalog
+// catalog, named <pkg>.v<version> with the image quay.io/example/<pkg>@fake<version>.
+// Its properties are the olm.package property for the package and version, followed
+// by props.
+func NewBundle(pkg, version string, props ...property.Property) *catalogmetadata.Bundle {
+	return &catalogmetadata.Bundle{
+		Bundle: declcfg.Bundle{
+			Name:       pkg + ".v" + version,
+			Package:    pkg,
+			Image:      fmt.Sprintf("quay.io/example/%s@fake%s", pkg, version),
+			Properties: append([]property.Property{property.MustBuildPackage(pkg, version)}, props...),
+		},
+		CatalogName: "fake-catalog",
+	}
+}